@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/guillermoballester/propagatorGo/internal/api"
+	"github.com/guillermoballester/propagatorGo/internal/api/handlers"
+	"github.com/guillermoballester/propagatorGo/internal/api/router"
+	"github.com/guillermoballester/propagatorGo/internal/calendar"
 	"github.com/guillermoballester/propagatorGo/internal/config"
 	"github.com/guillermoballester/propagatorGo/internal/constants"
 	"github.com/guillermoballester/propagatorGo/internal/database"
+	"github.com/guillermoballester/propagatorGo/internal/leader"
 	"github.com/guillermoballester/propagatorGo/internal/orchestrator"
 	"github.com/guillermoballester/propagatorGo/internal/queue"
 	"github.com/guillermoballester/propagatorGo/internal/repository"
@@ -19,66 +28,161 @@ import (
 	"github.com/guillermoballester/propagatorGo/internal/worker"
 )
 
+// defaultCalendarDir holds the exchange calendars when the config names no directory
+const defaultCalendarDir = "data/calendars"
+
+// defaultShutdownTimeout is used when the config sets no shutdown timeout
+const defaultShutdownTimeout = 30 * time.Second
+
 func main() {
 	configPath := flag.String("config", "config.json", "Path to configuration file")
+	flag.Parse()
 	cfg, errCfg := config.LoadConfig(*configPath)
 	if errCfg != nil {
 		log.Fatalf("Failed to load config: %v", errCfg)
 	}
 
+	mode := cfg.App.Mode
+	runWorkers := mode == constants.AppModeAll || mode == constants.AppModeWorkers
+	serveAPI := mode == constants.AppModeAll || mode == constants.AppModeAPI
+	log.Printf("Starting %s in %s mode", cfg.App.Name, mode)
+
 	dbClient, redisClient := initDB(cfg)
-	deps := initWorkingDependencies(cfg, dbClient, redisClient)
+	articleRepo := repository.NewArticleRepository(dbClient.GetDB())
+	deps := initWorkingDependencies(cfg, redisClient, articleRepo)
 
-	// Orchestrator
-	o := orchestrator.NewOrchestrator(&cfg.Scheduler, deps)
-	regErr := o.RegisterWorkerPool(config.WorkerConfig{
-		PoolSize:   2,
-		WorkerType: constants.WorkerTypeScraper,
-		JobName:    constants.SourceYahoo + "-" + constants.WorkerTypeScraper,
-		CronExpr:   "0 */30 * * * *",
-		Source:     constants.SourceYahoo,
-		Enabled:    true,
-	})
-	if regErr != nil {
-		log.Panicf("Error registering Yahoo scraper pool: %v", regErr)
-	}
-
-	regErr = o.RegisterWorkerPool(config.WorkerConfig{
-		PoolSize:   2,
-		WorkerType: constants.WorkerTypeConsumer,
-		JobName:    "writer" + constants.WorkerTypeConsumer,
-		CronExpr:   "0 */30 * * * *",
-		Enabled:    true,
-	})
-
-	o.Start()
-
-	// Run initial jobs
-	if err := o.RunJob(constants.SourceYahoo + "-" + constants.WorkerTypeScraper); err != nil {
-		log.Printf("Failed to run news-scraper: %v", err)
-	}
-
-	go func() {
-		log.Printf("Waiting 1 minute before running initial scraper job...")
-		time.Sleep(1 * time.Minute)
-		if err := o.RunJob("writer" + constants.WorkerTypeConsumer); err != nil {
-			log.Printf("Failed to run news-scraper: %v", err)
-		}
-	}()
-
-	// Wait for termination signal
+	// Leader election, so cron jobs fire on a single instance. API-only
+	// instances never campaign, they only report the current leader.
+	elector := leader.NewElector(cfg.Cluster, redisClient)
+
+	var o *orchestrator.Orchestrator
+	if runWorkers {
+		elector.Start(context.Background())
+		o = initOrchestrator(cfg, deps, dbClient, elector)
+	}
+
+	// Serve the API first so health checks answer while the workers start,
+	// reporting the instance as not ready until they have
+	var server *api.Server
+	serverErr := make(chan error, 1)
+	if serveAPI {
+		server = api.NewServer(cfg, &router.Dependencies{
+			ArticleRepo:  articleRepo,
+			JobRunRepo:   repository.NewJobRunRepository(dbClient.GetDB()),
+			Elector:      elector,
+			TaskService:  deps.TaskService,
+			Orchestrator: o,
+			ScraperSvc:   deps.ScraperSvc,
+			HealthChecks: map[string]handlers.HealthCheck{
+				"database": dbClient.Ping,
+				"redis":    redisClient.Ping,
+			},
+		})
+		go func() {
+			if err := server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- err
+			}
+		}()
+	}
+
+	if o != nil {
+		o.Start()
+		o.RunStartupJobs()
+	}
+	if server != nil {
+		server.SetReady(true)
+	}
+
+	// Wait for a termination signal, or for the API server to fail
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	<-c
 
-	o.Stop()
+	exitCode := 0
+	select {
+	case <-c:
+	case err := <-serverErr:
+		log.Printf("API server failed: %v", err)
+		exitCode = 1
+	}
+
+	shutdown(cfg, server, o, elector)
+
+	if err := redisClient.Close(); err != nil {
+		log.Printf("Error closing Redis client: %v", err)
+	}
+	if err := dbClient.Close(); err != nil {
+		log.Printf("Error closing database client: %v", err)
+	}
+	os.Exit(exitCode)
+}
+
+// shutdown stops taking traffic, then stops the API server and the
+// orchestrator concurrently under the shared shutdown timeout. Leadership is
+// handed over only once this instance has stopped its work.
+func shutdown(cfg *config.Config, server *api.Server, o *orchestrator.Orchestrator, elector *leader.Elector) {
+	log.Println("Shutting down...")
+	if server != nil {
+		server.SetReady(false)
+	}
+
+	shutdownTimeout := cfg.App.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	if server != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := server.Stop(ctx); err != nil {
+				log.Printf("Error stopping API server: %v", err)
+			}
+		}()
+	}
+
+	var report *orchestrator.ShutdownReport
+	if o != nil {
+		report = o.Shutdown(ctx)
+	}
+	wg.Wait()
+
+	elector.Stop()
+	if report != nil {
+		report.Log()
+	}
+}
+
+// initOrchestrator creates the orchestrator and registers every configured job
+func initOrchestrator(cfg *config.Config, deps *orchestrator.WorkerDependencies, dbClient *database.PostgresClient, elector *leader.Elector) *orchestrator.Orchestrator {
+	o := orchestrator.NewOrchestrator(&cfg.Scheduler, deps)
+	o.SetElector(elector)
+	o.SetHistory(repository.NewJobRunRepository(dbClient.GetDB()), cfg.Cluster.InstanceID)
+	o.SetOverrides(repository.NewJobOverrideRepository(dbClient.GetDB()))
+
+	calendarDir := cfg.Scheduler.CalendarDir
+	if calendarDir == "" {
+		calendarDir = defaultCalendarDir
+	}
+	calendars, err := calendar.LoadDir(calendarDir)
+	if err != nil {
+		log.Fatalf("Failed to load exchange calendars: %v", err)
+	}
+	o.SetCalendars(calendars)
+
+	if err := o.RegisterJobs(cfg.Pools); err != nil {
+		log.Panicf("Error registering jobs: %v", err)
+	}
+	return o
 }
 
-func initWorkingDependencies(cfg *config.Config, dbClient *database.PostgresClient, redisClient *queue.RedisClient) *orchestrator.WorkerDependencies {
+func initWorkingDependencies(cfg *config.Config, redisClient *queue.RedisClient, r *repository.ArticleRepository) *orchestrator.WorkerDependencies {
 	t := task.NewService(cfg, redisClient)
-	r := repository.NewArticleRepository(dbClient.GetDB())
 	s := scraper.NewScraperService(cfg, redisClient, t)
 	f := worker.NewWorkerFactory(cfg, s, t, r)
+	f.SetStateStore(redisClient)
 
 	return &orchestrator.WorkerDependencies{
 		ScraperSvc:    s,
@@ -92,13 +196,11 @@ func initDB(cfg *config.Config) (*database.PostgresClient, *queue.RedisClient) {
 	if err != nil {
 		log.Fatalf("Failed to initialize database client: %v", err)
 	}
-	//defer dbClient.Close()
 
 	redisClient, err := queue.NewRedisClient(cfg.Redis)
 	if err != nil {
 		log.Fatalf("Failed to initialize Redis client: %v", err)
 	}
-	//defer redisClient.Close()
 
 	return dbClient, redisClient
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+	"github.com/guillermoballester/propagatorGo/internal/leader"
+)
+
+// ClusterHandler handles requests about the state of the cluster
+type ClusterHandler struct {
+	BaseHandler
+	elector *leader.Elector
+}
+
+// LeaderResponse describes the current leader as seen by this instance
+type LeaderResponse struct {
+	Leader     string `json:"leader"`
+	InstanceID string `json:"instance_id"`
+	IsLeader   bool   `json:"is_leader"`
+}
+
+// NewClusterHandler creates a new cluster handler
+func NewClusterHandler(elector *leader.Elector) *ClusterHandler {
+	return &ClusterHandler{
+		elector: elector,
+	}
+}
+
+// GetLeader reports which instance currently holds the leader lease
+func (h *ClusterHandler) GetLeader(w http.ResponseWriter, r *http.Request) {
+	if h.elector == nil {
+		response.Error(w, http.StatusServiceUnavailable, "Leader election is not enabled")
+		return
+	}
+
+	current, err := h.elector.Leader(r.Context())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Error retrieving leader")
+		return
+	}
+
+	response.JSON(w, LeaderResponse{
+		Leader:     current,
+		InstanceID: h.elector.InstanceID(),
+		IsLeader:   h.elector.IsLeader(),
+	}, http.StatusOK)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+)
+
+// healthCheckTimeout bounds each dependency check
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck reports whether a dependency of the instance is reachable
+type HealthCheck func(ctx context.Context) error
+
+// HealthHandler reports whether the instance is ready to serve requests
+type HealthHandler struct {
+	BaseHandler
+	version string
+	mode    string
+	ready   *atomic.Bool
+	checks  map[string]HealthCheck
+}
+
+// HealthResponse describes the health of the instance
+type HealthResponse struct {
+	Status    string            `json:"status"` // ok, not_ready or unavailable
+	Mode      string            `json:"mode"`
+	Version   string            `json:"version"`
+	Timestamp time.Time         `json:"timestamp"`
+	Checks    map[string]string `json:"checks,omitempty"`
+}
+
+// NewHealthHandler creates a new health handler. The instance is reported as
+// not ready while ready is unset, e.g. while starting or shutting down, and
+// as unavailable while a check fails. Check errors are logged, not returned.
+func NewHealthHandler(version, mode string, ready *atomic.Bool, checks map[string]HealthCheck) *HealthHandler {
+	return &HealthHandler{
+		version: version,
+		mode:    mode,
+		ready:   ready,
+		checks:  checks,
+	}
+}
+
+// GetHealth reports the health of the instance, with a 503 status when it
+// should not receive traffic
+func (h *HealthHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	resp := HealthResponse{
+		Status:    "ok",
+		Mode:      h.mode,
+		Version:   h.version,
+		Timestamp: time.Now().UTC(),
+	}
+
+	if h.ready != nil && !h.ready.Load() {
+		resp.Status = "not_ready"
+		response.JSON(w, resp, http.StatusServiceUnavailable)
+		return
+	}
+
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp.Checks = make(map[string]string, len(names))
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		err := h.checks[name](ctx)
+		cancel()
+
+		if err != nil {
+			log.Printf("Health check %s failed: %v", name, err)
+			resp.Checks[name] = "unavailable"
+			resp.Status = "unavailable"
+			continue
+		}
+		resp.Checks[name] = "ok"
+	}
+
+	status := http.StatusOK
+	if resp.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	response.JSON(w, resp, status)
+}
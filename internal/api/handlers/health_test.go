@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetHealth(t *testing.T) {
+	ready := &atomic.Bool{}
+	failing := errors.New("dial tcp 10.0.3.7:5432: connect: connection refused")
+	h := NewHealthHandler("1.0.0", "all", ready, map[string]HealthCheck{
+		"postgres": func(context.Context) error { return failing },
+		"redis":    func(context.Context) error { return nil },
+	})
+
+	get := func() (int, HealthResponse) {
+		rec := httptest.NewRecorder()
+		h.GetHealth(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		var resp HealthResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Expected a health response, got %v", err)
+		}
+		return rec.Code, resp
+	}
+
+	if code, resp := get(); code != http.StatusServiceUnavailable || resp.Status != "not_ready" {
+		t.Errorf("Expected 503 not_ready while starting, got %d %s", code, resp.Status)
+	}
+
+	ready.Store(true)
+	code, resp := get()
+	if code != http.StatusServiceUnavailable || resp.Status != "unavailable" {
+		t.Errorf("Expected 503 unavailable while a check fails, got %d %s", code, resp.Status)
+	}
+	if resp.Checks["postgres"] != "unavailable" || resp.Checks["redis"] != "ok" {
+		t.Errorf("Expected the failed check to be reported without its error, got %v", resp.Checks)
+	}
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+	"github.com/guillermoballester/propagatorGo/internal/database"
+	"github.com/guillermoballester/propagatorGo/internal/orchestrator"
+	"github.com/guillermoballester/propagatorGo/internal/repository"
+	"github.com/guillermoballester/propagatorGo/internal/scheduler"
+
+	"github.com/gorilla/mux"
+)
+
+// JobHandler handles requests about scheduled jobs
+type JobHandler struct {
+	BaseHandler
+	jobRunRepo   *repository.JobRunRepository
+	orchestrator *orchestrator.Orchestrator
+}
+
+// JobResponse represents a job and its current state sent to the client
+type JobResponse struct {
+	Name           string     `json:"name"`
+	Description    string     `json:"description,omitempty"`
+	Enabled        bool       `json:"enabled"`
+	Paused         bool       `json:"paused"`
+	CronExpr       string     `json:"cron_expr,omitempty"`
+	Overlap        string     `json:"overlap,omitempty"`
+	DependsOn      []string   `json:"depends_on,omitempty"`
+	OnFailure      []string   `json:"on_failure,omitempty"`
+	Status         string     `json:"status"`
+	ActiveRuns     int        `json:"active_runs"`
+	Queued         bool       `json:"queued"`
+	LastRun        *time.Time `json:"last_run,omitempty"`
+	NextRun        *time.Time `json:"next_run,omitempty"`
+	LastDuration   string     `json:"last_duration,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastAttempts   int        `json:"last_attempts"`
+	ItemsProcessed int64      `json:"items_processed"`
+	ItemsFailed    int64      `json:"items_failed"`
+	Summary        string     `json:"summary,omitempty"`
+}
+
+// RescheduleRequest changes the cron expression of a job
+type RescheduleRequest struct {
+	CronExpr string `json:"cron_expr"` // Empty to restore the configured expression
+}
+
+// JobRunResponse represents a job run sent to the client
+type JobRunResponse struct {
+	ID             int64      `json:"id"`
+	JobName        string     `json:"job_name"`
+	Trigger        string     `json:"trigger"`
+	ParentRunID    int64      `json:"parent_run_id,omitempty"`
+	Status         string     `json:"status"`
+	StartedAt      time.Time  `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	Duration       string     `json:"duration,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	ItemsProcessed int64      `json:"items_processed"`
+	ItemsFailed    int64      `json:"items_failed"`
+	Attempts       int        `json:"attempts"`
+	Summary        string     `json:"summary,omitempty"`
+	InstanceID     string     `json:"instance_id"`
+}
+
+// JobRunView is a run along with the runs it triggered, recursively
+type JobRunView struct {
+	JobRunResponse
+	Triggered []*JobRunView `json:"triggered"`
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(repo *repository.JobRunRepository, o *orchestrator.Orchestrator) *JobHandler {
+	return &JobHandler{
+		jobRunRepo:   repo,
+		orchestrator: o,
+	}
+}
+
+// GetJobs lists every job with its schedule and current state
+func (h *JobHandler) GetJobs(w http.ResponseWriter, _ *http.Request) {
+	if !h.requireScheduler(w) {
+		return
+	}
+
+	jobs := h.orchestrator.Jobs()
+	responses := make([]JobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = mapJobToResponse(job)
+	}
+	response.JSON(w, responses, http.StatusOK)
+}
+
+// GetJob returns a job with its schedule and current state
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	if !h.requireScheduler(w) {
+		return
+	}
+
+	job, err := h.orchestrator.Job(mux.Vars(r)["name"])
+	if err != nil {
+		jobError(w, err)
+		return
+	}
+	response.JSON(w, mapJobToResponse(job), http.StatusOK)
+}
+
+// TriggerJob runs a job now, regardless of its schedule. The job's overlap
+// policy applies if it is already running.
+func (h *JobHandler) TriggerJob(w http.ResponseWriter, r *http.Request) {
+	if !h.requireScheduler(w) {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if err := h.orchestrator.RunJob(name); err != nil {
+		jobError(w, err)
+		return
+	}
+	response.JSON(w, map[string]string{
+		"job":     name,
+		"message": "Job run triggered",
+	}, http.StatusAccepted)
+}
+
+// PauseJob stops firing the scheduled runs of a job
+func (h *JobHandler) PauseJob(w http.ResponseWriter, r *http.Request) {
+	h.updateJob(w, r, h.orchestrator.PauseJob)
+}
+
+// ResumeJob fires the scheduled runs of a paused job again
+func (h *JobHandler) ResumeJob(w http.ResponseWriter, r *http.Request) {
+	h.updateJob(w, r, h.orchestrator.ResumeJob)
+}
+
+// RescheduleJob changes the cron expression of a job
+func (h *JobHandler) RescheduleJob(w http.ResponseWriter, r *http.Request) {
+	var req RescheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	h.updateJob(w, r, func(name string) error {
+		return h.orchestrator.RescheduleJob(name, req.CronExpr)
+	})
+}
+
+// CancelJob cancels the runs of a job in progress on the instance serving the
+// request
+func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	if !h.requireScheduler(w) {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	cancelled, err := h.orchestrator.CancelJob(name)
+	if err != nil {
+		jobError(w, err)
+		return
+	}
+	response.JSON(w, map[string]interface{}{
+		"job":       name,
+		"cancelled": cancelled,
+	}, http.StatusOK)
+}
+
+// updateJob applies a change to the job named in the request and returns the
+// job's new state
+func (h *JobHandler) updateJob(w http.ResponseWriter, r *http.Request, change func(name string) error) {
+	if !h.requireScheduler(w) {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if err := change(name); err != nil {
+		jobError(w, err)
+		return
+	}
+
+	job, err := h.orchestrator.Job(name)
+	if err != nil {
+		jobError(w, err)
+		return
+	}
+	response.JSON(w, mapJobToResponse(job), http.StatusOK)
+}
+
+// requireScheduler reports whether this instance runs the scheduler, writing
+// an error response when it does not, e.g. in api mode
+func (h *JobHandler) requireScheduler(w http.ResponseWriter) bool {
+	if h.orchestrator == nil {
+		response.Error(w, http.StatusServiceUnavailable,
+			"Jobs are not managed by this instance, it only serves the API; use an instance running the workers")
+		return false
+	}
+	return true
+}
+
+// jobError writes the response matching an error returned by the scheduler
+func jobError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		response.NotFound(w, "Job not found")
+	case errors.Is(err, scheduler.ErrInvalidSchedule):
+		response.ValidationErrors(w, map[string]interface{}{"cron_expr": err.Error()})
+	case errors.Is(err, scheduler.ErrJobDisabled), errors.Is(err, scheduler.ErrJobNotRunning):
+		response.Error(w, http.StatusConflict, err.Error())
+	case errors.Is(err, scheduler.ErrShuttingDown):
+		response.Error(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		response.Error(w, http.StatusInternalServerError, "Error updating job")
+	}
+}
+
+// GetRuns lists job runs, latest first. Runs can be filtered by job, status,
+// trigger, instance and a started_at range given as RFC 3339 times.
+func (h *JobHandler) GetRuns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := h.GetLimitParam(r, 20, 100)
+	page := h.GetPageParam(r, 1)
+
+	filter := repository.JobRunFilter{
+		JobName:    query.Get("job"),
+		Status:     query.Get("status"),
+		Trigger:    query.Get("trigger"),
+		InstanceID: query.Get("instance"),
+		Limit:      limit,
+		Offset:     (page - 1) * limit,
+	}
+
+	invalid := make(map[string]interface{})
+	if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			invalid["since"] = "must be an RFC 3339 time"
+		}
+		filter.Since = t
+	}
+	if until := query.Get("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			invalid["until"] = "must be an RFC 3339 time"
+		}
+		filter.Until = t
+	}
+	if len(invalid) > 0 {
+		response.ValidationErrors(w, invalid)
+		return
+	}
+
+	runs, total, err := h.jobRunRepo.ListRuns(r.Context(), filter)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Error retrieving job runs")
+		return
+	}
+
+	responses := make([]JobRunResponse, len(runs))
+	for i, run := range runs {
+		responses[i] = mapJobRunToResponse(run)
+	}
+	response.JSON(w, h.Paginate(responses, total, limit, page), http.StatusOK)
+}
+
+// GetRun returns a run along with the tree of downstream runs it triggered
+func (h *JobHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid job run ID")
+		return
+	}
+
+	run, err := h.jobRunRepo.GetRun(r.Context(), id)
+	if errors.Is(err, repository.ErrJobRunNotFound) {
+		response.NotFound(w, "Job run not found")
+		return
+	}
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Error retrieving job run")
+		return
+	}
+
+	downstream, err := h.jobRunRepo.ListDownstreamRuns(r.Context(), id)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Error retrieving downstream job runs")
+		return
+	}
+
+	root := &JobRunView{JobRunResponse: mapJobRunToResponse(run), Triggered: []*JobRunView{}}
+	views := map[int64]*JobRunView{run.ID: root}
+	for _, d := range downstream {
+		views[d.ID] = &JobRunView{JobRunResponse: mapJobRunToResponse(d), Triggered: []*JobRunView{}}
+	}
+	for _, d := range downstream {
+		if parent, ok := views[d.ParentRunID]; ok {
+			parent.Triggered = append(parent.Triggered, views[d.ID])
+		}
+	}
+
+	response.JSON(w, root, http.StatusOK)
+}
+
+// mapJobRunToResponse maps a job run to its API response
+func mapJobRunToResponse(run database.JobRun) JobRunResponse {
+	resp := JobRunResponse{
+		ID:             run.ID,
+		JobName:        run.JobName,
+		Trigger:        run.Trigger,
+		ParentRunID:    run.ParentRunID,
+		Status:         run.Status,
+		StartedAt:      run.StartedAt,
+		Error:          run.Error,
+		ItemsProcessed: run.ItemsProcessed,
+		ItemsFailed:    run.ItemsFailed,
+		Attempts:       run.Attempts,
+		Summary:        run.Summary,
+		InstanceID:     run.InstanceID,
+	}
+	if !run.FinishedAt.IsZero() {
+		finishedAt := run.FinishedAt
+		resp.FinishedAt = &finishedAt
+		resp.Duration = finishedAt.Sub(run.StartedAt).Round(time.Millisecond).String()
+	}
+	return resp
+}
+
+// mapJobToResponse maps a job snapshot to its API response
+func mapJobToResponse(job scheduler.JobInfo) JobResponse {
+	resp := JobResponse{
+		Name:         job.Name,
+		Description:  job.Description,
+		Enabled:      job.Enabled,
+		Paused:       job.Paused,
+		CronExpr:     job.CronExpr,
+		Overlap:      job.Overlap,
+		DependsOn:    job.DependsOn,
+		OnFailure:    job.OnFailure,
+		Status:       string(job.Status),
+		ActiveRuns:   job.ActiveRuns,
+		Queued:       job.Queued,
+		LastError:    job.LastError,
+		LastAttempts: job.LastAttempts,
+	}
+	if !job.LastRun.IsZero() {
+		lastRun := job.LastRun
+		resp.LastRun = &lastRun
+		resp.LastDuration = job.LastRunTime.Round(time.Millisecond).String()
+	}
+	if !job.NextRun.IsZero() {
+		nextRun := job.NextRun
+		resp.NextRun = &nextRun
+	}
+	if job.LastResult != nil {
+		resp.ItemsProcessed = job.LastResult.ItemsProcessed
+		resp.ItemsFailed = job.LastResult.ItemsFailed
+		resp.Summary = job.LastResult.Summary
+	}
+	return resp
+}
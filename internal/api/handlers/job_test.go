@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestJobEndpointsWithoutScheduler(t *testing.T) {
+	h := NewJobHandler(nil, nil)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"list", h.GetJobs},
+		{"get", h.GetJob},
+		{"trigger", h.TriggerJob},
+		{"pause", h.PauseJob},
+		{"resume", h.ResumeJob},
+		{"cancel", h.CancelJob},
+	}
+
+	for _, tt := range tests {
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/jobs/dispatch", nil), map[string]string{"name": "dispatch"})
+		rec := httptest.NewRecorder()
+		tt.handler(rec, req)
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected %s to answer 503 without a scheduler, got %d", tt.name, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "only serves the API") {
+			t.Errorf("Expected %s to explain why, got %s", tt.name, rec.Body.String())
+		}
+	}
+}
@@ -25,10 +25,17 @@ type ArticleResponse struct {
 	Text      string    `json:"text,omitempty"`
 	SiteName  string    `json:"site_name"`
 	Symbol    string    `json:"symbol"`
+	Tags      []string  `json:"tags"`
 	ScrapedAt time.Time `json:"scraped_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Values of the total parameter of article listings
+const (
+	totalExact    = "exact"
+	totalEstimate = "estimate"
+)
+
 // NewNewsHandler creates a new news handler
 func NewNewsHandler(repo *repository.ArticleRepository) *NewsHandler {
 	return &NewsHandler{
@@ -38,68 +45,89 @@ func NewNewsHandler(repo *repository.ArticleRepository) *NewsHandler {
 
 // GetBySymbol handles requests for articles by stock symbol
 func (h *NewsHandler) GetBySymbol(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	symbol := vars["symbol"]
-
-	// Get pagination parameters
-	limit := h.GetLimitParam(r, 10, 50)
-	page := h.GetPageParam(r, 1)
-
-	articles, total, err := h.articleRepo.GetArticlesBySymbol(r.Context(), symbol)
-	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "Error retrieving articles")
-		return
-	}
-
-	// Apply pagination
-	start := (page - 1) * limit
-	end := start + limit
-	if start >= len(articles) {
-		// Return empty array if page is beyond available data
-		response.JSON(w, h.Paginate([]ArticleResponse{}, total, limit, page), http.StatusOK)
-		return
-	}
-	if end > len(articles) {
-		end = len(articles)
-	}
-	paginatedArticles := articles[start:end]
-
-	// Map to response objects
-	responses := mapArticlesToResponse(paginatedArticles)
-	response.JSON(w, h.Paginate(responses, total, limit, page), http.StatusOK)
+	symbol := mux.Vars(r)["symbol"]
+
+	h.listArticles(w, r,
+		func(cursor *repository.ArticleCursor, limit int) (repository.ArticlePage, error) {
+			return h.articleRepo.ListArticlesBySymbol(r.Context(), symbol, cursor, limit)
+		},
+		func(exact bool) (int, error) {
+			return h.articleRepo.CountArticlesBySymbol(r.Context(), symbol, exact)
+		},
+	)
 }
 
 // GetBySite handles requests for articles by site name
 func (h *NewsHandler) GetBySite(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	siteName := vars["site"]
+	siteName := mux.Vars(r)["site"]
+
+	h.listArticles(w, r,
+		func(cursor *repository.ArticleCursor, limit int) (repository.ArticlePage, error) {
+			return h.articleRepo.ListArticlesBySite(r.Context(), siteName, cursor, limit)
+		},
+		func(exact bool) (int, error) {
+			return h.articleRepo.CountArticlesBySite(r.Context(), siteName, exact)
+		},
+	)
+}
 
-	// Get pagination parameters
+// listArticles serves a page of articles, newest first. The page starts at
+// the cursor parameter, and the total is only counted when the total
+// parameter asks for an exact or estimated one.
+func (h *NewsHandler) listArticles(
+	w http.ResponseWriter,
+	r *http.Request,
+	list func(cursor *repository.ArticleCursor, limit int) (repository.ArticlePage, error),
+	count func(exact bool) (int, error),
+) {
+	query := r.URL.Query()
 	limit := h.GetLimitParam(r, 10, 50)
-	page := h.GetPageParam(r, 1)
 
-	articles, total, err := h.articleRepo.GetArticlesBySite(r.Context(), siteName)
+	invalid := make(map[string]interface{})
+	var cursor *repository.ArticleCursor
+	if token := query.Get("cursor"); token != "" {
+		c, err := repository.DecodeArticleCursor(token)
+		if err != nil {
+			invalid["cursor"] = "must be a cursor returned by a previous page"
+		}
+		cursor = &c
+	}
+	totalMode := query.Get("total")
+	if totalMode != "" && totalMode != totalExact && totalMode != totalEstimate {
+		invalid["total"] = "must be exact or estimate"
+	}
+	if len(invalid) > 0 {
+		response.ValidationErrors(w, invalid)
+		return
+	}
+
+	page, err := list(cursor, limit)
 	if err != nil {
 		response.Error(w, http.StatusInternalServerError, "Error retrieving articles")
 		return
 	}
 
-	// Apply pagination
-	start := (page - 1) * limit
-	end := start + limit
-	if start >= len(articles) {
-		// Return empty array if page is beyond available data
-		response.JSON(w, h.Paginate([]ArticleResponse{}, total, limit, page), http.StatusOK)
-		return
+	pagination := response.CursorPagination{PerPage: limit}
+	if page.Next != nil {
+		pagination.NextCursor = page.Next.Encode()
+	}
+	if page.Prev != nil {
+		pagination.PrevCursor = page.Prev.Encode()
 	}
-	if end > len(articles) {
-		end = len(articles)
+	if totalMode != "" {
+		total, err := count(totalMode == totalExact)
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, "Error counting articles")
+			return
+		}
+		pagination.Total = &total
+		pagination.TotalEstimated = totalMode == totalEstimate
 	}
-	paginatedArticles := articles[start:end]
 
-	// Map to response objects
-	responses := mapArticlesToResponse(paginatedArticles)
-	response.JSON(w, h.Paginate(responses, total, limit, page), http.StatusOK)
+	response.JSON(w, response.CursorPaginatedResponse{
+		Data:       mapArticlesToResponse(page.Articles),
+		Pagination: pagination,
+	}, http.StatusOK)
 }
 
 // Helper function to map a database article to API response
@@ -111,6 +139,7 @@ func mapArticleToResponse(article database.Article) ArticleResponse {
 		Text:      article.Text,
 		SiteName:  article.SiteName,
 		Symbol:    article.Symbol,
+		Tags:      article.Tags,
 		ScrapedAt: article.ScrapedAt,
 		CreatedAt: article.CreatedAt,
 	}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+	"github.com/guillermoballester/propagatorGo/internal/orchestrator"
+	"github.com/guillermoballester/propagatorGo/internal/worker"
+)
+
+// PoolHandler handles requests about worker pools
+type PoolHandler struct {
+	BaseHandler
+	orchestrator *orchestrator.Orchestrator
+}
+
+// NewPoolHandler creates a new pool handler
+func NewPoolHandler(o *orchestrator.Orchestrator) *PoolHandler {
+	return &PoolHandler{
+		orchestrator: o,
+	}
+}
+
+// GetStats reports size, throughput and scaling decisions for every pool
+func (h *PoolHandler) GetStats(w http.ResponseWriter, _ *http.Request) {
+	if h.orchestrator == nil {
+		response.JSON(w, []worker.PoolStats{}, http.StatusOK)
+		return
+	}
+
+	stats := h.orchestrator.PoolStats()
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	response.JSON(w, stats, http.StatusOK)
+}
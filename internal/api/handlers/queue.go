@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+	"github.com/guillermoballester/propagatorGo/internal/task"
+)
+
+// QueueHandler handles requests about task queues
+type QueueHandler struct {
+	BaseHandler
+	taskService *task.Service
+}
+
+// NewQueueHandler creates a new queue handler
+func NewQueueHandler(taskSvc *task.Service) *QueueHandler {
+	return &QueueHandler{
+		taskService: taskSvc,
+	}
+}
+
+// GetBackpressure reports the backpressure state of every configured queue
+func (h *QueueHandler) GetBackpressure(w http.ResponseWriter, _ *http.Request) {
+	if h.taskService == nil {
+		response.JSON(w, []task.BackpressureStats{}, http.StatusOK)
+		return
+	}
+
+	response.JSON(w, h.taskService.BackpressureStats(), http.StatusOK)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+	"github.com/guillermoballester/propagatorGo/internal/repository"
+)
+
+// SearchHandler handles full-text search requests over articles
+type SearchHandler struct {
+	BaseHandler
+	articleRepo *repository.ArticleRepository
+}
+
+// SearchResultResponse represents a search result sent to the client
+type SearchResultResponse struct {
+	ArticleResponse
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(repo *repository.ArticleRepository) *SearchHandler {
+	return &SearchHandler{
+		articleRepo: repo,
+	}
+}
+
+// Search handles requests for the articles matching a query, best matches first
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := h.GetLimitParam(r, 10, 50)
+	page := h.GetPageParam(r, 1)
+
+	search := repository.ArticleSearch{
+		Query:    strings.TrimSpace(query.Get("q")),
+		Symbol:   query.Get("symbol"),
+		SiteName: query.Get("site"),
+		Limit:    limit,
+		Offset:   (page - 1) * limit,
+	}
+
+	invalid := make(map[string]interface{})
+	if search.Query == "" {
+		invalid["q"] = "is required"
+	}
+	if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			invalid["since"] = "must be an RFC 3339 time"
+		}
+		search.Since = t
+	}
+	if until := query.Get("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			invalid["until"] = "must be an RFC 3339 time"
+		}
+		search.Until = t
+	}
+	if len(invalid) > 0 {
+		response.ValidationErrors(w, invalid)
+		return
+	}
+
+	results, total, err := h.articleRepo.SearchArticles(r.Context(), search)
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, "Error searching articles")
+		return
+	}
+
+	responses := make([]SearchResultResponse, len(results))
+	for i, result := range results {
+		responses[i] = SearchResultResponse{
+			ArticleResponse: mapArticleToResponse(result.Article),
+			Rank:            result.Rank,
+			Snippet:         result.Snippet,
+		}
+	}
+	response.JSON(w, h.Paginate(responses, total, limit, page), http.StatusOK)
+}
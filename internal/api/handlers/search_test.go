@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+)
+
+func TestSearchValidation(t *testing.T) {
+	h := NewSearchHandler(nil)
+
+	tests := []struct {
+		url     string
+		invalid []string
+	}{
+		{"/search", []string{"q"}},
+		{"/search?q=%20%20", []string{"q"}},
+		{"/search?q=apple&since=yesterday", []string{"since"}},
+		{"/search?q=apple&until=2026-03-02", []string{"until"}},
+		{"/search?since=2026-03-02T00:00:00Z&until=never", []string{"q", "until"}},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.Search(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected 422 for %s, got %d", tt.url, rec.Code)
+			continue
+		}
+
+		var resp response.ValidationError
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Expected a validation error for %s, got %v", tt.url, err)
+		}
+		invalid := make([]string, 0, len(resp.Errors))
+		for field := range resp.Errors {
+			invalid = append(invalid, field)
+		}
+		sort.Strings(invalid)
+		if !reflect.DeepEqual(invalid, tt.invalid) {
+			t.Errorf("Expected invalid fields %v for %s, got %v", tt.invalid, tt.url, invalid)
+		}
+	}
+}
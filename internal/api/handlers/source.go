@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+	scraper "github.com/guillermoballester/propagatorGo/internal/scrapper"
+)
+
+// SourceHandler handles requests about scraped sources
+type SourceHandler struct {
+	BaseHandler
+	scraperService *scraper.Service
+}
+
+// NewSourceHandler creates a new source handler
+func NewSourceHandler(scraperSvc *scraper.Service) *SourceHandler {
+	return &SourceHandler{
+		scraperService: scraperSvc,
+	}
+}
+
+// GetBreakers reports the circuit breaker state of every source
+func (h *SourceHandler) GetBreakers(w http.ResponseWriter, _ *http.Request) {
+	if h.scraperService == nil {
+		response.JSON(w, []scraper.BreakerStats{}, http.StatusOK)
+		return
+	}
+
+	stats := h.scraperService.BreakerStats()
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Source < stats[j].Source
+	})
+	response.JSON(w, stats, http.StatusOK)
+}
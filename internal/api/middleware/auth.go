@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/response"
+)
+
+// AdminToken middleware only lets through requests carrying the admin token
+// as a bearer token. With no token configured every request is refused, so
+// admin endpoints are disabled rather than left open.
+func AdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				response.Forbidden(w, "Admin endpoints are disabled, no admin token is configured")
+				return
+			}
+
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				response.Unauthorized(w, "A valid admin token is required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
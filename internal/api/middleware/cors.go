@@ -4,32 +4,10 @@ import (
 	"net/http"
 )
 
-// CORS middleware adds Cross-Origin Resource Sharing headers to responses
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set CORS headers
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
-
-		// Handle preflight requests
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		// Call the next handler
-		next.ServeHTTP(w, r)
-	})
-}
-
-// CORSWithConfig is a more configurable version of the CORS middleware
-func CORSWithConfig(allowedOrigins []string, allowedMethods []string, allowedHeaders []string) func(http.Handler) http.Handler {
-	// Default values if not provided
-	if len(allowedOrigins) == 0 {
-		allowedOrigins = []string{"*"}
-	}
+// CORS middleware adds Cross-Origin Resource Sharing headers to responses for
+// the allowed origins only. An origin of "*" allows every origin; with none
+// allowed, browsers only let pages of the API's own origin call it.
+func CORS(allowedOrigins []string, allowedMethods []string, allowedHeaders []string) func(http.Handler) http.Handler {
 	if len(allowedMethods) == 0 {
 		allowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	}
@@ -37,22 +15,40 @@ func CORSWithConfig(allowedOrigins []string, allowedMethods []string, allowedHea
 		allowedHeaders = []string{"Content-Type", "Authorization"}
 	}
 
+	anyOrigin := false
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		origins[origin] = true
+	}
+
 	// Join arrays into comma-separated strings
-	origins := joinStrings(allowedOrigins, ", ")
 	methods := joinStrings(allowedMethods, ", ")
 	headers := joinStrings(allowedHeaders, ", ")
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Set CORS headers
-			w.Header().Set("Access-Control-Allow-Origin", origins)
-			w.Header().Set("Access-Control-Allow-Methods", methods)
-			w.Header().Set("Access-Control-Allow-Headers", headers)
-			w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
+			// The header names a single origin, so it depends on the request's
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			allowed := origin != "" && (anyOrigin || origins[origin])
+			if allowed {
+				if anyOrigin {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
+			}
 
-			// Handle preflight requests
-			if r.Method == http.MethodOptions {
-				w.WriteHeader(http.StatusOK)
+			// Handle preflight requests, which fail without the headers above
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.WriteHeader(http.StatusNoContent)
 				return
 			}
 
@@ -19,6 +19,22 @@ type Pagination struct {
 	LastPage    int `json:"last_page"`
 }
 
+// CursorPaginatedResponse provides a standard structure for responses paged
+// with cursors
+type CursorPaginatedResponse struct {
+	Data       interface{}      `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
+// CursorPagination holds cursor pagination metadata
+type CursorPagination struct {
+	PerPage        int    `json:"per_page"`
+	NextCursor     string `json:"next_cursor,omitempty"`
+	PrevCursor     string `json:"prev_cursor,omitempty"`
+	Total          *int   `json:"total,omitempty"`
+	TotalEstimated bool   `json:"total_estimated,omitempty"`
+}
+
 // JSON writes a JSON response with appropriate headers
 func JSON(w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/handlers"
+	"github.com/guillermoballester/propagatorGo/internal/leader"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterClusterRoutes sets up all cluster-related routes
+func RegisterClusterRoutes(r *mux.Router, elector *leader.Elector) {
+	clusterHandler := handlers.NewClusterHandler(elector)
+
+	// GET /cluster/leader - Instance currently running scheduled jobs
+	r.HandleFunc("/cluster/leader", clusterHandler.GetLeader).Methods(http.MethodGet)
+}
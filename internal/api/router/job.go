@@ -0,0 +1,50 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/handlers"
+	"github.com/guillermoballester/propagatorGo/internal/orchestrator"
+	"github.com/guillermoballester/propagatorGo/internal/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterJobRoutes sets up all scheduled job routes. The routes changing jobs
+// are guarded by the admin middleware.
+func RegisterJobRoutes(r *mux.Router, jobRunRepo *repository.JobRunRepository, o *orchestrator.Orchestrator, admin mux.MiddlewareFunc) {
+	jobHandler := handlers.NewJobHandler(jobRunRepo, o)
+
+	// GET /jobs - Every job with its schedule, status, next run and last error
+	r.HandleFunc("/jobs", jobHandler.GetJobs).Methods(http.MethodGet)
+
+	// GET /jobs/runs - Job run history, with filters
+	r.HandleFunc("/jobs/runs", jobHandler.GetRuns).Methods(http.MethodGet)
+
+	// GET /jobs/runs/{id} - A run and the downstream runs it triggered
+	r.HandleFunc("/jobs/runs/{id:[0-9]+}", jobHandler.GetRun).Methods(http.MethodGet)
+
+	// The run routes above take precedence over the job name routes below
+
+	// GET /jobs/{name} - A job with its schedule and current state
+	r.HandleFunc("/jobs/{name}", jobHandler.GetJob).Methods(http.MethodGet)
+
+	// Routes changing jobs require the admin token
+	adminRoutes := r.NewRoute().Subrouter()
+	adminRoutes.Use(admin)
+
+	// POST /jobs/{name}/trigger - Run a job now
+	adminRoutes.HandleFunc("/jobs/{name}/trigger", jobHandler.TriggerJob).Methods(http.MethodPost)
+
+	// POST /jobs/{name}/pause - Stop firing the scheduled runs of a job
+	adminRoutes.HandleFunc("/jobs/{name}/pause", jobHandler.PauseJob).Methods(http.MethodPost)
+
+	// POST /jobs/{name}/resume - Fire the scheduled runs of a paused job again
+	adminRoutes.HandleFunc("/jobs/{name}/resume", jobHandler.ResumeJob).Methods(http.MethodPost)
+
+	// PUT /jobs/{name}/schedule - Change the cron expression of a job
+	adminRoutes.HandleFunc("/jobs/{name}/schedule", jobHandler.RescheduleJob).Methods(http.MethodPut)
+
+	// POST /jobs/{name}/cancel - Cancel the runs of a job in progress on this instance
+	adminRoutes.HandleFunc("/jobs/{name}/cancel", jobHandler.CancelJob).Methods(http.MethodPost)
+}
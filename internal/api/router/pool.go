@@ -0,0 +1,18 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/handlers"
+	"github.com/guillermoballester/propagatorGo/internal/orchestrator"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterPoolRoutes sets up all worker pool routes
+func RegisterPoolRoutes(r *mux.Router, o *orchestrator.Orchestrator) {
+	poolHandler := handlers.NewPoolHandler(o)
+
+	// GET /pools - Worker pool stats, including autoscaling decisions
+	r.HandleFunc("/pools", poolHandler.GetStats).Methods(http.MethodGet)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/handlers"
+	"github.com/guillermoballester/propagatorGo/internal/task"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterQueueRoutes sets up all queue-related routes
+func RegisterQueueRoutes(r *mux.Router, taskSvc *task.Service) {
+	queueHandler := handlers.NewQueueHandler(taskSvc)
+
+	// GET /queues/backpressure - Watermarks and backpressure state per queue
+	r.HandleFunc("/queues/backpressure", queueHandler.GetBackpressure).Methods(http.MethodGet)
+}
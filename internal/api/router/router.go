@@ -2,30 +2,64 @@ package router
 
 import (
 	"net/http"
+	"sync/atomic"
 
+	"github.com/guillermoballester/propagatorGo/internal/api/handlers"
 	"github.com/guillermoballester/propagatorGo/internal/api/middleware"
 	"github.com/guillermoballester/propagatorGo/internal/api/response"
 	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/leader"
+	"github.com/guillermoballester/propagatorGo/internal/orchestrator"
 	"github.com/guillermoballester/propagatorGo/internal/repository"
+	scraper "github.com/guillermoballester/propagatorGo/internal/scrapper"
+	"github.com/guillermoballester/propagatorGo/internal/task"
 
 	"github.com/gorilla/mux"
 )
 
+// Dependencies contains everything the route handlers need
+type Dependencies struct {
+	ArticleRepo  *repository.ArticleRepository
+	JobRunRepo   *repository.JobRunRepository
+	Elector      *leader.Elector
+	TaskService  *task.Service
+	Orchestrator *orchestrator.Orchestrator
+	ScraperSvc   *scraper.Service
+
+	// Ready is set once the instance may receive traffic
+	Ready *atomic.Bool
+
+	// HealthChecks are the dependencies reported by the health endpoint
+	HealthChecks map[string]handlers.HealthCheck
+}
+
 // Setup configures the main application router with all routes
-func Setup(cfg *config.Config, articleRepo *repository.ArticleRepository) *mux.Router {
+func Setup(cfg *config.Config, deps *Dependencies) *mux.Router {
 	r := mux.NewRouter()
 
 	// Apply global middleware
-	r.Use(middleware.CORS)
+	r.Use(middleware.CORS(cfg.App.AllowedOrigins, nil, nil))
+
+	// Middleware only runs on matched routes, so preflight requests need one
+	r.Methods(http.MethodOptions).HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
 
 	// Create API subrouter with version prefix
 	api := r.PathPrefix(cfg.App.APIPrefix).Subrouter()
 
 	// Register route groups
-	RegisterNewsRoutes(api, articleRepo)
+	RegisterNewsRoutes(api, deps.ArticleRepo)
+	RegisterSearchRoutes(api, deps.ArticleRepo)
+	RegisterClusterRoutes(api, deps.Elector)
+	RegisterQueueRoutes(api, deps.TaskService)
+	RegisterPoolRoutes(api, deps.Orchestrator)
+	RegisterSourceRoutes(api, deps.ScraperSvc)
+	RegisterJobRoutes(api, deps.JobRunRepo, deps.Orchestrator, middleware.AdminToken(cfg.App.AdminToken))
 
-	// Health check endpoint
-	api.HandleFunc("/health", healthCheckHandler).Methods(http.MethodGet)
+	// Health check endpoint, failing until the instance is ready
+	healthHandler := handlers.NewHealthHandler(cfg.App.Version, cfg.App.Mode, deps.Ready, deps.HealthChecks)
+	api.HandleFunc("/health", healthHandler.GetHealth).Methods(http.MethodGet)
 
 	// Not found handler
 	r.NotFoundHandler = http.HandlerFunc(notFoundHandler)
@@ -33,15 +67,6 @@ func Setup(cfg *config.Config, articleRepo *repository.ArticleRepository) *mux.R
 	return r
 }
 
-// healthCheckHandler provides a simple health check endpoint
-func healthCheckHandler(w http.ResponseWriter, _ *http.Request) {
-	response.JSON(w, map[string]string{
-		"status":    "ok",
-		"timestamp": http.TimeFormat,
-		"version":   "1.0.0", // This should come from your app config
-	}, http.StatusOK)
-}
-
 // notFoundHandler provides a custom 404 response
 func notFoundHandler(w http.ResponseWriter, _ *http.Request) {
 	response.NotFound(w, "The requested resource could not be found")
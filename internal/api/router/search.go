@@ -0,0 +1,18 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/handlers"
+	"github.com/guillermoballester/propagatorGo/internal/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterSearchRoutes sets up all search-related routes
+func RegisterSearchRoutes(r *mux.Router, articleRepo *repository.ArticleRepository) {
+	searchHandler := handlers.NewSearchHandler(articleRepo)
+
+	// GET /search - Full-text search over articles
+	r.HandleFunc("/search", searchHandler.Search).Methods(http.MethodGet)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/guillermoballester/propagatorGo/internal/api/handlers"
+	scraper "github.com/guillermoballester/propagatorGo/internal/scrapper"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterSourceRoutes sets up all source-related routes
+func RegisterSourceRoutes(r *mux.Router, scraperSvc *scraper.Service) {
+	sourceHandler := handlers.NewSourceHandler(scraperSvc)
+
+	// GET /sources/breakers - Circuit breaker state per source
+	r.HandleFunc("/sources/breakers", sourceHandler.GetBreakers).Methods(http.MethodGet)
+}
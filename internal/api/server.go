@@ -5,26 +5,30 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/guillermoballester/propagatorGo/internal/api/router"
 	"github.com/guillermoballester/propagatorGo/internal/config"
-	"github.com/guillermoballester/propagatorGo/internal/repository"
 
 	"github.com/gorilla/mux"
 )
 
 // Server represents the API server
 type Server struct {
-	httpServer  *http.Server
-	router      *mux.Router
-	config      *config.Config
-	articleRepo *repository.ArticleRepository
+	httpServer *http.Server
+	router     *mux.Router
+	config     *config.Config
+	deps       *router.Dependencies
 }
 
-// NewServer creates a new API server
-func NewServer(cfg *config.Config, articleRepo *repository.ArticleRepository) *Server {
-	r := router.Setup(cfg, articleRepo)
+// NewServer creates a new API server. Its health endpoint fails until
+// SetReady is called.
+func NewServer(cfg *config.Config, deps *router.Dependencies) *Server {
+	if deps.Ready == nil {
+		deps.Ready = &atomic.Bool{}
+	}
+	r := router.Setup(cfg, deps)
 
 	addr := fmt.Sprintf(":%d", cfg.App.Port)
 	srv := &http.Server{
@@ -36,10 +40,10 @@ func NewServer(cfg *config.Config, articleRepo *repository.ArticleRepository) *S
 	}
 
 	return &Server{
-		httpServer:  srv,
-		router:      r,
-		config:      cfg,
-		articleRepo: articleRepo,
+		httpServer: srv,
+		router:     r,
+		config:     cfg,
+		deps:       deps,
 	}
 }
 
@@ -49,6 +53,12 @@ func (s *Server) Start() error {
 	return s.httpServer.ListenAndServe()
 }
 
+// SetReady sets whether the instance may receive traffic, as reported by the
+// health endpoint
+func (s *Server) SetReady(ready bool) {
+	s.deps.Ready.Store(ready)
+}
+
 // Stop gracefully shuts down the server
 func (s *Server) Stop(ctx context.Context) error {
 	log.Println("API server shutting down gracefully")
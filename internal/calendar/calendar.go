@@ -0,0 +1,306 @@
+// Package calendar describes the trading sessions of exchanges, so jobs can
+// be scheduled around market hours and holidays
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	// Embedded so exchange time zones resolve on images without tzdata
+	_ "time/tzdata"
+)
+
+// Session is a part of a trading day
+type Session string
+
+const (
+	SessionPre     Session = "pre"     // Pre-market
+	SessionRegular Session = "regular" // Regular trading hours
+	SessionPost    Session = "post"    // After-hours
+	SessionClosed  Session = "closed"  // Outside every session, on weekends and holidays
+)
+
+// dateLayout is the layout of dates in calendar files
+const dateLayout = "2006-01-02"
+
+// sessionOrder is the order sessions follow within a trading day
+var sessionOrder = []Session{SessionPre, SessionRegular, SessionPost}
+
+// SessionHours are the opening and closing times of a session, as "15:04"
+// in the exchange time zone
+type SessionHours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// File is the format of a calendar data file
+type File struct {
+	Name        string                   `json:"name"`
+	Timezone    string                   `json:"timezone"`
+	Sessions    map[Session]SessionHours `json:"sessions"`
+	Holidays    []string                 `json:"holidays"`    // Dates the exchange is closed
+	EarlyCloses map[string]string        `json:"earlyCloses"` // Dates the regular session closes early, with the closing time
+}
+
+// Calendar holds the sessions and holidays of an exchange
+type Calendar struct {
+	name        string
+	location    *time.Location
+	sessions    map[Session]clockRange
+	holidays    map[string]bool
+	earlyCloses map[string]clock
+}
+
+// clock is a time of day, in minutes since midnight
+type clock int
+
+// clockRange is the part of a day a session covers
+type clockRange struct {
+	open  clock
+	close clock
+}
+
+// Interval is a stretch of time
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// New creates a calendar from the contents of a calendar file
+func New(f File) (*Calendar, error) {
+	if f.Name == "" {
+		return nil, fmt.Errorf("calendar name is required")
+	}
+
+	location, err := time.LoadLocation(f.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("calendar %s: invalid timezone %q: %w", f.Name, f.Timezone, err)
+	}
+
+	c := &Calendar{
+		name:        strings.ToUpper(f.Name),
+		location:    location,
+		sessions:    make(map[Session]clockRange, len(f.Sessions)),
+		holidays:    make(map[string]bool, len(f.Holidays)),
+		earlyCloses: make(map[string]clock, len(f.EarlyCloses)),
+	}
+
+	if _, ok := f.Sessions[SessionRegular]; !ok {
+		return nil, fmt.Errorf("calendar %s: a regular session is required", f.Name)
+	}
+	for session, hours := range f.Sessions {
+		if !validSession(session) {
+			return nil, fmt.Errorf("calendar %s: unknown session %q", f.Name, session)
+		}
+		open, err := parseClock(hours.Open)
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: %s session: %w", f.Name, session, err)
+		}
+		closeAt, err := parseClock(hours.Close)
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: %s session: %w", f.Name, session, err)
+		}
+		if closeAt <= open {
+			return nil, fmt.Errorf("calendar %s: %s session closes before it opens", f.Name, session)
+		}
+		c.sessions[session] = clockRange{open: open, close: closeAt}
+	}
+
+	for _, date := range f.Holidays {
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return nil, fmt.Errorf("calendar %s: invalid holiday %q: %w", f.Name, date, err)
+		}
+		c.holidays[date] = true
+	}
+
+	for date, closeAt := range f.EarlyCloses {
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return nil, fmt.Errorf("calendar %s: invalid early close date %q: %w", f.Name, date, err)
+		}
+		at, err := parseClock(closeAt)
+		if err != nil {
+			return nil, fmt.Errorf("calendar %s: early close on %s: %w", f.Name, date, err)
+		}
+		c.earlyCloses[date] = at
+	}
+
+	return c, nil
+}
+
+// Load reads a calendar file
+func Load(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading calendar file: %w", err)
+	}
+
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("error parsing calendar file %s: %w", path, err)
+	}
+
+	return New(f)
+}
+
+// Name returns the exchange name, in upper case
+func (c *Calendar) Name() string {
+	return c.name
+}
+
+// Location returns the exchange time zone
+func (c *Calendar) Location() *time.Location {
+	return c.location
+}
+
+// IsTradingDay reports whether the exchange opens on the day of t, in the
+// exchange time zone
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	local := t.In(c.location)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.holidays[local.Format(dateLayout)]
+}
+
+// SessionAt returns the session in progress at t
+func (c *Calendar) SessionAt(t time.Time) Session {
+	for _, session := range sessionOrder {
+		for _, interval := range c.intervals(t, session) {
+			if !t.Before(interval.Start) && t.Before(interval.End) {
+				return session
+			}
+		}
+	}
+	return SessionClosed
+}
+
+// InSessions reports whether one of the given sessions is in progress at t
+func (c *Calendar) InSessions(t time.Time, sessions []Session) bool {
+	current := c.SessionAt(t)
+	for _, session := range sessions {
+		if session == current {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the first instant at or after t when one of the given
+// sessions is in progress. It returns the zero time if none opens within a year.
+func (c *Calendar) NextOpen(t time.Time, sessions []Session) time.Time {
+	local := t.In(c.location)
+	for day := 0; day <= 366; day++ {
+		for _, interval := range c.dayIntervals(local.AddDate(0, 0, day), sessions) {
+			if interval.End.After(t) {
+				if interval.Start.After(t) {
+					return interval.Start
+				}
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// NextClose returns the instant the given sessions stop being in progress,
+// counting consecutive sessions as one, or t itself if none is in progress
+func (c *Calendar) NextClose(t time.Time, sessions []Session) time.Time {
+	end := t
+	for _, interval := range c.dayIntervals(t, sessions) {
+		if !end.Before(interval.Start) && end.Before(interval.End) {
+			end = interval.End
+		}
+	}
+	return end
+}
+
+// dayIntervals returns the intervals of the given sessions on the day of t,
+// in chronological order
+func (c *Calendar) dayIntervals(t time.Time, sessions []Session) []Interval {
+	var intervals []Interval
+	for _, session := range sessions {
+		intervals = append(intervals, c.intervals(t, session)...)
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+	return intervals
+}
+
+// intervals returns when a session runs on the day of t, taking holidays and
+// early closes into account. An early close ends the regular session early
+// and moves the start of the post-market session along with it.
+func (c *Calendar) intervals(t time.Time, session Session) []Interval {
+	hours, ok := c.sessions[session]
+	if !ok || !c.IsTradingDay(t) {
+		return nil
+	}
+
+	local := t.In(c.location)
+	if early, ok := c.earlyCloses[local.Format(dateLayout)]; ok {
+		switch session {
+		case SessionRegular:
+			if early < hours.close {
+				hours.close = early
+			}
+		case SessionPost:
+			if early < hours.open {
+				hours.close -= hours.open - early
+				hours.open = early
+			}
+		}
+	}
+
+	year, month, day := local.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, c.location)
+	return []Interval{{
+		Start: at(midnight, hours.open),
+		End:   at(midnight, hours.close),
+	}}
+}
+
+// at returns the instant a time of day falls on, in the day's time zone
+func at(midnight time.Time, clk clock) time.Time {
+	year, month, day := midnight.Date()
+	return time.Date(year, month, day, int(clk)/60, int(clk)%60, 0, 0, midnight.Location())
+}
+
+// parseClock parses a "15:04" time of day
+func parseClock(value string) (clock, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return clock(t.Hour()*60 + t.Minute()), nil
+}
+
+// validSession reports whether a session can be configured
+func validSession(session Session) bool {
+	for _, s := range sessionOrder {
+		if s == session {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSessions converts session names, defaulting to the regular session
+func ParseSessions(names []string) ([]Session, error) {
+	if len(names) == 0 {
+		return []Session{SessionRegular}, nil
+	}
+
+	sessions := make([]Session, 0, len(names))
+	for _, name := range names {
+		session := Session(strings.ToLower(name))
+		if !validSession(session) {
+			return nil, fmt.Errorf("unknown session %q", name)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+// everyMinutes fires at every multiple of n minutes
+type everyMinutes int
+
+func (e everyMinutes) Next(t time.Time) time.Time {
+	step := time.Duration(e) * time.Minute
+	return t.Truncate(step).Add(step)
+}
+
+func testCalendar(t *testing.T) *Calendar {
+	t.Helper()
+
+	c, err := New(File{
+		Name:     "nyse",
+		Timezone: "America/New_York",
+		Sessions: map[Session]SessionHours{
+			SessionPre:     {Open: "04:00", Close: "09:30"},
+			SessionRegular: {Open: "09:30", Close: "16:00"},
+			SessionPost:    {Open: "16:00", Close: "20:00"},
+		},
+		Holidays:    []string{"2026-12-25"},
+		EarlyCloses: map[string]string{"2026-12-24": "13:00"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error creating calendar, got %v", err)
+	}
+	return c
+}
+
+func newYork(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Expected no error loading location, got %v", err)
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, location)
+	if err != nil {
+		t.Fatalf("Expected no error parsing %q, got %v", value, err)
+	}
+	return parsed
+}
+
+func TestSessionAt(t *testing.T) {
+	c := testCalendar(t)
+
+	cases := []struct {
+		at   string
+		want Session
+	}{
+		{"2026-12-23 03:59", SessionClosed},
+		{"2026-12-23 04:00", SessionPre},
+		{"2026-12-23 09:30", SessionRegular},
+		{"2026-12-23 15:59", SessionRegular},
+		{"2026-12-23 16:00", SessionPost},
+		{"2026-12-23 20:00", SessionClosed},
+		{"2026-12-24 13:30", SessionPost},    // Early close
+		{"2026-12-25 10:00", SessionClosed},  // Holiday
+		{"2026-12-26 10:00", SessionClosed},  // Saturday
+		{"2026-03-09 09:30", SessionRegular}, // First day after the DST change
+	}
+
+	for _, tc := range cases {
+		if got := c.SessionAt(newYork(t, tc.at)); got != tc.want {
+			t.Errorf("At %s expected session %s, got %s", tc.at, tc.want, got)
+		}
+	}
+}
+
+func TestSessionScheduleSkipsHolidaysAndWeekends(t *testing.T) {
+	c := testCalendar(t)
+	schedule := NewSessionSchedule(c, []Session{SessionRegular}, everyMinutes(5), nil)
+
+	// After the early close on Christmas Eve the next regular session is on Monday
+	next := schedule.Next(newYork(t, "2026-12-24 12:58"))
+	if want := newYork(t, "2026-12-28 09:30"); !next.Equal(want) {
+		t.Errorf("Expected next run at %s, got %s", want, next.In(want.Location()))
+	}
+}
+
+func TestSessionScheduleUsesOffSessionSchedule(t *testing.T) {
+	c := testCalendar(t)
+	schedule := NewSessionSchedule(c, []Session{SessionRegular}, everyMinutes(5), everyMinutes(60))
+
+	cases := []struct {
+		from string
+		want string
+	}{
+		{"2026-12-23 10:02", "2026-12-23 10:05"}, // In session, every 5 minutes
+		{"2026-12-23 16:02", "2026-12-23 17:00"}, // After the close, hourly
+		{"2026-12-23 08:10", "2026-12-23 09:00"}, // Before the open, hourly
+		{"2026-12-23 09:01", "2026-12-23 09:30"}, // The open comes before the next hour
+		{"2026-12-25 10:30", "2026-12-25 11:00"}, // Holiday, hourly
+	}
+
+	for _, tc := range cases {
+		next := schedule.Next(newYork(t, tc.from))
+		if want := newYork(t, tc.want); !next.Equal(want) {
+			t.Errorf("From %s expected next run at %s, got %s", tc.from, want, next.In(want.Location()))
+		}
+	}
+}
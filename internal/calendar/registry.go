@@ -0,0 +1,59 @@
+package calendar
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Registry holds the calendars of every known exchange
+type Registry struct {
+	calendars map[string]*Calendar
+}
+
+// NewRegistry creates a registry holding the given calendars
+func NewRegistry(calendars ...*Calendar) *Registry {
+	r := &Registry{calendars: make(map[string]*Calendar, len(calendars))}
+	for _, c := range calendars {
+		r.calendars[c.Name()] = c
+	}
+	return r
+}
+
+// LoadDir loads every calendar file (*.json) of a directory
+func LoadDir(dir string) (*Registry, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing calendar files: %w", err)
+	}
+
+	r := NewRegistry()
+	for _, path := range paths {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := r.calendars[c.Name()]; exists {
+			return nil, fmt.Errorf("calendar %s is defined twice", c.Name())
+		}
+		r.calendars[c.Name()] = c
+	}
+	return r, nil
+}
+
+// Get returns the calendar of an exchange, by case-insensitive name
+func (r *Registry) Get(name string) (*Calendar, bool) {
+	c, ok := r.calendars[strings.ToUpper(name)]
+	return c, ok
+}
+
+// Names returns the names of the known exchanges, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.calendars))
+	for name := range r.calendars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,85 @@
+package calendar
+
+import (
+	"time"
+)
+
+// maxScheduleSteps bounds how many candidate times a SessionSchedule
+// examines, so a schedule that can never fire does not loop forever
+const maxScheduleSteps = 10000
+
+// Schedule gives the next activation time after a given time. It matches
+// the cron.Schedule interface.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// SessionSchedule follows one schedule while any of a set of sessions is in
+// progress and another schedule the rest of the time, e.g. every 5 minutes
+// during the regular session and hourly otherwise
+type SessionSchedule struct {
+	calendar   *Calendar
+	sessions   []Session
+	inSession  Schedule
+	offSession Schedule // Nil when the job does not run outside the sessions
+}
+
+// NewSessionSchedule creates a schedule that follows inSession during the
+// given sessions and offSession, which may be nil, outside of them
+func NewSessionSchedule(c *Calendar, sessions []Session, inSession, offSession Schedule) *SessionSchedule {
+	return &SessionSchedule{
+		calendar:   c,
+		sessions:   sessions,
+		inSession:  inSession,
+		offSession: offSession,
+	}
+}
+
+// Next returns the next activation time after t, or the zero time if the
+// schedule will not fire again
+func (s *SessionSchedule) Next(t time.Time) time.Time {
+	next := s.nextInSession(t)
+	if s.offSession == nil {
+		return next
+	}
+
+	off := s.nextOffSession(t)
+	if next.IsZero() || (!off.IsZero() && off.Before(next)) {
+		return off
+	}
+	return next
+}
+
+// nextInSession returns the next time inSession fires during the sessions,
+// jumping over closed periods instead of stepping through them
+func (s *SessionSchedule) nextInSession(t time.Time) time.Time {
+	cur := t
+	for i := 0; i < maxScheduleSteps; i++ {
+		candidate := s.inSession.Next(cur)
+		if candidate.IsZero() || s.calendar.InSessions(candidate, s.sessions) {
+			return candidate
+		}
+
+		open := s.calendar.NextOpen(candidate, s.sessions)
+		if open.IsZero() {
+			return time.Time{}
+		}
+		// Schedules fire strictly after the time given, so step back to include the opening
+		cur = open.Add(-time.Nanosecond)
+	}
+	return time.Time{}
+}
+
+// nextOffSession returns the next time offSession fires outside the
+// sessions, jumping over the sessions instead of stepping through them
+func (s *SessionSchedule) nextOffSession(t time.Time) time.Time {
+	cur := t
+	for i := 0; i < maxScheduleSteps; i++ {
+		candidate := s.offSession.Next(cur)
+		if candidate.IsZero() || !s.calendar.InSessions(candidate, s.sessions) {
+			return candidate
+		}
+		cur = s.calendar.NextClose(candidate, s.sessions).Add(-time.Nanosecond)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,256 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Fake is a clock whose time only moves when advanced, for tests. Timers and
+// tickers fire as Advance moves past them, and AfterFunc functions run in the
+// goroutine calling Advance before it returns.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{} // Closed whenever a waiter is added
+}
+
+// fakeWaiter is a pending timer or ticker of a fake clock
+type fakeWaiter struct {
+	clock  *Fake
+	at     time.Time
+	period time.Duration // Zero for timers
+	c      chan time.Time
+	f      func()
+}
+
+// NewFake creates a fake clock set to now
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After waits for d to elapse on the fake clock
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the fake clock is advanced by d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTimer creates a timer firing once the fake clock is advanced by d
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: f, c: make(chan time.Time, 1)}
+	w.Reset(d)
+	return w
+}
+
+// NewTicker creates a ticker firing every time the fake clock is advanced by d
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	w := &fakeWaiter{clock: f, period: d, c: make(chan time.Time, 1)}
+	w.Reset(d)
+	return fakeTicker{w}
+}
+
+// AfterFunc runs fn once the fake clock is advanced by d
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &fakeWaiter{clock: f, f: fn}
+	w.Reset(d)
+	return w
+}
+
+// WithTimeout returns a copy of ctx cancelled once the fake clock is advanced
+// by d
+func (f *Fake) WithTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	tctx := &timeoutContext{Context: ctx, done: make(chan struct{})}
+
+	timer := f.AfterFunc(d, func() {
+		tctx.cancel(context.DeadlineExceeded)
+	})
+	stop := context.AfterFunc(ctx, func() {
+		tctx.cancel(ctx.Err())
+	})
+
+	return tctx, func() {
+		timer.Stop()
+		stop()
+		tctx.cancel(context.Canceled)
+	}
+}
+
+// Advance moves the fake time forward by d, firing the timers and tickers due
+// on the way in order
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	end := f.now.Add(d)
+	for {
+		w := f.next(end)
+		if w == nil {
+			break
+		}
+
+		f.now = w.at
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+		} else {
+			f.remove(w)
+		}
+
+		if w.f != nil {
+			// The function may use the clock, so it runs without the lock
+			f.mu.Unlock()
+			w.f()
+			f.mu.Lock()
+			continue
+		}
+		// Like time.Ticker, drop ticks the receiver is too slow for
+		select {
+		case w.c <- f.now:
+		default:
+		}
+	}
+	if end.After(f.now) {
+		f.now = end
+	}
+}
+
+// BlockUntil waits until at least n timers and tickers are pending, so a test
+// can advance the clock once the code under test is waiting on it
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		pending := len(f.waiters)
+		changed := f.changed
+		f.mu.Unlock()
+
+		if pending >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+// next returns the earliest waiter due by end; callers must hold f.mu
+func (f *Fake) next(end time.Time) *fakeWaiter {
+	var earliest *fakeWaiter
+	for _, w := range f.waiters {
+		if !w.at.After(end) && (earliest == nil || w.at.Before(earliest.at)) {
+			earliest = w
+		}
+	}
+	return earliest
+}
+
+// remove removes a waiter, reporting whether it was pending; callers must
+// hold f.mu
+func (f *Fake) remove(w *fakeWaiter) bool {
+	for i, pending := range f.waiters {
+		if pending == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// C returns the channel the waiter fires on
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+// Stop prevents the waiter from firing, reporting whether it was pending
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+
+	return w.clock.remove(w)
+}
+
+// Reset makes the waiter fire once the clock is advanced by d from now,
+// reporting whether it was pending
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	f := w.clock
+	f.mu.Lock()
+	pending := f.remove(w)
+	w.at = f.now.Add(d)
+	f.waiters = append(f.waiters, w)
+	close(f.changed)
+	f.changed = make(chan struct{})
+	f.mu.Unlock()
+
+	// Waiters already due fire right away
+	if d <= 0 {
+		f.Advance(0)
+	}
+	return pending
+}
+
+// fakeTicker is a waiter firing every period
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+// Stop turns the ticker off
+func (t fakeTicker) Stop() {
+	t.fakeWaiter.Stop()
+}
+
+// timeoutContext is cancelled after a timeout on a fake clock. It reports no
+// deadline, since a deadline in fake time would mislead code comparing it
+// with the wall clock.
+type timeoutContext struct {
+	context.Context
+	mu   sync.Mutex
+	done chan struct{}
+	err  error
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c *timeoutContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *timeoutContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// cancel cancels the context with err, unless it was already cancelled
+func (c *timeoutContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+}
@@ -0,0 +1,74 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFakeFiresTimersInOrder(t *testing.T) {
+	start := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	var fired []time.Duration
+	f.AfterFunc(3*time.Second, func() { fired = append(fired, f.Since(start)) })
+	f.AfterFunc(time.Second, func() { fired = append(fired, f.Since(start)) })
+	stopped := f.AfterFunc(2*time.Second, func() { fired = append(fired, f.Since(start)) })
+	stopped.Stop()
+
+	f.Advance(5 * time.Second)
+
+	if len(fired) != 2 || fired[0] != time.Second || fired[1] != 3*time.Second {
+		t.Errorf("Expected timers at 1s and 3s, got %v", fired)
+	}
+	if got := f.Since(start); got != 5*time.Second {
+		t.Errorf("Expected 5s elapsed, got %s", got)
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	ticker := f.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for i := 0; i < 3; i++ {
+		f.Advance(time.Minute)
+		select {
+		case <-ticker.C():
+		default:
+			t.Fatalf("Expected tick %d", i+1)
+		}
+	}
+
+	f.Advance(30 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Errorf("Expected no tick before the period elapsed")
+	default:
+	}
+}
+
+func TestFakeWithTimeout(t *testing.T) {
+	f := NewFake(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	ctx, cancel := f.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f.Advance(999 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatalf("Expected context alive before the timeout, got %v", ctx.Err())
+	}
+
+	f.Advance(time.Millisecond)
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("Expected %v, got %v", context.DeadlineExceeded, ctx.Err())
+	}
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	child, cancelChild := f.WithTimeout(parent, time.Hour)
+	defer cancelChild()
+	cancelParent()
+	<-child.Done()
+	if child.Err() != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, child.Err())
+	}
+}
@@ -3,8 +3,11 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/constants"
 )
 
 // Config represents the main application configuration
@@ -15,6 +18,9 @@ type Config struct {
 	Redis     RedisConfig     `json:"redis"`
 	StockList StockList       `json:"stockList"`
 	Database  DatabaseConfig  `json:"database"`
+	Cluster   ClusterConfig   `json:"cluster"`
+	Queues    []QueueConfig   `json:"queues,omitempty"`
+	Pools     []WorkerConfig  `json:"pools"` // Worker pools, each run by the scheduler job named by its JobName
 }
 
 type DatabaseConfig struct {
@@ -36,6 +42,7 @@ type Stock struct {
 	Symbol  string `json:"symbol"`
 	Name    string `json:"name,omitempty"`
 	Enabled bool   `json:"enabled"`
+	Tier    string `json:"tier,omitempty"` // Priority tier used by adaptive scheduling (high, normal, low)
 }
 
 // SiteConfig stores the selector configuration for each website
@@ -58,6 +65,20 @@ type AppConfig struct {
 	LogLevel  string `json:"logLevel"`
 	APIPrefix string `json:"apiPrefix"`
 	Env       string `json:"env"` // development, production, testing
+
+	// Mode decides whether the process serves the API, runs the workers, or both (default)
+	Mode string `json:"mode,omitempty"`
+
+	// ShutdownTimeout is how long workers may take to finish in-flight items on shutdown
+	ShutdownTimeout time.Duration `json:"shutdownTimeout,omitempty"`
+
+	// AdminToken is the bearer token required by the endpoints that change
+	// jobs; they are disabled when it is empty
+	AdminToken string `json:"adminToken,omitempty"`
+
+	// AllowedOrigins lists the origins browsers may call the API from, "*"
+	// for any; empty allows the API's own origin only
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
 }
 
 // ScraperConfig contains settings for web scraping
@@ -68,22 +89,111 @@ type ScraperConfig struct {
 	RandomDelay   time.Duration `json:"randomDelay"`
 	Sites         []SiteConfig  `json:"sites"`
 	ParallelLimit int           `json:"parallelLimit"`
+
+	Scheduling     SchedulingPolicyConfig `json:"scheduling,omitempty"`
+	CircuitBreaker CircuitBreakerConfig   `json:"circuitBreaker,omitempty"`
+}
+
+// CircuitBreakerConfig controls when scraping a source is suspended. The
+// breaker opens once FailureRate of the last WindowSize scrapes failed, then
+// lets a single probe through after OpenDuration, doubling the wait after
+// every failed probe up to MaxOpenDuration.
+type CircuitBreakerConfig struct {
+	WindowSize      int           `json:"windowSize,omitempty"`
+	MinRequests     int           `json:"minRequests,omitempty"` // Scrapes needed in the window before it can trip
+	FailureRate     float64       `json:"failureRate,omitempty"`
+	OpenDuration    time.Duration `json:"openDuration,omitempty"`
+	MaxOpenDuration time.Duration `json:"maxOpenDuration,omitempty"`
+}
+
+// SchedulingPolicyConfig controls how scrapers choose the next symbol. The
+// adaptive policy scrapes each symbol somewhere between MinInterval and
+// MaxInterval depending on its tier, its recent article yield and failures.
+type SchedulingPolicyConfig struct {
+	Policy      string             `json:"policy,omitempty"` // roundrobin (default) or adaptive
+	MinInterval time.Duration      `json:"minInterval,omitempty"`
+	MaxInterval time.Duration      `json:"maxInterval,omitempty"`
+	TierWeights map[string]float64 `json:"tierWeights,omitempty"` // Higher weights are scraped more often
 }
 
 // SchedulerConfig contains settings for job scheduling
 type SchedulerConfig struct {
 	Jobs           []JobConfig   `json:"jobs"`
 	DefaultTimeout time.Duration `json:"defaultTimeout"`
+
+	// HistoryRetention is how long job runs are kept in the database (30 days by default)
+	HistoryRetention time.Duration `json:"historyRetention,omitempty"`
+
+	// CalendarDir holds the exchange calendar files used by calendar-aware jobs
+	CalendarDir string `json:"calendarDir,omitempty"`
 }
 
 // JobConfig represents a schedulable job configuration
 type JobConfig struct {
-	Name        string        `json:"name"`
-	CronExpr    string        `json:"cronExpr"`
-	Timeout     time.Duration `json:"timeout"`
-	RetryCount  int           `json:"retryCount"`
-	Enabled     bool          `json:"enabled"`
-	Description string        `json:"description"`
+	Name         string        `json:"name"`
+	Kind         string        `json:"kind,omitempty"` // "pool" (default) runs the worker pool linked by name, "dispatch" enqueues scrape tasks, "apiCall" enqueues APICalls
+	CronExpr     string        `json:"cronExpr"`
+	Timeout      time.Duration `json:"timeout"`                // Per attempt; DefaultTimeout when unset
+	RetryCount   int           `json:"retryCount"`             // Extra attempts after a failed run
+	RetryBackoff time.Duration `json:"retryBackoff,omitempty"` // Delay before the first retry, doubled for each further retry
+	Enabled      bool          `json:"enabled"`
+	Description  string        `json:"description"`
+
+	// Overlap decides what happens when the job is triggered while it is
+	// still running: "skip" (default), "queue-one", "replace" or "allow".
+	// Pool jobs cannot allow overlapping runs.
+	Overlap string `json:"overlap,omitempty"`
+
+	// DependsOn lists jobs that must all complete before this job runs; a job
+	// with dependencies may leave CronExpr empty to run only when they
+	// complete. OnFailure lists jobs to run when this job fails.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	OnFailure []string `json:"onFailure,omitempty"`
+
+	// CatchUp decides what happens on startup to the scheduled runs missed
+	// while no instance was running: "none" (default), "once" or "all", which
+	// runs every missed run up to CatchUpLimit (5 by default)
+	CatchUp      string `json:"catchUp,omitempty"`
+	CatchUpLimit int    `json:"catchUpLimit,omitempty"`
+
+	// Calendar restricts CronExpr to the trading sessions of an exchange
+	Calendar *CalendarScheduleConfig `json:"calendar,omitempty"`
+
+	// RunOnStart runs the job once when the application starts, after StartDelay
+	RunOnStart bool          `json:"runOnStart,omitempty"`
+	StartDelay time.Duration `json:"startDelay,omitempty"`
+
+	// Sources lists the sites a dispatch job enqueues scrape tasks for; empty
+	// means every enabled site
+	Sources []string `json:"sources,omitempty"`
+
+	// APICalls lists the calls an apiCall job enqueues; {symbol} in a URL,
+	// header or body expands into one call per enabled stock
+	APICalls []APICallConfig `json:"apiCalls,omitempty"`
+}
+
+// APICallConfig describes an HTTP request enqueued by an apiCall job
+type APICallConfig struct {
+	Method         string            `json:"method,omitempty"` // GET when empty
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	ExpectedStatus []int             `json:"expectedStatus,omitempty"` // Any 2xx when empty
+	Timeout        time.Duration     `json:"timeout,omitempty"`
+	MaxAttempts    int               `json:"maxAttempts,omitempty"` // Attempts for network errors, 429 and 5xx
+	Handler        string            `json:"handler,omitempty"`     // Overrides the API pool's apiHandler
+	FollowUp       string            `json:"followUp,omitempty"`    // Overrides the API pool's followUpTaskType
+	Source         string            `json:"source,omitempty"`      // Optional, for stats breakdowns
+}
+
+// CalendarScheduleConfig makes a job follow an exchange's trading calendar:
+// CronExpr only fires while one of Sessions is in progress, and
+// OffSessionCronExpr, when set, fires the rest of the time, including
+// weekends and holidays
+type CalendarScheduleConfig struct {
+	Exchange           string   `json:"exchange"`                     // Calendar name, e.g. NYSE
+	Sessions           []string `json:"sessions,omitempty"`           // pre, regular (default) and/or post
+	OffSessionCronExpr string   `json:"offSessionCronExpr,omitempty"` // Schedule outside the sessions
 }
 
 // RedisConfig represents Redis connection settings
@@ -92,15 +202,95 @@ type RedisConfig struct {
 	Password string `json:"password"`
 }
 
+// QueueConfig defines flow-control watermarks for a task queue. Producers
+// back off once the queue reaches HighWatermark and resume when it drains
+// to LowWatermark.
+type QueueConfig struct {
+	TaskType      string        `json:"taskType"`
+	HighWatermark int64         `json:"highWatermark"`
+	LowWatermark  int64         `json:"lowWatermark"`
+	Mode          string        `json:"mode,omitempty"`          // "pause" (default) or "throttle"
+	ThrottleDelay time.Duration `json:"throttleDelay,omitempty"` // Extra delay per item in throttle mode
+	CheckInterval time.Duration `json:"checkInterval,omitempty"` // How often the queue length is sampled
+}
+
+// ClusterConfig controls how several instances coordinate with each other
+type ClusterConfig struct {
+	InstanceID string        `json:"instanceId,omitempty"` // Defaults to hostname-pid
+	LeaderKey  string        `json:"leaderKey,omitempty"`  // Redis key holding the leader lease
+	LeaseTTL   time.Duration `json:"leaseTTL,omitempty"`   // How long a lease lasts without renewal
+}
+
 // WorkerConfig defines configuration for a worker pool
 type WorkerConfig struct {
 	PoolSize   int    `json:"poolSize"`
 	WorkerType string `json:"workerType"`
-	JobName    string `json:"jobName"`
-	CronExpr   string `json:"cronExpr"`
+	JobName    string `json:"jobName"` // Scheduler job that runs the pool
 	QueueName  string `json:"queueName,omitempty"`
 	Source     string `json:"source,omitempty"`
 	Enabled    bool   `json:"enabled"`
+
+	// ScrapeMode selects how scraper workers obtain symbols: "roundrobin"
+	// (default) cycles the local stock list, "queue" pulls scrape tasks
+	// enqueued by a dispatch job so several instances can share the work.
+	ScrapeMode string `json:"scrapeMode,omitempty"`
+
+	// RunMode decides when the pool's job completes: "window" (default) runs
+	// for RunWindow, "pass" finishes after one pass over the enabled stocks
+	// or once the pool's queue is drained
+	RunMode   string        `json:"runMode,omitempty"`
+	RunWindow time.Duration `json:"runWindow,omitempty"`
+
+	// Consumer batching: articles are accumulated and saved together once
+	// BatchSize is reached or BatchWindow has elapsed. A BatchSize of 0 or 1
+	// saves each article as soon as it is dequeued.
+	BatchSize   int           `json:"batchSize,omitempty"`
+	BatchWindow time.Duration `json:"batchWindow,omitempty"`
+
+	// Pipeline lists the stages consumer workers run every article through,
+	// in order, before it is saved
+	Pipeline []PipelineStageConfig `json:"pipeline,omitempty"`
+
+	// API workers route each response to APIHandler, a handler registered on
+	// the worker factory, or enqueue it as a FollowUpTaskType task, unless the
+	// task names its own handler or follow-up
+	APIHandler       string `json:"apiHandler,omitempty"`
+	FollowUpTaskType string `json:"followUpTaskType,omitempty"`
+
+	// Autoscale lets a running pool resize itself between MinWorkers and
+	// MaxWorkers; PoolSize is ignored when it is set
+	Autoscale *AutoscaleConfig `json:"autoscale,omitempty"`
+
+	// Restart controls how crashed or failed workers are restarted
+	Restart RestartConfig `json:"restart,omitempty"`
+}
+
+// PipelineStageConfig configures a stage of a consumer pool's pipeline
+type PipelineStageConfig struct {
+	Name    string            `json:"name"`              // Stage registered on the worker factory
+	Timeout time.Duration     `json:"timeout,omitempty"` // Longest a stage may take per article
+	OnError string            `json:"onError,omitempty"` // "fail" (default), "continue" or "drop"
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// RestartConfig defines the restart policy applied by a pool's supervisor
+type RestartConfig struct {
+	Policy         string        `json:"policy,omitempty"`         // "on-failure" (default), "always" or "never"
+	MaxRestarts    int           `json:"maxRestarts,omitempty"`    // Restarts allowed within Window before escalating
+	Window         time.Duration `json:"window,omitempty"`         // Period over which restarts are counted
+	InitialBackoff time.Duration `json:"initialBackoff,omitempty"` // Delay before the first restart, doubled each time
+	MaxBackoff     time.Duration `json:"maxBackoff,omitempty"`
+}
+
+// AutoscaleConfig defines how a worker pool scales with its queue
+type AutoscaleConfig struct {
+	MinWorkers           int           `json:"minWorkers"`
+	MaxWorkers           int           `json:"maxWorkers"`
+	TargetQueuePerWorker int64         `json:"targetQueuePerWorker,omitempty"` // Pending items one worker should handle
+	MaxLatency           time.Duration `json:"maxLatency,omitempty"`           // Average item latency that triggers a scale-up
+	Interval             time.Duration `json:"interval,omitempty"`             // How often scaling is evaluated
+	ScaleUpCooldown      time.Duration `json:"scaleUpCooldown,omitempty"`
+	ScaleDownCooldown    time.Duration `json:"scaleDownCooldown,omitempty"`
 }
 
 // LoadConfig loads the configuration from a JSON file
@@ -134,6 +324,14 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("app name is required")
 	}
 
+	switch cfg.App.Mode {
+	case "":
+		cfg.App.Mode = constants.AppModeAll
+	case constants.AppModeAll, constants.AppModeAPI, constants.AppModeWorkers:
+	default:
+		return fmt.Errorf("unknown app mode: %s", cfg.App.Mode)
+	}
+
 	if len(cfg.Scraper.Sites) == 0 {
 		return fmt.Errorf("at least one scraper site must be configured")
 	}
@@ -153,9 +351,126 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("at least one scheduler job must be configured")
 	}
 
+	if err := validateJobs(cfg); err != nil {
+		return err
+	}
+
 	if len(cfg.StockList.Stocks) == 0 {
 		return fmt.Errorf("at least one stock list must be configured")
 	}
 
+	for _, q := range cfg.Queues {
+		if q.TaskType == "" {
+			return fmt.Errorf("queue task type is required")
+		}
+		if q.HighWatermark <= 0 || q.LowWatermark < 0 || q.LowWatermark >= q.HighWatermark {
+			return fmt.Errorf("queue %s: low watermark must be below a positive high watermark", q.TaskType)
+		}
+	}
+
+	applyClusterDefaults(&cfg.Cluster)
+
+	return nil
+}
+
+// validateJobs checks that job names are unique and that every pool is linked
+// to a pool job
+func validateJobs(cfg *Config) error {
+	jobs := make(map[string]JobConfig, len(cfg.Scheduler.Jobs))
+	for _, job := range cfg.Scheduler.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("job name is required")
+		}
+		if _, exists := jobs[job.Name]; exists {
+			return fmt.Errorf("job %s is configured twice", job.Name)
+		}
+		if job.RetryCount < 0 {
+			return fmt.Errorf("job %s: retry count cannot be negative", job.Name)
+		}
+		switch job.Kind {
+		case "", constants.JobKindPool, constants.JobKindDispatch:
+		case constants.JobKindAPICall:
+			if err := validateAPICalls(job); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("job %s: unknown kind: %s", job.Name, job.Kind)
+		}
+		switch job.Overlap {
+		case "", constants.OverlapSkip, constants.OverlapQueueOne, constants.OverlapReplace:
+		case constants.OverlapAllow:
+			if job.Kind == "" || job.Kind == constants.JobKindPool {
+				return fmt.Errorf("job %s: a worker pool cannot run twice at once, overlap %s is not allowed", job.Name, job.Overlap)
+			}
+		default:
+			return fmt.Errorf("job %s: unknown overlap policy: %s", job.Name, job.Overlap)
+		}
+		switch job.CatchUp {
+		case "", constants.CatchUpNone:
+		case constants.CatchUpOnce, constants.CatchUpAll:
+			if job.RunOnStart {
+				return fmt.Errorf("job %s: runOnStart already runs the job at startup, catch up %s is not allowed", job.Name, job.CatchUp)
+			}
+		default:
+			return fmt.Errorf("job %s: unknown catch-up policy: %s", job.Name, job.CatchUp)
+		}
+		jobs[job.Name] = job
+	}
+
+	pools := make(map[string]bool, len(cfg.Pools))
+	for _, pool := range cfg.Pools {
+		job, ok := jobs[pool.JobName]
+		if !ok {
+			return fmt.Errorf("pool %s: no job with that name is configured", pool.JobName)
+		}
+		if job.Kind != "" && job.Kind != constants.JobKindPool {
+			return fmt.Errorf("pool %s: job is of kind %s", pool.JobName, job.Kind)
+		}
+		if pools[pool.JobName] {
+			return fmt.Errorf("pool %s is configured twice", pool.JobName)
+		}
+		pools[pool.JobName] = true
+	}
+
+	return nil
+}
+
+// validateAPICalls checks the calls of an apiCall job
+func validateAPICalls(job JobConfig) error {
+	if len(job.APICalls) == 0 {
+		return fmt.Errorf("job %s: an apiCall job needs at least one api call", job.Name)
+	}
+	for i, call := range job.APICalls {
+		if call.URL == "" {
+			return fmt.Errorf("job %s: api call %d has no url", job.Name, i)
+		}
+		switch call.Method {
+		case "", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead:
+		default:
+			return fmt.Errorf("job %s: api call %d: unsupported HTTP method: %s", job.Name, i, call.Method)
+		}
+		if call.MaxAttempts < 0 || call.Timeout < 0 {
+			return fmt.Errorf("job %s: api call %d: timeout and max attempts cannot be negative", job.Name, i)
+		}
+	}
 	return nil
 }
+
+// applyClusterDefaults fills in unset cluster settings
+func applyClusterDefaults(cluster *ClusterConfig) {
+	if cluster.InstanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "propagator"
+		}
+		cluster.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	if cluster.LeaderKey == "" {
+		cluster.LeaderKey = "propagator:leader"
+	}
+
+	if cluster.LeaseTTL <= 0 {
+		cluster.LeaseTTL = 15 * time.Second
+	}
+}
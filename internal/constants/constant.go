@@ -10,9 +10,79 @@ const (
 // Task types
 const (
 	TaskTypeConsume = "consume"
+	TaskTypeScrape  = "scrape"
 	TaskTypeAPICall = "api_call"
 )
 
+// Scrape modes decide where scraper workers get their symbols from
+const (
+	ScrapeModeRoundRobin = "roundrobin" // Cycle through the local stock list
+	ScrapeModeQueue      = "queue"      // Pull scrape tasks from the shared queue
+)
+
+// Scheduling policies decide which symbol a round-robin scraper takes next
+const (
+	SchedulingRoundRobin = "roundrobin" // Every symbol in turn
+	SchedulingAdaptive   = "adaptive"   // Symbols with more news are scraped more often
+)
+
+// Stock tiers weight how often the adaptive policy scrapes a symbol
+const (
+	TierHigh   = "high"
+	TierNormal = "normal"
+	TierLow    = "low"
+)
+
+// App modes decide what a process runs
+const (
+	AppModeAll     = "all"     // Serve the API and run the workers
+	AppModeAPI     = "api"     // Only serve the API
+	AppModeWorkers = "workers" // Only run the scheduler and its worker pools
+)
+
+// Run modes decide when a pool's job is finished
+const (
+	RunModeWindow = "window" // Run for a fixed window of time
+	RunModePass   = "pass"   // Run one pass over the stocks, or until the queue is drained
+)
+
+// Job kinds decide what a configured job does
+const (
+	JobKindPool     = "pool"     // Run the worker pool linked to the job
+	JobKindDispatch = "dispatch" // Enqueue one scrape task per symbol and source
+	JobKindAPICall  = "apiCall"  // Enqueue the job's api calls
+)
+
+// Overlap policies decide what happens when a job is triggered while a
+// previous run is still in progress
+const (
+	OverlapSkip     = "skip"      // Skip the new run
+	OverlapQueueOne = "queue-one" // Start the new run once the current one ends, keeping at most one waiting
+	OverlapReplace  = "replace"   // Cancel the current run and start the new one
+	OverlapAllow    = "allow"     // Run both concurrently
+)
+
+// Catch-up policies decide what happens to the runs a job missed while no
+// instance was running
+const (
+	CatchUpNone = "none" // Ignore missed runs
+	CatchUpOnce = "once" // Run once at startup
+	CatchUpAll  = "all"  // Run once per missed run, up to a limit
+)
+
+// Backpressure modes decide how producers react to a full queue
+const (
+	BackpressureModePause    = "pause"    // Stop producing until the queue drains
+	BackpressureModeThrottle = "throttle" // Keep producing at a lower rate
+)
+
+// Restart policies decide when a supervisor restarts a worker
+const (
+	RestartOnFailure = "on-failure" // Restart after a panic or an error
+	RestartAlways    = "always"     // Restart after any exit the pool did not ask for
+	RestartNever     = "never"      // Never restart
+)
+
 // Source types
 const (
 	SourceYahoo     = "yahoo"
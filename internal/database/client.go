@@ -1,11 +1,15 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
 	"github.com/guillermoballester/propagatorGo/internal/config"
 	"github.com/guillermoballester/propagatorGo/internal/database/sqlc"
+
+	// Registers the postgres driver, also used by sqlc for array columns
+	_ "github.com/lib/pq"
 )
 
 // PostgresClient handles database operations
@@ -44,6 +48,11 @@ func (c *PostgresClient) Close() error {
 	return c.db.Close()
 }
 
+// Ping checks that the database is reachable
+func (c *PostgresClient) Ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
 func (c *PostgresClient) GetDB() *sql.DB {
 	return c.db
 }
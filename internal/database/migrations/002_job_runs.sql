@@ -0,0 +1,21 @@
+-- Create job_runs table, one row per job execution
+CREATE TABLE IF NOT EXISTS job_runs (
+                                        id BIGSERIAL PRIMARY KEY,
+                                        job_name TEXT NOT NULL,
+                                        trigger TEXT NOT NULL,
+                                        status TEXT NOT NULL,
+                                        started_at TIMESTAMPTZ NOT NULL,
+                                        finished_at TIMESTAMPTZ,
+                                        error TEXT,
+                                        items_processed BIGINT NOT NULL DEFAULT 0,
+                                        items_failed BIGINT NOT NULL DEFAULT 0,
+                                        attempts INTEGER NOT NULL DEFAULT 0,
+                                        summary TEXT,
+                                        instance_id TEXT NOT NULL
+    );
+
+-- Create an index for listing the runs of a job, latest first
+CREATE INDEX IF NOT EXISTS job_runs_job_name_started_at_idx ON job_runs(job_name, started_at DESC);
+
+-- Create an index for listing all runs and pruning old ones
+CREATE INDEX IF NOT EXISTS job_runs_started_at_idx ON job_runs(started_at);
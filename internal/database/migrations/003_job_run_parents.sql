@@ -0,0 +1,5 @@
+-- Link job runs to the upstream run that triggered them
+ALTER TABLE job_runs ADD COLUMN IF NOT EXISTS parent_run_id BIGINT REFERENCES job_runs(id) ON DELETE SET NULL;
+
+-- Create an index for finding the runs triggered by a run
+CREATE INDEX IF NOT EXISTS job_runs_parent_run_id_idx ON job_runs(parent_run_id);
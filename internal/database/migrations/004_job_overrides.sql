@@ -0,0 +1,7 @@
+-- Create job_overrides table, holding the changes made to jobs at runtime
+CREATE TABLE IF NOT EXISTS job_overrides (
+                                             job_name TEXT PRIMARY KEY,
+                                             paused BOOLEAN NOT NULL DEFAULT FALSE,
+                                             cron_expr TEXT,
+                                             updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
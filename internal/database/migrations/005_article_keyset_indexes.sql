@@ -0,0 +1,5 @@
+-- Create indexes matching the order articles are listed in, so a page is read
+-- straight from the index from the cursor on
+CREATE INDEX IF NOT EXISTS articles_symbol_scraped_at_idx ON articles(symbol, scraped_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS articles_site_name_scraped_at_idx ON articles(site_name, scraped_at DESC, id DESC);
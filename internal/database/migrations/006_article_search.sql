@@ -0,0 +1,27 @@
+-- Add a full-text search document to articles, weighting titles above text
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Keep the search document up to date as articles are saved
+CREATE OR REPLACE FUNCTION articles_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.text, '')), 'B');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS articles_search_vector_trigger ON articles;
+CREATE TRIGGER articles_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, text ON articles
+    FOR EACH ROW EXECUTE FUNCTION articles_search_vector_update();
+
+-- Fill in the search document of articles saved before this migration
+UPDATE articles SET
+    search_vector =
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(text, '')), 'B')
+WHERE search_vector IS NULL;
+
+-- Create an index for full-text search
+CREATE INDEX IF NOT EXISTS articles_search_vector_idx ON articles USING GIN(search_vector);
@@ -0,0 +1,5 @@
+-- Store the tracked symbols an article mentions, as tagged by the pipeline
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Create an index for finding the articles tagged with a symbol
+CREATE INDEX IF NOT EXISTS articles_tags_idx ON articles USING GIN(tags);
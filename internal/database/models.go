@@ -12,7 +12,31 @@ type Article struct {
 	ScrapedAt time.Time `db:"scraped_at"`
 	CreatedAt time.Time `db:"created_at"`
 	Symbol    string    `db:"symbol"`
+	Tags      []string  `db:"tags"` // Tracked symbols the article mentions
+}
+
+// JobRun records one execution of a scheduled job
+type JobRun struct {
+	ID             int64     `db:"id"`
+	JobName        string    `db:"job_name"`
+	Trigger        string    `db:"trigger"`       // scheduled, manual, dependency, on-failure or catch-up
+	ParentRunID    int64     `db:"parent_run_id"` // Upstream run that triggered this one, zero when none
+	Status         string    `db:"status"`
+	StartedAt      time.Time `db:"started_at"`
+	FinishedAt     time.Time `db:"finished_at"` // Zero while the run is in progress
+	Error          string    `db:"error"`
+	ItemsProcessed int64     `db:"items_processed"`
+	ItemsFailed    int64     `db:"items_failed"`
+	Attempts       int       `db:"attempts"`
+	Summary        string    `db:"summary"`
+	InstanceID     string    `db:"instance_id"`
+}
 
-	// Relationships (not stored directly in the database)
-	Tags []string `db:"-"`
+// JobOverride holds the changes made to a job at runtime, which take
+// precedence over its configuration
+type JobOverride struct {
+	JobName   string    `db:"job_name"`
+	Paused    bool      `db:"paused"`    // Scheduled runs are not fired
+	CronExpr  string    `db:"cron_expr"` // Empty to use the configured expression
+	UpdatedAt time.Time `db:"updated_at"`
 }
@@ -0,0 +1,18 @@
+-- name: ListJobOverrides :many
+SELECT * FROM job_overrides
+ORDER BY job_name;
+
+-- name: UpsertJobOverride :exec
+INSERT INTO job_overrides (
+    job_name, paused, cron_expr, updated_at
+) VALUES (
+             $1, $2, $3, NOW()
+         )
+ON CONFLICT (job_name) DO UPDATE
+SET paused = EXCLUDED.paused,
+    cron_expr = EXCLUDED.cron_expr,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteJobOverride :exec
+DELETE FROM job_overrides
+WHERE job_name = $1;
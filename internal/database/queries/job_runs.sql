@@ -0,0 +1,70 @@
+-- name: CreateJobRun :one
+INSERT INTO job_runs (
+    job_name, trigger, status, started_at, instance_id, parent_run_id
+) VALUES (
+             $1, $2, $3, $4, $5, $6
+         )
+RETURNING id;
+
+-- name: GetJobRun :one
+SELECT * FROM job_runs
+WHERE id = $1;
+
+-- name: GetLastScheduledJobRunStart :one
+SELECT started_at FROM job_runs
+WHERE job_name = $1 AND trigger IN ('scheduled', 'catch-up')
+ORDER BY started_at DESC
+LIMIT 1;
+
+-- name: ListDownstreamJobRuns :many
+WITH RECURSIVE downstream AS (
+    SELECT * FROM job_runs
+    WHERE job_runs.parent_run_id = $1
+    UNION ALL
+    SELECT child.* FROM job_runs child
+    JOIN downstream ON child.parent_run_id = downstream.id
+)
+SELECT * FROM downstream
+ORDER BY started_at, id;
+
+-- name: FinishJobRun :exec
+UPDATE job_runs
+SET status = $2,
+    finished_at = $3,
+    error = $4,
+    items_processed = $5,
+    items_failed = $6,
+    attempts = $7,
+    summary = $8
+WHERE id = $1;
+
+-- name: ListJobRuns :many
+SELECT * FROM job_runs
+WHERE (sqlc.narg('job_name')::text IS NULL OR job_name = sqlc.narg('job_name'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('trigger')::text IS NULL OR trigger = sqlc.narg('trigger'))
+  AND (sqlc.narg('instance_id')::text IS NULL OR instance_id = sqlc.narg('instance_id'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR started_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR started_at < sqlc.narg('until'))
+ORDER BY started_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountJobRuns :one
+SELECT COUNT(*) FROM job_runs
+WHERE (sqlc.narg('job_name')::text IS NULL OR job_name = sqlc.narg('job_name'))
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('trigger')::text IS NULL OR trigger = sqlc.narg('trigger'))
+  AND (sqlc.narg('instance_id')::text IS NULL OR instance_id = sqlc.narg('instance_id'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR started_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR started_at < sqlc.narg('until'));
+
+-- name: DeleteJobRunsBefore :execrows
+DELETE FROM job_runs
+WHERE started_at < $1;
+
+-- name: AbandonJobRuns :execrows
+UPDATE job_runs
+SET status = 'abandoned',
+    finished_at = sqlc.arg('finished_at'),
+    summary = 'instance stopped before the run finished'
+WHERE job_name = sqlc.arg('job_name') AND status = 'running' AND started_at < sqlc.arg('started_before');
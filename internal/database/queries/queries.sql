@@ -1,29 +1,123 @@
 -- name: GetArticle :one
-SELECT * FROM articles
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
 WHERE id = $1;
 
 -- name: GetArticleByURL :one
-SELECT * FROM articles
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
 WHERE url = $1;
 
--- name: GetArticleBySymbol :many
-SELECT * FROM articles
+-- name: ListArticlesBySymbol :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE symbol = $1
+ORDER BY scraped_at DESC, id DESC
+LIMIT $2;
+
+-- name: ListArticlesBySymbolBefore :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE symbol = sqlc.arg('symbol')
+  AND (scraped_at, id) < (sqlc.arg('scraped_at')::timestamptz, sqlc.arg('id')::int)
+ORDER BY scraped_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListArticlesBySymbolAfter :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE symbol = sqlc.arg('symbol')
+  AND (scraped_at, id) > (sqlc.arg('scraped_at')::timestamptz, sqlc.arg('id')::int)
+ORDER BY scraped_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: CountArticlesBySymbol :one
+SELECT COUNT(*) FROM articles
 WHERE symbol = $1;
 
--- name: GetArticleBySite :many
-SELECT * FROM articles
+-- name: ListArticlesBySite :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE site_name = $1
+ORDER BY scraped_at DESC, id DESC
+LIMIT $2;
+
+-- name: ListArticlesBySiteBefore :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE site_name = sqlc.arg('site_name')
+  AND (scraped_at, id) < (sqlc.arg('scraped_at')::timestamptz, sqlc.arg('id')::int)
+ORDER BY scraped_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListArticlesBySiteAfter :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE site_name = sqlc.arg('site_name')
+  AND (scraped_at, id) > (sqlc.arg('scraped_at')::timestamptz, sqlc.arg('id')::int)
+ORDER BY scraped_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: CountArticlesBySite :one
+SELECT COUNT(*) FROM articles
 WHERE site_name = $1;
 
 -- name: CreateArticle :one
 INSERT INTO articles (
-    title, url, text, site_name, scraped_at, symbol
+    title, url, text, site_name, scraped_at, symbol, tags
 ) VALUES (
-             $1, $2, $3, $4, $5, $6
+             $1, $2, $3, $4, $5, $6, $7
          ) ON CONFLICT (url)
     DO UPDATE SET
                   title = EXCLUDED.title,
                   text = EXCLUDED.text,
                   site_name = EXCLUDED.site_name,
                   scraped_at = EXCLUDED.scraped_at,
-                  symbol = EXCLUDED.symbol
-RETURNING *;
\ No newline at end of file
+                  symbol = EXCLUDED.symbol,
+                  tags = EXCLUDED.tags
+RETURNING id, title, url, text, site_name, scraped_at, created_at, symbol, tags;
+-- name: UpsertArticles :many
+-- Takes one array per column. Each article's tags are joined with commas, as
+-- Postgres arrays cannot hold arrays of different lengths.
+-- xmax is zero only for freshly inserted tuples, which tells inserts and updates apart
+INSERT INTO articles (title, url, text, site_name, scraped_at, symbol, tags)
+SELECT unnest(sqlc.arg('titles')::text[]),
+       unnest(sqlc.arg('urls')::text[]),
+       NULLIF(unnest(sqlc.arg('texts')::text[]), ''),
+       unnest(sqlc.arg('site_names')::text[]),
+       unnest(sqlc.arg('scraped_ats')::timestamptz[]),
+       unnest(sqlc.arg('symbols')::text[]),
+       string_to_array(unnest(sqlc.arg('tags')::text[]), ',')
+ON CONFLICT (url)
+    DO UPDATE SET
+                  title = EXCLUDED.title,
+                  text = EXCLUDED.text,
+                  site_name = EXCLUDED.site_name,
+                  scraped_at = EXCLUDED.scraped_at,
+                  symbol = EXCLUDED.symbol,
+                  tags = EXCLUDED.tags
+    WHERE (articles.title, articles.text, articles.site_name, articles.symbol, articles.tags)
+              IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.text, EXCLUDED.site_name, EXCLUDED.symbol, EXCLUDED.tags)
+RETURNING id, url, (xmax = 0)::boolean AS inserted;
+-- name: SearchArticles :many
+WITH ranked AS (
+    SELECT articles.id, articles.title, articles.url, articles.text, articles.site_name, articles.symbol,
+           articles.tags, articles.scraped_at, articles.created_at, ts_rank(articles.search_vector, query) AS rank, query
+    FROM articles, websearch_to_tsquery('english', sqlc.arg('query')::text) query
+    WHERE articles.search_vector @@ query
+      AND (sqlc.narg('symbol')::text IS NULL OR articles.symbol = sqlc.narg('symbol'))
+      AND (sqlc.narg('site_name')::text IS NULL OR articles.site_name = sqlc.narg('site_name'))
+      AND (sqlc.narg('since')::timestamptz IS NULL OR articles.scraped_at >= sqlc.narg('since'))
+      AND (sqlc.narg('until')::timestamptz IS NULL OR articles.scraped_at < sqlc.narg('until'))
+    ORDER BY rank DESC, articles.scraped_at DESC, articles.id DESC
+    LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset')
+)
+-- The text is HTML-escaped first, so the <mark> tags are the only markup in snippets
+SELECT id, title, url, site_name, symbol, tags, scraped_at, created_at, rank::real AS rank,
+       ts_headline('english',
+                   replace(replace(replace(coalesce(text, title), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+                   query,
+                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')::text AS snippet
+FROM ranked
+ORDER BY rank DESC, scraped_at DESC, id DESC;
+
+-- name: CountSearchArticles :one
+SELECT COUNT(*)
+FROM articles
+WHERE search_vector @@ websearch_to_tsquery('english', sqlc.arg('query')::text)
+  AND (sqlc.narg('symbol')::text IS NULL OR symbol = sqlc.narg('symbol'))
+  AND (sqlc.narg('site_name')::text IS NULL OR site_name = sqlc.narg('site_name'))
+  AND (sqlc.narg('since')::timestamptz IS NULL OR scraped_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until')::timestamptz IS NULL OR scraped_at < sqlc.narg('until'));
@@ -24,44 +24,142 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.abandonJobRunsStmt, err = db.PrepareContext(ctx, abandonJobRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query AbandonJobRuns: %w", err)
+	}
+	if q.countArticlesBySiteStmt, err = db.PrepareContext(ctx, countArticlesBySite); err != nil {
+		return nil, fmt.Errorf("error preparing query CountArticlesBySite: %w", err)
+	}
+	if q.countArticlesBySymbolStmt, err = db.PrepareContext(ctx, countArticlesBySymbol); err != nil {
+		return nil, fmt.Errorf("error preparing query CountArticlesBySymbol: %w", err)
+	}
+	if q.countJobRunsStmt, err = db.PrepareContext(ctx, countJobRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query CountJobRuns: %w", err)
+	}
+	if q.countSearchArticlesStmt, err = db.PrepareContext(ctx, countSearchArticles); err != nil {
+		return nil, fmt.Errorf("error preparing query CountSearchArticles: %w", err)
+	}
 	if q.createArticleStmt, err = db.PrepareContext(ctx, createArticle); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateArticle: %w", err)
 	}
-	if q.getArticleStmt, err = db.PrepareContext(ctx, getArticle); err != nil {
-		return nil, fmt.Errorf("error preparing query GetArticle: %w", err)
+	if q.createJobRunStmt, err = db.PrepareContext(ctx, createJobRun); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateJobRun: %w", err)
+	}
+	if q.deleteJobOverrideStmt, err = db.PrepareContext(ctx, deleteJobOverride); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteJobOverride: %w", err)
 	}
-	if q.getArticleBySiteStmt, err = db.PrepareContext(ctx, getArticleBySite); err != nil {
-		return nil, fmt.Errorf("error preparing query GetArticleBySite: %w", err)
+	if q.deleteJobRunsBeforeStmt, err = db.PrepareContext(ctx, deleteJobRunsBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteJobRunsBefore: %w", err)
 	}
-	if q.getArticleBySymbolStmt, err = db.PrepareContext(ctx, getArticleBySymbol); err != nil {
-		return nil, fmt.Errorf("error preparing query GetArticleBySymbol: %w", err)
+	if q.finishJobRunStmt, err = db.PrepareContext(ctx, finishJobRun); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishJobRun: %w", err)
+	}
+	if q.getArticleStmt, err = db.PrepareContext(ctx, getArticle); err != nil {
+		return nil, fmt.Errorf("error preparing query GetArticle: %w", err)
 	}
 	if q.getArticleByURLStmt, err = db.PrepareContext(ctx, getArticleByURL); err != nil {
 		return nil, fmt.Errorf("error preparing query GetArticleByURL: %w", err)
 	}
+	if q.getJobRunStmt, err = db.PrepareContext(ctx, getJobRun); err != nil {
+		return nil, fmt.Errorf("error preparing query GetJobRun: %w", err)
+	}
+	if q.getLastScheduledJobRunStartStmt, err = db.PrepareContext(ctx, getLastScheduledJobRunStart); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLastScheduledJobRunStart: %w", err)
+	}
+	if q.listArticlesBySiteStmt, err = db.PrepareContext(ctx, listArticlesBySite); err != nil {
+		return nil, fmt.Errorf("error preparing query ListArticlesBySite: %w", err)
+	}
+	if q.listArticlesBySiteAfterStmt, err = db.PrepareContext(ctx, listArticlesBySiteAfter); err != nil {
+		return nil, fmt.Errorf("error preparing query ListArticlesBySiteAfter: %w", err)
+	}
+	if q.listArticlesBySiteBeforeStmt, err = db.PrepareContext(ctx, listArticlesBySiteBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query ListArticlesBySiteBefore: %w", err)
+	}
+	if q.listArticlesBySymbolStmt, err = db.PrepareContext(ctx, listArticlesBySymbol); err != nil {
+		return nil, fmt.Errorf("error preparing query ListArticlesBySymbol: %w", err)
+	}
+	if q.listArticlesBySymbolAfterStmt, err = db.PrepareContext(ctx, listArticlesBySymbolAfter); err != nil {
+		return nil, fmt.Errorf("error preparing query ListArticlesBySymbolAfter: %w", err)
+	}
+	if q.listArticlesBySymbolBeforeStmt, err = db.PrepareContext(ctx, listArticlesBySymbolBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query ListArticlesBySymbolBefore: %w", err)
+	}
+	if q.listDownstreamJobRunsStmt, err = db.PrepareContext(ctx, listDownstreamJobRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDownstreamJobRuns: %w", err)
+	}
+	if q.listJobOverridesStmt, err = db.PrepareContext(ctx, listJobOverrides); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJobOverrides: %w", err)
+	}
+	if q.listJobRunsStmt, err = db.PrepareContext(ctx, listJobRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJobRuns: %w", err)
+	}
+	if q.searchArticlesStmt, err = db.PrepareContext(ctx, searchArticles); err != nil {
+		return nil, fmt.Errorf("error preparing query SearchArticles: %w", err)
+	}
+	if q.upsertArticlesStmt, err = db.PrepareContext(ctx, upsertArticles); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertArticles: %w", err)
+	}
+	if q.upsertJobOverrideStmt, err = db.PrepareContext(ctx, upsertJobOverride); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertJobOverride: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.abandonJobRunsStmt != nil {
+		if cerr := q.abandonJobRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing abandonJobRunsStmt: %w", cerr)
+		}
+	}
+	if q.countArticlesBySiteStmt != nil {
+		if cerr := q.countArticlesBySiteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countArticlesBySiteStmt: %w", cerr)
+		}
+	}
+	if q.countArticlesBySymbolStmt != nil {
+		if cerr := q.countArticlesBySymbolStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countArticlesBySymbolStmt: %w", cerr)
+		}
+	}
+	if q.countJobRunsStmt != nil {
+		if cerr := q.countJobRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countJobRunsStmt: %w", cerr)
+		}
+	}
+	if q.countSearchArticlesStmt != nil {
+		if cerr := q.countSearchArticlesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countSearchArticlesStmt: %w", cerr)
+		}
+	}
 	if q.createArticleStmt != nil {
 		if cerr := q.createArticleStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createArticleStmt: %w", cerr)
 		}
 	}
-	if q.getArticleStmt != nil {
-		if cerr := q.getArticleStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getArticleStmt: %w", cerr)
+	if q.createJobRunStmt != nil {
+		if cerr := q.createJobRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createJobRunStmt: %w", cerr)
+		}
+	}
+	if q.deleteJobOverrideStmt != nil {
+		if cerr := q.deleteJobOverrideStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteJobOverrideStmt: %w", cerr)
 		}
 	}
-	if q.getArticleBySiteStmt != nil {
-		if cerr := q.getArticleBySiteStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getArticleBySiteStmt: %w", cerr)
+	if q.deleteJobRunsBeforeStmt != nil {
+		if cerr := q.deleteJobRunsBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteJobRunsBeforeStmt: %w", cerr)
 		}
 	}
-	if q.getArticleBySymbolStmt != nil {
-		if cerr := q.getArticleBySymbolStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getArticleBySymbolStmt: %w", cerr)
+	if q.finishJobRunStmt != nil {
+		if cerr := q.finishJobRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishJobRunStmt: %w", cerr)
+		}
+	}
+	if q.getArticleStmt != nil {
+		if cerr := q.getArticleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getArticleStmt: %w", cerr)
 		}
 	}
 	if q.getArticleByURLStmt != nil {
@@ -69,6 +167,76 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getArticleByURLStmt: %w", cerr)
 		}
 	}
+	if q.getJobRunStmt != nil {
+		if cerr := q.getJobRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getJobRunStmt: %w", cerr)
+		}
+	}
+	if q.getLastScheduledJobRunStartStmt != nil {
+		if cerr := q.getLastScheduledJobRunStartStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLastScheduledJobRunStartStmt: %w", cerr)
+		}
+	}
+	if q.listArticlesBySiteStmt != nil {
+		if cerr := q.listArticlesBySiteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listArticlesBySiteStmt: %w", cerr)
+		}
+	}
+	if q.listArticlesBySiteAfterStmt != nil {
+		if cerr := q.listArticlesBySiteAfterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listArticlesBySiteAfterStmt: %w", cerr)
+		}
+	}
+	if q.listArticlesBySiteBeforeStmt != nil {
+		if cerr := q.listArticlesBySiteBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listArticlesBySiteBeforeStmt: %w", cerr)
+		}
+	}
+	if q.listArticlesBySymbolStmt != nil {
+		if cerr := q.listArticlesBySymbolStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listArticlesBySymbolStmt: %w", cerr)
+		}
+	}
+	if q.listArticlesBySymbolAfterStmt != nil {
+		if cerr := q.listArticlesBySymbolAfterStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listArticlesBySymbolAfterStmt: %w", cerr)
+		}
+	}
+	if q.listArticlesBySymbolBeforeStmt != nil {
+		if cerr := q.listArticlesBySymbolBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listArticlesBySymbolBeforeStmt: %w", cerr)
+		}
+	}
+	if q.listDownstreamJobRunsStmt != nil {
+		if cerr := q.listDownstreamJobRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDownstreamJobRunsStmt: %w", cerr)
+		}
+	}
+	if q.listJobOverridesStmt != nil {
+		if cerr := q.listJobOverridesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJobOverridesStmt: %w", cerr)
+		}
+	}
+	if q.listJobRunsStmt != nil {
+		if cerr := q.listJobRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJobRunsStmt: %w", cerr)
+		}
+	}
+	if q.searchArticlesStmt != nil {
+		if cerr := q.searchArticlesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing searchArticlesStmt: %w", cerr)
+		}
+	}
+	if q.upsertArticlesStmt != nil {
+		if cerr := q.upsertArticlesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertArticlesStmt: %w", cerr)
+		}
+	}
+	if q.upsertJobOverrideStmt != nil {
+		if cerr := q.upsertJobOverrideStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertJobOverrideStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -106,23 +274,65 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                     DBTX
-	tx                     *sql.Tx
-	createArticleStmt      *sql.Stmt
-	getArticleStmt         *sql.Stmt
-	getArticleBySiteStmt   *sql.Stmt
-	getArticleBySymbolStmt *sql.Stmt
-	getArticleByURLStmt    *sql.Stmt
+	db                              DBTX
+	tx                              *sql.Tx
+	abandonJobRunsStmt              *sql.Stmt
+	countArticlesBySiteStmt         *sql.Stmt
+	countArticlesBySymbolStmt       *sql.Stmt
+	countJobRunsStmt                *sql.Stmt
+	countSearchArticlesStmt         *sql.Stmt
+	createArticleStmt               *sql.Stmt
+	createJobRunStmt                *sql.Stmt
+	deleteJobOverrideStmt           *sql.Stmt
+	deleteJobRunsBeforeStmt         *sql.Stmt
+	finishJobRunStmt                *sql.Stmt
+	getArticleStmt                  *sql.Stmt
+	getArticleByURLStmt             *sql.Stmt
+	getJobRunStmt                   *sql.Stmt
+	getLastScheduledJobRunStartStmt *sql.Stmt
+	listArticlesBySiteStmt          *sql.Stmt
+	listArticlesBySiteAfterStmt     *sql.Stmt
+	listArticlesBySiteBeforeStmt    *sql.Stmt
+	listArticlesBySymbolStmt        *sql.Stmt
+	listArticlesBySymbolAfterStmt   *sql.Stmt
+	listArticlesBySymbolBeforeStmt  *sql.Stmt
+	listDownstreamJobRunsStmt       *sql.Stmt
+	listJobOverridesStmt            *sql.Stmt
+	listJobRunsStmt                 *sql.Stmt
+	searchArticlesStmt              *sql.Stmt
+	upsertArticlesStmt              *sql.Stmt
+	upsertJobOverrideStmt           *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                     tx,
-		tx:                     tx,
-		createArticleStmt:      q.createArticleStmt,
-		getArticleStmt:         q.getArticleStmt,
-		getArticleBySiteStmt:   q.getArticleBySiteStmt,
-		getArticleBySymbolStmt: q.getArticleBySymbolStmt,
-		getArticleByURLStmt:    q.getArticleByURLStmt,
+		db:                              tx,
+		tx:                              tx,
+		abandonJobRunsStmt:              q.abandonJobRunsStmt,
+		countArticlesBySiteStmt:         q.countArticlesBySiteStmt,
+		countArticlesBySymbolStmt:       q.countArticlesBySymbolStmt,
+		countJobRunsStmt:                q.countJobRunsStmt,
+		countSearchArticlesStmt:         q.countSearchArticlesStmt,
+		createArticleStmt:               q.createArticleStmt,
+		createJobRunStmt:                q.createJobRunStmt,
+		deleteJobOverrideStmt:           q.deleteJobOverrideStmt,
+		deleteJobRunsBeforeStmt:         q.deleteJobRunsBeforeStmt,
+		finishJobRunStmt:                q.finishJobRunStmt,
+		getArticleStmt:                  q.getArticleStmt,
+		getArticleByURLStmt:             q.getArticleByURLStmt,
+		getJobRunStmt:                   q.getJobRunStmt,
+		getLastScheduledJobRunStartStmt: q.getLastScheduledJobRunStartStmt,
+		listArticlesBySiteStmt:          q.listArticlesBySiteStmt,
+		listArticlesBySiteAfterStmt:     q.listArticlesBySiteAfterStmt,
+		listArticlesBySiteBeforeStmt:    q.listArticlesBySiteBeforeStmt,
+		listArticlesBySymbolStmt:        q.listArticlesBySymbolStmt,
+		listArticlesBySymbolAfterStmt:   q.listArticlesBySymbolAfterStmt,
+		listArticlesBySymbolBeforeStmt:  q.listArticlesBySymbolBeforeStmt,
+		listDownstreamJobRunsStmt:       q.listDownstreamJobRunsStmt,
+		listJobOverridesStmt:            q.listJobOverridesStmt,
+		listJobRunsStmt:                 q.listJobRunsStmt,
+		searchArticlesStmt:              q.searchArticlesStmt,
+		upsertArticlesStmt:              q.upsertArticlesStmt,
+		upsertJobOverrideStmt:           q.upsertJobOverrideStmt,
 	}
 }
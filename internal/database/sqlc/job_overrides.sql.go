@@ -0,0 +1,77 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: job_overrides.sql
+
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+)
+
+const deleteJobOverride = `-- name: DeleteJobOverride :exec
+DELETE FROM job_overrides
+WHERE job_name = $1
+`
+
+func (q *Queries) DeleteJobOverride(ctx context.Context, jobName string) error {
+	_, err := q.exec(ctx, q.deleteJobOverrideStmt, deleteJobOverride, jobName)
+	return err
+}
+
+const listJobOverrides = `-- name: ListJobOverrides :many
+SELECT job_name, paused, cron_expr, updated_at FROM job_overrides
+ORDER BY job_name
+`
+
+func (q *Queries) ListJobOverrides(ctx context.Context) ([]JobOverride, error) {
+	rows, err := q.query(ctx, q.listJobOverridesStmt, listJobOverrides)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []JobOverride{}
+	for rows.Next() {
+		var i JobOverride
+		if err := rows.Scan(
+			&i.JobName,
+			&i.Paused,
+			&i.CronExpr,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertJobOverride = `-- name: UpsertJobOverride :exec
+INSERT INTO job_overrides (
+    job_name, paused, cron_expr, updated_at
+) VALUES (
+             $1, $2, $3, NOW()
+         )
+ON CONFLICT (job_name) DO UPDATE
+SET paused = EXCLUDED.paused,
+    cron_expr = EXCLUDED.cron_expr,
+    updated_at = EXCLUDED.updated_at
+`
+
+type UpsertJobOverrideParams struct {
+	JobName  string         `json:"job_name"`
+	Paused   bool           `json:"paused"`
+	CronExpr sql.NullString `json:"cron_expr"`
+}
+
+func (q *Queries) UpsertJobOverride(ctx context.Context, arg UpsertJobOverrideParams) error {
+	_, err := q.exec(ctx, q.upsertJobOverrideStmt, upsertJobOverride, arg.JobName, arg.Paused, arg.CronExpr)
+	return err
+}
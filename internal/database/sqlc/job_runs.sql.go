@@ -0,0 +1,323 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: job_runs.sql
+
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const abandonJobRuns = `-- name: AbandonJobRuns :execrows
+UPDATE job_runs
+SET status = 'abandoned',
+    finished_at = $1,
+    summary = 'instance stopped before the run finished'
+WHERE job_name = $2 AND status = 'running' AND started_at < $3
+`
+
+type AbandonJobRunsParams struct {
+	FinishedAt    sql.NullTime `json:"finished_at"`
+	JobName       string       `json:"job_name"`
+	StartedBefore time.Time    `json:"started_before"`
+}
+
+func (q *Queries) AbandonJobRuns(ctx context.Context, arg AbandonJobRunsParams) (int64, error) {
+	result, err := q.exec(ctx, q.abandonJobRunsStmt, abandonJobRuns, arg.FinishedAt, arg.JobName, arg.StartedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countJobRuns = `-- name: CountJobRuns :one
+SELECT COUNT(*) FROM job_runs
+WHERE ($1::text IS NULL OR job_name = $1)
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::text IS NULL OR trigger = $3)
+  AND ($4::text IS NULL OR instance_id = $4)
+  AND ($5::timestamptz IS NULL OR started_at >= $5)
+  AND ($6::timestamptz IS NULL OR started_at < $6)
+`
+
+type CountJobRunsParams struct {
+	JobName    sql.NullString `json:"job_name"`
+	Status     sql.NullString `json:"status"`
+	Trigger    sql.NullString `json:"trigger"`
+	InstanceID sql.NullString `json:"instance_id"`
+	Since      sql.NullTime   `json:"since"`
+	Until      sql.NullTime   `json:"until"`
+}
+
+func (q *Queries) CountJobRuns(ctx context.Context, arg CountJobRunsParams) (int64, error) {
+	row := q.queryRow(ctx, q.countJobRunsStmt, countJobRuns,
+		arg.JobName,
+		arg.Status,
+		arg.Trigger,
+		arg.InstanceID,
+		arg.Since,
+		arg.Until,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createJobRun = `-- name: CreateJobRun :one
+INSERT INTO job_runs (
+    job_name, trigger, status, started_at, instance_id, parent_run_id
+) VALUES (
+             $1, $2, $3, $4, $5, $6
+         )
+RETURNING id
+`
+
+type CreateJobRunParams struct {
+	JobName     string        `json:"job_name"`
+	Trigger     string        `json:"trigger"`
+	Status      string        `json:"status"`
+	StartedAt   time.Time     `json:"started_at"`
+	InstanceID  string        `json:"instance_id"`
+	ParentRunID sql.NullInt64 `json:"parent_run_id"`
+}
+
+func (q *Queries) CreateJobRun(ctx context.Context, arg CreateJobRunParams) (int64, error) {
+	row := q.queryRow(ctx, q.createJobRunStmt, createJobRun,
+		arg.JobName,
+		arg.Trigger,
+		arg.Status,
+		arg.StartedAt,
+		arg.InstanceID,
+		arg.ParentRunID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteJobRunsBefore = `-- name: DeleteJobRunsBefore :execrows
+DELETE FROM job_runs
+WHERE started_at < $1
+`
+
+func (q *Queries) DeleteJobRunsBefore(ctx context.Context, startedAt time.Time) (int64, error) {
+	result, err := q.exec(ctx, q.deleteJobRunsBeforeStmt, deleteJobRunsBefore, startedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const finishJobRun = `-- name: FinishJobRun :exec
+UPDATE job_runs
+SET status = $2,
+    finished_at = $3,
+    error = $4,
+    items_processed = $5,
+    items_failed = $6,
+    attempts = $7,
+    summary = $8
+WHERE id = $1
+`
+
+type FinishJobRunParams struct {
+	ID             int64          `json:"id"`
+	Status         string         `json:"status"`
+	FinishedAt     sql.NullTime   `json:"finished_at"`
+	Error          sql.NullString `json:"error"`
+	ItemsProcessed int64          `json:"items_processed"`
+	ItemsFailed    int64          `json:"items_failed"`
+	Attempts       int32          `json:"attempts"`
+	Summary        sql.NullString `json:"summary"`
+}
+
+func (q *Queries) FinishJobRun(ctx context.Context, arg FinishJobRunParams) error {
+	_, err := q.exec(ctx, q.finishJobRunStmt, finishJobRun,
+		arg.ID,
+		arg.Status,
+		arg.FinishedAt,
+		arg.Error,
+		arg.ItemsProcessed,
+		arg.ItemsFailed,
+		arg.Attempts,
+		arg.Summary,
+	)
+	return err
+}
+
+const getJobRun = `-- name: GetJobRun :one
+SELECT id, job_name, trigger, status, started_at, finished_at, error, items_processed, items_failed, attempts, summary, instance_id, parent_run_id FROM job_runs
+WHERE id = $1
+`
+
+func (q *Queries) GetJobRun(ctx context.Context, id int64) (JobRun, error) {
+	row := q.queryRow(ctx, q.getJobRunStmt, getJobRun, id)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.JobName,
+		&i.Trigger,
+		&i.Status,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.Error,
+		&i.ItemsProcessed,
+		&i.ItemsFailed,
+		&i.Attempts,
+		&i.Summary,
+		&i.InstanceID,
+		&i.ParentRunID,
+	)
+	return i, err
+}
+
+const getLastScheduledJobRunStart = `-- name: GetLastScheduledJobRunStart :one
+SELECT started_at FROM job_runs
+WHERE job_name = $1 AND trigger IN ('scheduled', 'catch-up')
+ORDER BY started_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastScheduledJobRunStart(ctx context.Context, jobName string) (time.Time, error) {
+	row := q.queryRow(ctx, q.getLastScheduledJobRunStartStmt, getLastScheduledJobRunStart, jobName)
+	var started_at time.Time
+	err := row.Scan(&started_at)
+	return started_at, err
+}
+
+const listDownstreamJobRuns = `-- name: ListDownstreamJobRuns :many
+WITH RECURSIVE downstream AS (
+    SELECT id, job_name, trigger, status, started_at, finished_at, error, items_processed, items_failed, attempts, summary, instance_id, parent_run_id FROM job_runs
+    WHERE job_runs.parent_run_id = $1
+    UNION ALL
+    SELECT child.id, child.job_name, child.trigger, child.status, child.started_at, child.finished_at, child.error, child.items_processed, child.items_failed, child.attempts, child.summary, child.instance_id, child.parent_run_id FROM job_runs child
+    JOIN downstream ON child.parent_run_id = downstream.id
+)
+SELECT id, job_name, trigger, status, started_at, finished_at, error, items_processed, items_failed, attempts, summary, instance_id, parent_run_id FROM downstream
+ORDER BY started_at, id
+`
+
+type ListDownstreamJobRunsRow struct {
+	ID             int64          `json:"id"`
+	JobName        string         `json:"job_name"`
+	Trigger        string         `json:"trigger"`
+	Status         string         `json:"status"`
+	StartedAt      time.Time      `json:"started_at"`
+	FinishedAt     sql.NullTime   `json:"finished_at"`
+	Error          sql.NullString `json:"error"`
+	ItemsProcessed int64          `json:"items_processed"`
+	ItemsFailed    int64          `json:"items_failed"`
+	Attempts       int32          `json:"attempts"`
+	Summary        sql.NullString `json:"summary"`
+	InstanceID     string         `json:"instance_id"`
+	ParentRunID    sql.NullInt64  `json:"parent_run_id"`
+}
+
+func (q *Queries) ListDownstreamJobRuns(ctx context.Context, parentRunID sql.NullInt64) ([]ListDownstreamJobRunsRow, error) {
+	rows, err := q.query(ctx, q.listDownstreamJobRunsStmt, listDownstreamJobRuns, parentRunID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDownstreamJobRunsRow{}
+	for rows.Next() {
+		var i ListDownstreamJobRunsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobName,
+			&i.Trigger,
+			&i.Status,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Error,
+			&i.ItemsProcessed,
+			&i.ItemsFailed,
+			&i.Attempts,
+			&i.Summary,
+			&i.InstanceID,
+			&i.ParentRunID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobRuns = `-- name: ListJobRuns :many
+SELECT id, job_name, trigger, status, started_at, finished_at, error, items_processed, items_failed, attempts, summary, instance_id, parent_run_id FROM job_runs
+WHERE ($1::text IS NULL OR job_name = $1)
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::text IS NULL OR trigger = $3)
+  AND ($4::text IS NULL OR instance_id = $4)
+  AND ($5::timestamptz IS NULL OR started_at >= $5)
+  AND ($6::timestamptz IS NULL OR started_at < $6)
+ORDER BY started_at DESC, id DESC
+LIMIT $8 OFFSET $7
+`
+
+type ListJobRunsParams struct {
+	JobName    sql.NullString `json:"job_name"`
+	Status     sql.NullString `json:"status"`
+	Trigger    sql.NullString `json:"trigger"`
+	InstanceID sql.NullString `json:"instance_id"`
+	Since      sql.NullTime   `json:"since"`
+	Until      sql.NullTime   `json:"until"`
+	Offset     int32          `json:"offset"`
+	Limit      int32          `json:"limit"`
+}
+
+func (q *Queries) ListJobRuns(ctx context.Context, arg ListJobRunsParams) ([]JobRun, error) {
+	rows, err := q.query(ctx, q.listJobRunsStmt, listJobRuns,
+		arg.JobName,
+		arg.Status,
+		arg.Trigger,
+		arg.InstanceID,
+		arg.Since,
+		arg.Until,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []JobRun{}
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobName,
+			&i.Trigger,
+			&i.Status,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Error,
+			&i.ItemsProcessed,
+			&i.ItemsFailed,
+			&i.Attempts,
+			&i.Summary,
+			&i.InstanceID,
+			&i.ParentRunID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
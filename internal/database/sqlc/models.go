@@ -10,12 +10,37 @@ import (
 )
 
 type Article struct {
-	ID        int32          `json:"id"`
-	Title     string         `json:"title"`
-	Url       string         `json:"url"`
-	Text      sql.NullString `json:"text"`
-	SiteName  string         `json:"site_name"`
-	ScrapedAt time.Time      `json:"scraped_at"`
-	CreatedAt time.Time      `json:"created_at"`
-	Symbol    string         `json:"symbol"`
+	ID           int32          `json:"id"`
+	Title        string         `json:"title"`
+	Url          string         `json:"url"`
+	Text         sql.NullString `json:"text"`
+	SiteName     string         `json:"site_name"`
+	ScrapedAt    time.Time      `json:"scraped_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Symbol       string         `json:"symbol"`
+	SearchVector interface{}    `json:"search_vector"`
+	Tags         []string       `json:"tags"`
+}
+
+type JobOverride struct {
+	JobName   string         `json:"job_name"`
+	Paused    bool           `json:"paused"`
+	CronExpr  sql.NullString `json:"cron_expr"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type JobRun struct {
+	ID             int64          `json:"id"`
+	JobName        string         `json:"job_name"`
+	Trigger        string         `json:"trigger"`
+	Status         string         `json:"status"`
+	StartedAt      time.Time      `json:"started_at"`
+	FinishedAt     sql.NullTime   `json:"finished_at"`
+	Error          sql.NullString `json:"error"`
+	ItemsProcessed int64          `json:"items_processed"`
+	ItemsFailed    int64          `json:"items_failed"`
+	Attempts       int32          `json:"attempts"`
+	Summary        sql.NullString `json:"summary"`
+	InstanceID     string         `json:"instance_id"`
+	ParentRunID    sql.NullInt64  `json:"parent_run_id"`
 }
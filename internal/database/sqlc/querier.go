@@ -6,14 +6,41 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
-	CreateArticle(ctx context.Context, arg CreateArticleParams) (Article, error)
-	GetArticle(ctx context.Context, id int32) (Article, error)
-	GetArticleBySite(ctx context.Context, siteName string) ([]Article, error)
-	GetArticleBySymbol(ctx context.Context, symbol string) ([]Article, error)
-	GetArticleByURL(ctx context.Context, url string) (Article, error)
+	AbandonJobRuns(ctx context.Context, arg AbandonJobRunsParams) (int64, error)
+	CountArticlesBySite(ctx context.Context, siteName string) (int64, error)
+	CountArticlesBySymbol(ctx context.Context, symbol string) (int64, error)
+	CountJobRuns(ctx context.Context, arg CountJobRunsParams) (int64, error)
+	CountSearchArticles(ctx context.Context, arg CountSearchArticlesParams) (int64, error)
+	CreateArticle(ctx context.Context, arg CreateArticleParams) (CreateArticleRow, error)
+	CreateJobRun(ctx context.Context, arg CreateJobRunParams) (int64, error)
+	DeleteJobOverride(ctx context.Context, jobName string) error
+	DeleteJobRunsBefore(ctx context.Context, startedAt time.Time) (int64, error)
+	FinishJobRun(ctx context.Context, arg FinishJobRunParams) error
+	GetArticle(ctx context.Context, id int32) (GetArticleRow, error)
+	GetArticleByURL(ctx context.Context, url string) (GetArticleByURLRow, error)
+	GetJobRun(ctx context.Context, id int64) (JobRun, error)
+	GetLastScheduledJobRunStart(ctx context.Context, jobName string) (time.Time, error)
+	ListArticlesBySite(ctx context.Context, arg ListArticlesBySiteParams) ([]ListArticlesBySiteRow, error)
+	ListArticlesBySiteAfter(ctx context.Context, arg ListArticlesBySiteAfterParams) ([]ListArticlesBySiteAfterRow, error)
+	ListArticlesBySiteBefore(ctx context.Context, arg ListArticlesBySiteBeforeParams) ([]ListArticlesBySiteBeforeRow, error)
+	ListArticlesBySymbol(ctx context.Context, arg ListArticlesBySymbolParams) ([]ListArticlesBySymbolRow, error)
+	ListArticlesBySymbolAfter(ctx context.Context, arg ListArticlesBySymbolAfterParams) ([]ListArticlesBySymbolAfterRow, error)
+	ListArticlesBySymbolBefore(ctx context.Context, arg ListArticlesBySymbolBeforeParams) ([]ListArticlesBySymbolBeforeRow, error)
+	ListDownstreamJobRuns(ctx context.Context, parentRunID sql.NullInt64) ([]ListDownstreamJobRunsRow, error)
+	ListJobOverrides(ctx context.Context) ([]JobOverride, error)
+	ListJobRuns(ctx context.Context, arg ListJobRunsParams) ([]JobRun, error)
+	// The text is HTML-escaped first, so the <mark> tags are the only markup in snippets
+	SearchArticles(ctx context.Context, arg SearchArticlesParams) ([]SearchArticlesRow, error)
+	// Takes one array per column. Each article's tags are joined with commas, as
+	// Postgres arrays cannot hold arrays of different lengths.
+	// xmax is zero only for freshly inserted tuples, which tells inserts and updates apart
+	UpsertArticles(ctx context.Context, arg UpsertArticlesParams) ([]UpsertArticlesRow, error)
+	UpsertJobOverride(ctx context.Context, arg UpsertJobOverrideParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -9,21 +9,79 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+const countArticlesBySite = `-- name: CountArticlesBySite :one
+SELECT COUNT(*) FROM articles
+WHERE site_name = $1
+`
+
+func (q *Queries) CountArticlesBySite(ctx context.Context, siteName string) (int64, error) {
+	row := q.queryRow(ctx, q.countArticlesBySiteStmt, countArticlesBySite, siteName)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countArticlesBySymbol = `-- name: CountArticlesBySymbol :one
+SELECT COUNT(*) FROM articles
+WHERE symbol = $1
+`
+
+func (q *Queries) CountArticlesBySymbol(ctx context.Context, symbol string) (int64, error) {
+	row := q.queryRow(ctx, q.countArticlesBySymbolStmt, countArticlesBySymbol, symbol)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchArticles = `-- name: CountSearchArticles :one
+SELECT COUNT(*)
+FROM articles
+WHERE search_vector @@ websearch_to_tsquery('english', $1::text)
+  AND ($2::text IS NULL OR symbol = $2)
+  AND ($3::text IS NULL OR site_name = $3)
+  AND ($4::timestamptz IS NULL OR scraped_at >= $4)
+  AND ($5::timestamptz IS NULL OR scraped_at < $5)
+`
+
+type CountSearchArticlesParams struct {
+	Query    string         `json:"query"`
+	Symbol   sql.NullString `json:"symbol"`
+	SiteName sql.NullString `json:"site_name"`
+	Since    sql.NullTime   `json:"since"`
+	Until    sql.NullTime   `json:"until"`
+}
+
+func (q *Queries) CountSearchArticles(ctx context.Context, arg CountSearchArticlesParams) (int64, error) {
+	row := q.queryRow(ctx, q.countSearchArticlesStmt, countSearchArticles,
+		arg.Query,
+		arg.Symbol,
+		arg.SiteName,
+		arg.Since,
+		arg.Until,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createArticle = `-- name: CreateArticle :one
 INSERT INTO articles (
-    title, url, text, site_name, scraped_at, symbol
+    title, url, text, site_name, scraped_at, symbol, tags
 ) VALUES (
-             $1, $2, $3, $4, $5, $6
+             $1, $2, $3, $4, $5, $6, $7
          ) ON CONFLICT (url)
     DO UPDATE SET
                   title = EXCLUDED.title,
                   text = EXCLUDED.text,
                   site_name = EXCLUDED.site_name,
                   scraped_at = EXCLUDED.scraped_at,
-                  symbol = EXCLUDED.symbol
-RETURNING id, title, url, text, site_name, scraped_at, created_at, symbol
+                  symbol = EXCLUDED.symbol,
+                  tags = EXCLUDED.tags
+RETURNING id, title, url, text, site_name, scraped_at, created_at, symbol, tags
 `
 
 type CreateArticleParams struct {
@@ -33,9 +91,22 @@ type CreateArticleParams struct {
 	SiteName  string         `json:"site_name"`
 	ScrapedAt time.Time      `json:"scraped_at"`
 	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+type CreateArticleRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
 }
 
-func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (Article, error) {
+func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (CreateArticleRow, error) {
 	row := q.queryRow(ctx, q.createArticleStmt, createArticle,
 		arg.Title,
 		arg.Url,
@@ -43,8 +114,9 @@ func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (A
 		arg.SiteName,
 		arg.ScrapedAt,
 		arg.Symbol,
+		pq.Array(arg.Tags),
 	)
-	var i Article
+	var i CreateArticleRow
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
@@ -54,18 +126,65 @@ func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (A
 		&i.ScrapedAt,
 		&i.CreatedAt,
 		&i.Symbol,
+		pq.Array(&i.Tags),
 	)
 	return i, err
 }
 
 const getArticle = `-- name: GetArticle :one
-SELECT id, title, url, text, site_name, scraped_at, created_at, symbol FROM articles
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
 WHERE id = $1
 `
 
-func (q *Queries) GetArticle(ctx context.Context, id int32) (Article, error) {
+type GetArticleRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+func (q *Queries) GetArticle(ctx context.Context, id int32) (GetArticleRow, error) {
 	row := q.queryRow(ctx, q.getArticleStmt, getArticle, id)
-	var i Article
+	var i GetArticleRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Url,
+		&i.Text,
+		&i.SiteName,
+		&i.ScrapedAt,
+		&i.CreatedAt,
+		&i.Symbol,
+		pq.Array(&i.Tags),
+	)
+	return i, err
+}
+
+const getArticleByURL = `-- name: GetArticleByURL :one
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE url = $1
+`
+
+type GetArticleByURLRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+func (q *Queries) GetArticleByURL(ctx context.Context, url string) (GetArticleByURLRow, error) {
+	row := q.queryRow(ctx, q.getArticleByURLStmt, getArticleByURL, url)
+	var i GetArticleByURLRow
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
@@ -75,24 +194,44 @@ func (q *Queries) GetArticle(ctx context.Context, id int32) (Article, error) {
 		&i.ScrapedAt,
 		&i.CreatedAt,
 		&i.Symbol,
+		pq.Array(&i.Tags),
 	)
 	return i, err
 }
 
-const getArticleBySite = `-- name: GetArticleBySite :many
-SELECT id, title, url, text, site_name, scraped_at, created_at, symbol FROM articles
+const listArticlesBySite = `-- name: ListArticlesBySite :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
 WHERE site_name = $1
+ORDER BY scraped_at DESC, id DESC
+LIMIT $2
 `
 
-func (q *Queries) GetArticleBySite(ctx context.Context, siteName string) ([]Article, error) {
-	rows, err := q.query(ctx, q.getArticleBySiteStmt, getArticleBySite, siteName)
+type ListArticlesBySiteParams struct {
+	SiteName string `json:"site_name"`
+	Limit    int32  `json:"limit"`
+}
+
+type ListArticlesBySiteRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+func (q *Queries) ListArticlesBySite(ctx context.Context, arg ListArticlesBySiteParams) ([]ListArticlesBySiteRow, error) {
+	rows, err := q.query(ctx, q.listArticlesBySiteStmt, listArticlesBySite, arg.SiteName, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Article{}
+	items := []ListArticlesBySiteRow{}
 	for rows.Next() {
-		var i Article
+		var i ListArticlesBySiteRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
@@ -102,6 +241,7 @@ func (q *Queries) GetArticleBySite(ctx context.Context, siteName string) ([]Arti
 			&i.ScrapedAt,
 			&i.CreatedAt,
 			&i.Symbol,
+			pq.Array(&i.Tags),
 		); err != nil {
 			return nil, err
 		}
@@ -116,20 +256,169 @@ func (q *Queries) GetArticleBySite(ctx context.Context, siteName string) ([]Arti
 	return items, nil
 }
 
-const getArticleBySymbol = `-- name: GetArticleBySymbol :many
-SELECT id, title, url, text, site_name, scraped_at, created_at, symbol FROM articles
+const listArticlesBySiteAfter = `-- name: ListArticlesBySiteAfter :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE site_name = $1
+  AND (scraped_at, id) > ($2::timestamptz, $3::int)
+ORDER BY scraped_at, id
+LIMIT $4
+`
+
+type ListArticlesBySiteAfterParams struct {
+	SiteName  string    `json:"site_name"`
+	ScrapedAt time.Time `json:"scraped_at"`
+	ID        int32     `json:"id"`
+	Limit     int32     `json:"limit"`
+}
+
+type ListArticlesBySiteAfterRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+func (q *Queries) ListArticlesBySiteAfter(ctx context.Context, arg ListArticlesBySiteAfterParams) ([]ListArticlesBySiteAfterRow, error) {
+	rows, err := q.query(ctx, q.listArticlesBySiteAfterStmt, listArticlesBySiteAfter,
+		arg.SiteName,
+		arg.ScrapedAt,
+		arg.ID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListArticlesBySiteAfterRow{}
+	for rows.Next() {
+		var i ListArticlesBySiteAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Text,
+			&i.SiteName,
+			&i.ScrapedAt,
+			&i.CreatedAt,
+			&i.Symbol,
+			pq.Array(&i.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticlesBySiteBefore = `-- name: ListArticlesBySiteBefore :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE site_name = $1
+  AND (scraped_at, id) < ($2::timestamptz, $3::int)
+ORDER BY scraped_at DESC, id DESC
+LIMIT $4
+`
+
+type ListArticlesBySiteBeforeParams struct {
+	SiteName  string    `json:"site_name"`
+	ScrapedAt time.Time `json:"scraped_at"`
+	ID        int32     `json:"id"`
+	Limit     int32     `json:"limit"`
+}
+
+type ListArticlesBySiteBeforeRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+func (q *Queries) ListArticlesBySiteBefore(ctx context.Context, arg ListArticlesBySiteBeforeParams) ([]ListArticlesBySiteBeforeRow, error) {
+	rows, err := q.query(ctx, q.listArticlesBySiteBeforeStmt, listArticlesBySiteBefore,
+		arg.SiteName,
+		arg.ScrapedAt,
+		arg.ID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListArticlesBySiteBeforeRow{}
+	for rows.Next() {
+		var i ListArticlesBySiteBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Text,
+			&i.SiteName,
+			&i.ScrapedAt,
+			&i.CreatedAt,
+			&i.Symbol,
+			pq.Array(&i.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticlesBySymbol = `-- name: ListArticlesBySymbol :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
 WHERE symbol = $1
+ORDER BY scraped_at DESC, id DESC
+LIMIT $2
 `
 
-func (q *Queries) GetArticleBySymbol(ctx context.Context, symbol string) ([]Article, error) {
-	rows, err := q.query(ctx, q.getArticleBySymbolStmt, getArticleBySymbol, symbol)
+type ListArticlesBySymbolParams struct {
+	Symbol string `json:"symbol"`
+	Limit  int32  `json:"limit"`
+}
+
+type ListArticlesBySymbolRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+func (q *Queries) ListArticlesBySymbol(ctx context.Context, arg ListArticlesBySymbolParams) ([]ListArticlesBySymbolRow, error) {
+	rows, err := q.query(ctx, q.listArticlesBySymbolStmt, listArticlesBySymbol, arg.Symbol, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Article{}
+	items := []ListArticlesBySymbolRow{}
 	for rows.Next() {
-		var i Article
+		var i ListArticlesBySymbolRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
@@ -139,6 +428,7 @@ func (q *Queries) GetArticleBySymbol(ctx context.Context, symbol string) ([]Arti
 			&i.ScrapedAt,
 			&i.CreatedAt,
 			&i.Symbol,
+			pq.Array(&i.Tags),
 		); err != nil {
 			return nil, err
 		}
@@ -153,23 +443,292 @@ func (q *Queries) GetArticleBySymbol(ctx context.Context, symbol string) ([]Arti
 	return items, nil
 }
 
-const getArticleByURL = `-- name: GetArticleByURL :one
-SELECT id, title, url, text, site_name, scraped_at, created_at, symbol FROM articles
-WHERE url = $1
+const listArticlesBySymbolAfter = `-- name: ListArticlesBySymbolAfter :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE symbol = $1
+  AND (scraped_at, id) > ($2::timestamptz, $3::int)
+ORDER BY scraped_at, id
+LIMIT $4
 `
 
-func (q *Queries) GetArticleByURL(ctx context.Context, url string) (Article, error) {
-	row := q.queryRow(ctx, q.getArticleByURLStmt, getArticleByURL, url)
-	var i Article
-	err := row.Scan(
-		&i.ID,
-		&i.Title,
-		&i.Url,
-		&i.Text,
-		&i.SiteName,
-		&i.ScrapedAt,
-		&i.CreatedAt,
-		&i.Symbol,
+type ListArticlesBySymbolAfterParams struct {
+	Symbol    string    `json:"symbol"`
+	ScrapedAt time.Time `json:"scraped_at"`
+	ID        int32     `json:"id"`
+	Limit     int32     `json:"limit"`
+}
+
+type ListArticlesBySymbolAfterRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+func (q *Queries) ListArticlesBySymbolAfter(ctx context.Context, arg ListArticlesBySymbolAfterParams) ([]ListArticlesBySymbolAfterRow, error) {
+	rows, err := q.query(ctx, q.listArticlesBySymbolAfterStmt, listArticlesBySymbolAfter,
+		arg.Symbol,
+		arg.ScrapedAt,
+		arg.ID,
+		arg.Limit,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListArticlesBySymbolAfterRow{}
+	for rows.Next() {
+		var i ListArticlesBySymbolAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Text,
+			&i.SiteName,
+			&i.ScrapedAt,
+			&i.CreatedAt,
+			&i.Symbol,
+			pq.Array(&i.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArticlesBySymbolBefore = `-- name: ListArticlesBySymbolBefore :many
+SELECT id, title, url, text, site_name, scraped_at, created_at, symbol, tags FROM articles
+WHERE symbol = $1
+  AND (scraped_at, id) < ($2::timestamptz, $3::int)
+ORDER BY scraped_at DESC, id DESC
+LIMIT $4
+`
+
+type ListArticlesBySymbolBeforeParams struct {
+	Symbol    string    `json:"symbol"`
+	ScrapedAt time.Time `json:"scraped_at"`
+	ID        int32     `json:"id"`
+	Limit     int32     `json:"limit"`
+}
+
+type ListArticlesBySymbolBeforeRow struct {
+	ID        int32          `json:"id"`
+	Title     string         `json:"title"`
+	Url       string         `json:"url"`
+	Text      sql.NullString `json:"text"`
+	SiteName  string         `json:"site_name"`
+	ScrapedAt time.Time      `json:"scraped_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	Symbol    string         `json:"symbol"`
+	Tags      []string       `json:"tags"`
+}
+
+func (q *Queries) ListArticlesBySymbolBefore(ctx context.Context, arg ListArticlesBySymbolBeforeParams) ([]ListArticlesBySymbolBeforeRow, error) {
+	rows, err := q.query(ctx, q.listArticlesBySymbolBeforeStmt, listArticlesBySymbolBefore,
+		arg.Symbol,
+		arg.ScrapedAt,
+		arg.ID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListArticlesBySymbolBeforeRow{}
+	for rows.Next() {
+		var i ListArticlesBySymbolBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Text,
+			&i.SiteName,
+			&i.ScrapedAt,
+			&i.CreatedAt,
+			&i.Symbol,
+			pq.Array(&i.Tags),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchArticles = `-- name: SearchArticles :many
+WITH ranked AS (
+    SELECT articles.id, articles.title, articles.url, articles.text, articles.site_name, articles.symbol,
+           articles.tags, articles.scraped_at, articles.created_at, ts_rank(articles.search_vector, query) AS rank, query
+    FROM articles, websearch_to_tsquery('english', $1::text) query
+    WHERE articles.search_vector @@ query
+      AND ($2::text IS NULL OR articles.symbol = $2)
+      AND ($3::text IS NULL OR articles.site_name = $3)
+      AND ($4::timestamptz IS NULL OR articles.scraped_at >= $4)
+      AND ($5::timestamptz IS NULL OR articles.scraped_at < $5)
+    ORDER BY rank DESC, articles.scraped_at DESC, articles.id DESC
+    LIMIT $7 OFFSET $6
+)
+SELECT id, title, url, site_name, symbol, tags, scraped_at, created_at, rank::real AS rank,
+       ts_headline('english',
+                   replace(replace(replace(coalesce(text, title), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+                   query,
+                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')::text AS snippet
+FROM ranked
+ORDER BY rank DESC, scraped_at DESC, id DESC
+`
+
+type SearchArticlesParams struct {
+	Query    string         `json:"query"`
+	Symbol   sql.NullString `json:"symbol"`
+	SiteName sql.NullString `json:"site_name"`
+	Since    sql.NullTime   `json:"since"`
+	Until    sql.NullTime   `json:"until"`
+	Offset   int32          `json:"offset"`
+	Limit    int32          `json:"limit"`
+}
+
+type SearchArticlesRow struct {
+	ID        int32     `json:"id"`
+	Title     string    `json:"title"`
+	Url       string    `json:"url"`
+	SiteName  string    `json:"site_name"`
+	Symbol    string    `json:"symbol"`
+	Tags      []string  `json:"tags"`
+	ScrapedAt time.Time `json:"scraped_at"`
+	CreatedAt time.Time `json:"created_at"`
+	Rank      float32   `json:"rank"`
+	Snippet   string    `json:"snippet"`
+}
+
+// The text is HTML-escaped first, so the <mark> tags are the only markup in snippets
+func (q *Queries) SearchArticles(ctx context.Context, arg SearchArticlesParams) ([]SearchArticlesRow, error) {
+	rows, err := q.query(ctx, q.searchArticlesStmt, searchArticles,
+		arg.Query,
+		arg.Symbol,
+		arg.SiteName,
+		arg.Since,
+		arg.Until,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchArticlesRow{}
+	for rows.Next() {
+		var i SearchArticlesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.SiteName,
+			&i.Symbol,
+			pq.Array(&i.Tags),
+			&i.ScrapedAt,
+			&i.CreatedAt,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertArticles = `-- name: UpsertArticles :many
+INSERT INTO articles (title, url, text, site_name, scraped_at, symbol, tags)
+SELECT unnest($1::text[]),
+       unnest($2::text[]),
+       NULLIF(unnest($3::text[]), ''),
+       unnest($4::text[]),
+       unnest($5::timestamptz[]),
+       unnest($6::text[]),
+       string_to_array(unnest($7::text[]), ',')
+ON CONFLICT (url)
+    DO UPDATE SET
+                  title = EXCLUDED.title,
+                  text = EXCLUDED.text,
+                  site_name = EXCLUDED.site_name,
+                  scraped_at = EXCLUDED.scraped_at,
+                  symbol = EXCLUDED.symbol,
+                  tags = EXCLUDED.tags
+    WHERE (articles.title, articles.text, articles.site_name, articles.symbol, articles.tags)
+              IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.text, EXCLUDED.site_name, EXCLUDED.symbol, EXCLUDED.tags)
+RETURNING id, url, (xmax = 0)::boolean AS inserted
+`
+
+type UpsertArticlesParams struct {
+	Titles     []string    `json:"titles"`
+	Urls       []string    `json:"urls"`
+	Texts      []string    `json:"texts"`
+	SiteNames  []string    `json:"site_names"`
+	ScrapedAts []time.Time `json:"scraped_ats"`
+	Symbols    []string    `json:"symbols"`
+	Tags       []string    `json:"tags"`
+}
+
+type UpsertArticlesRow struct {
+	ID       int32  `json:"id"`
+	Url      string `json:"url"`
+	Inserted bool   `json:"inserted"`
+}
+
+// Takes one array per column. Each article's tags are joined with commas, as
+// Postgres arrays cannot hold arrays of different lengths.
+// xmax is zero only for freshly inserted tuples, which tells inserts and updates apart
+func (q *Queries) UpsertArticles(ctx context.Context, arg UpsertArticlesParams) ([]UpsertArticlesRow, error) {
+	rows, err := q.query(ctx, q.upsertArticlesStmt, upsertArticles,
+		pq.Array(arg.Titles),
+		pq.Array(arg.Urls),
+		pq.Array(arg.Texts),
+		pq.Array(arg.SiteNames),
+		pq.Array(arg.ScrapedAts),
+		pq.Array(arg.Symbols),
+		pq.Array(arg.Tags),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UpsertArticlesRow{}
+	for rows.Next() {
+		var i UpsertArticlesRow
+		if err := rows.Scan(&i.ID, &i.Url, &i.Inserted); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
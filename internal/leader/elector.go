@@ -0,0 +1,197 @@
+package leader
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+// leaseMarginDivisor sets the safety margin as a share of the lease TTL: an
+// instance steps down that long before its lease would expire, so it never
+// acts as leader while another instance may already have taken over
+const leaseMarginDivisor = 5
+
+// LockService defines the distributed lock operations needed for leader election
+type LockService interface {
+	AcquireLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	RenewLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	ReleaseLock(ctx context.Context, key, owner string) error
+	LockHolder(ctx context.Context, key string) (string, error)
+}
+
+// Elector campaigns for a lease-based leadership shared by all instances.
+// The leader renews its lease periodically; if it dies the lease expires and
+// another instance takes over on its next attempt.
+type Elector struct {
+	locks      LockService
+	key        string
+	instanceID string
+	ttl        time.Duration
+	clock      clock.Clock
+
+	mu          sync.RWMutex
+	isLeader    bool
+	lastRenewal time.Time // When the last successful attempt was sent
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewElector creates a new elector for this instance
+func NewElector(cfg config.ClusterConfig, locks LockService) *Elector {
+	return &Elector{
+		locks:      locks,
+		key:        cfg.LeaderKey,
+		instanceID: cfg.InstanceID,
+		ttl:        cfg.LeaseTTL,
+		clock:      clock.New(),
+	}
+}
+
+// SetClock replaces the clock the elector renews its lease on, e.g. with a
+// fake clock in tests. It must be called before Start.
+func (e *Elector) SetClock(c clock.Clock) {
+	e.clock = c
+}
+
+// Start makes a first attempt at leadership and keeps campaigning in the background
+func (e *Elector) Start(ctx context.Context) {
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.done = make(chan struct{})
+
+	e.campaign(ctx)
+
+	go func() {
+		defer close(e.done)
+
+		// Renew well before the lease runs out
+		ticker := e.clock.NewTicker(e.ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				e.campaign(ctx)
+			}
+		}
+	}()
+
+	log.Printf("Leader election started for instance %s", e.instanceID)
+}
+
+// Stop ends the campaign and hands over leadership if this instance holds it
+func (e *Elector) Stop() {
+	if e.cancel == nil {
+		return
+	}
+	e.cancel()
+	<-e.done
+
+	if e.holdsLease() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := e.locks.ReleaseLock(ctx, e.key, e.instanceID); err != nil {
+			log.Printf("Error releasing leadership: %v", err)
+		}
+		e.setLeader(false, time.Time{})
+	}
+
+	log.Printf("Leader election stopped for instance %s", e.instanceID)
+}
+
+// IsLeader reports whether this instance currently holds the lease. An
+// instance that could not renew its lease stops being the leader a safety
+// margin before the lease expires.
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.isLeader && e.clock.Now().Before(e.stepDownAt())
+}
+
+// holdsLease reports whether this instance last acquired or renewed the
+// lease, even if it already stepped down because the renewal is overdue
+func (e *Elector) holdsLease() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.isLeader
+}
+
+// stepDownAt returns when leadership lapses unless renewed; callers must hold e.mu
+func (e *Elector) stepDownAt() time.Time {
+	return e.lastRenewal.Add(e.ttl - e.ttl/leaseMarginDivisor)
+}
+
+// InstanceID returns the identifier this instance campaigns with
+func (e *Elector) InstanceID() string {
+	return e.instanceID
+}
+
+// Leader returns the identifier of the instance currently holding the lease,
+// or an empty string if there is no leader
+func (e *Elector) Leader(ctx context.Context) (string, error) {
+	return e.locks.LockHolder(ctx, e.key)
+}
+
+// campaign renews the lease when leading, or tries to acquire it otherwise
+func (e *Elector) campaign(ctx context.Context) {
+	if e.holdsLease() {
+		e.renew(ctx)
+		return
+	}
+
+	// The lease runs from when it was requested, not from when Redis answered
+	sentAt := e.clock.Now()
+	acquired, err := e.locks.AcquireLock(ctx, e.key, e.instanceID, e.ttl)
+	if err != nil {
+		log.Printf("Error campaigning for leadership: %v", err)
+		return
+	}
+
+	if acquired {
+		e.setLeader(true, sentAt)
+		log.Printf("Instance %s became leader", e.instanceID)
+	}
+}
+
+// renew extends the lease, stepping down if it was lost or could not be
+// renewed a safety margin before it would expire
+func (e *Elector) renew(ctx context.Context) {
+	sentAt := e.clock.Now()
+	renewed, err := e.locks.RenewLock(ctx, e.key, e.instanceID, e.ttl)
+	if err == nil && renewed {
+		e.setLeader(true, sentAt)
+		return
+	}
+
+	if err != nil {
+		e.mu.RLock()
+		expired := !e.clock.Now().Before(e.stepDownAt())
+		e.mu.RUnlock()
+
+		if !expired {
+			log.Printf("Error renewing leadership, will retry: %v", err)
+			return
+		}
+	}
+
+	e.setLeader(false, time.Time{})
+	log.Printf("Instance %s lost leadership", e.instanceID)
+}
+
+// setLeader updates the leadership state
+func (e *Elector) setLeader(isLeader bool, renewedAt time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.isLeader = isLeader
+	e.lastRenewal = renewedAt
+}
@@ -0,0 +1,199 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+var testStart = time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+const testTTL = 15 * time.Second
+
+// memLocks is a lock service kept in memory, expiring locks on a fake clock
+type memLocks struct {
+	clock   *clock.Fake
+	mu      sync.Mutex
+	holder  string
+	expires time.Time
+	err     error         // Returned by every call when set
+	latency time.Duration // How far the clock moves while a call is served
+}
+
+func (l *memLocks) serve() error {
+	if l.latency > 0 {
+		l.clock.Advance(l.latency)
+	}
+	return l.err
+}
+
+func (l *memLocks) held() bool {
+	return l.holder != "" && l.clock.Now().Before(l.expires)
+}
+
+func (l *memLocks) AcquireLock(_ context.Context, _, owner string, ttl time.Duration) (bool, error) {
+	if err := l.serve(); err != nil {
+		return false, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held() {
+		return false, nil
+	}
+	l.holder, l.expires = owner, l.clock.Now().Add(ttl)
+	return true, nil
+}
+
+func (l *memLocks) RenewLock(_ context.Context, _, owner string, ttl time.Duration) (bool, error) {
+	if err := l.serve(); err != nil {
+		return false, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.held() || l.holder != owner {
+		return false, nil
+	}
+	l.expires = l.clock.Now().Add(ttl)
+	return true, nil
+}
+
+func (l *memLocks) ReleaseLock(_ context.Context, _, owner string) error {
+	if err := l.serve(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder == owner {
+		l.holder = ""
+	}
+	return nil
+}
+
+func (l *memLocks) LockHolder(context.Context, string) (string, error) {
+	if err := l.serve(); err != nil {
+		return "", err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.held() {
+		return "", nil
+	}
+	return l.holder, nil
+}
+
+// newTestElector creates an elector for an instance on a fake clock
+func newTestElector(id string, locks *memLocks) *Elector {
+	e := NewElector(config.ClusterConfig{InstanceID: id, LeaderKey: "leader", LeaseTTL: testTTL}, locks)
+	e.SetClock(locks.clock)
+	return e
+}
+
+func TestElectorCampaign(t *testing.T) {
+	locks := &memLocks{clock: clock.NewFake(testStart)}
+	a, b := newTestElector("a", locks), newTestElector("b", locks)
+	ctx := context.Background()
+
+	a.campaign(ctx)
+	b.campaign(ctx)
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("Expected only the first instance to lead, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	if leader, err := b.Leader(ctx); err != nil || leader != "a" {
+		t.Errorf("Expected leader a, got %q (%v)", leader, err)
+	}
+
+	// Renewals keep the lease past its first expiry
+	for i := 0; i < 6; i++ {
+		locks.clock.Advance(testTTL / 3)
+		a.campaign(ctx)
+		b.campaign(ctx)
+	}
+	if !a.IsLeader() || b.IsLeader() {
+		t.Errorf("Expected the renewed lease to stay with a, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+}
+
+func TestElectorLeaseRunsFromRequest(t *testing.T) {
+	locks := &memLocks{clock: clock.NewFake(testStart), latency: 2 * time.Second}
+	a := newTestElector("a", locks)
+
+	a.campaign(context.Background())
+	if !a.IsLeader() {
+		t.Fatalf("Expected a to lead")
+	}
+
+	// The lease was requested at testStart, so a steps down a safety margin
+	// before testStart+TTL whatever the latency of the answer
+	stepDown := testStart.Add(testTTL - testTTL/leaseMarginDivisor)
+	locks.clock.Advance(stepDown.Sub(locks.clock.Now()) - time.Nanosecond)
+	if !a.IsLeader() {
+		t.Errorf("Expected a to lead until the safety margin")
+	}
+	locks.clock.Advance(time.Nanosecond)
+	if a.IsLeader() {
+		t.Errorf("Expected a to step down at %v, still leading at %v", stepDown, locks.clock.Now())
+	}
+}
+
+func TestElectorFailsOverWhenRenewalsFail(t *testing.T) {
+	locks := &memLocks{clock: clock.NewFake(testStart)}
+	a, b := newTestElector("a", locks), newTestElector("b", locks)
+	ctx := context.Background()
+
+	a.campaign(ctx)
+	locks.err = errors.New("i/o timeout")
+
+	// Failed renewals are retried while the lease is safe
+	locks.clock.Advance(testTTL / 3)
+	a.campaign(ctx)
+	if !a.IsLeader() {
+		t.Fatalf("Expected a to keep leading after one failed renewal")
+	}
+
+	// a steps down before the lease expires, so both never lead at once
+	locks.clock.Advance(testTTL - testTTL/leaseMarginDivisor - testTTL/3)
+	if a.IsLeader() {
+		t.Errorf("Expected a to step down a safety margin before its lease expires")
+	}
+
+	locks.clock.Advance(testTTL / leaseMarginDivisor)
+	a.campaign(ctx)
+	locks.err = nil
+	b.campaign(ctx)
+	if a.IsLeader() || !b.IsLeader() {
+		t.Errorf("Expected b to take over once the lease expired, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+
+	// a campaigns again as a follower once its store is reachable
+	a.campaign(ctx)
+	if a.IsLeader() {
+		t.Errorf("Expected a to stay a follower")
+	}
+}
+
+func TestElectorReleasesLeadershipOnStop(t *testing.T) {
+	locks := &memLocks{clock: clock.NewFake(testStart)}
+	a := newTestElector("a", locks)
+
+	a.Start(context.Background())
+	if !a.IsLeader() {
+		t.Fatalf("Expected a to lead after starting")
+	}
+
+	a.Stop()
+	if a.IsLeader() {
+		t.Errorf("Expected a to stop leading")
+	}
+	if leader, _ := a.Leader(context.Background()); leader != "" {
+		t.Errorf("Expected the lease to be released, held by %q", leader)
+	}
+}
@@ -12,3 +12,17 @@ type ArticleData struct {
 	ScrapedAt time.Time `json:"scraped_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// SymbolState tracks how scraping a symbol from a source has been going
+type SymbolState struct {
+	Symbol              string    `json:"symbol"`
+	Source              string    `json:"source"`
+	LastScrapedAt       time.Time `json:"last_scraped_at"`
+	LastSuccessAt       time.Time `json:"last_success_at"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastYield           int       `json:"last_yield"`    // New articles found by the last successful scrape
+	ArticleYield        float64   `json:"article_yield"` // Moving average of new articles per successful scrape
+	TotalScrapes        int64     `json:"total_scrapes"`
+	TotalArticles       int64     `json:"total_articles"`
+	RecentURLs          []string  `json:"recent_urls,omitempty"` // Tells new articles from ones already seen
+}
@@ -6,7 +6,11 @@ import (
 	"log"
 	"time"
 
+	"github.com/guillermoballester/propagatorGo/internal/calendar"
+	"github.com/guillermoballester/propagatorGo/internal/clock"
 	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/leader"
 	"github.com/guillermoballester/propagatorGo/internal/scheduler"
 	scraper "github.com/guillermoballester/propagatorGo/internal/scrapper"
 	"github.com/guillermoballester/propagatorGo/internal/task"
@@ -15,8 +19,11 @@ import (
 
 // Orchestrator manages worker pools and schedules their execution
 type Orchestrator struct {
-	scheduler *scheduler.Scheduler
-	pools     map[string]*worker.Pool
+	scheduler    *scheduler.Scheduler
+	schedulerCfg *config.SchedulerConfig
+	pools        map[string]*worker.Pool
+	elector      *leader.Elector
+	clock        clock.Clock
 
 	workerDeps *WorkerDependencies
 }
@@ -31,19 +38,116 @@ type WorkerDependencies struct {
 // NewOrchestrator creates a new orchestrator
 func NewOrchestrator(schedulerCfg *config.SchedulerConfig, deps *WorkerDependencies) *Orchestrator {
 	return &Orchestrator{
-		scheduler:  scheduler.NewScheduler(schedulerCfg),
-		pools:      make(map[string]*worker.Pool),
-		workerDeps: deps,
+		scheduler:    scheduler.NewScheduler(schedulerCfg),
+		schedulerCfg: schedulerCfg,
+		pools:        make(map[string]*worker.Pool),
+		clock:        clock.New(),
+		workerDeps:   deps,
 	}
 }
 
-// RegisterWorkerPool creates and registers a worker pool
+// SetClock makes the orchestrator, its scheduler and the workers it creates
+// tell the time and wait on a clock other than the wall clock, e.g. a fake
+// clock in tests. It must be called before jobs are registered.
+func (o *Orchestrator) SetClock(c clock.Clock) {
+	o.clock = c
+	o.scheduler.SetClock(c)
+	if o.workerDeps != nil && o.workerDeps.WorkerFactory != nil {
+		o.workerDeps.WorkerFactory.SetClock(c)
+	}
+}
+
+// RegisterJobs registers every configured job: dispatch and apiCall jobs, and
+// pool jobs along with the pool linked to them by name
+func (o *Orchestrator) RegisterJobs(pools []config.WorkerConfig) error {
+	if err := scheduler.ValidateDAG(o.schedulerCfg.Jobs); err != nil {
+		return err
+	}
+
+	poolsByJob := make(map[string]config.WorkerConfig, len(pools))
+	for _, pool := range pools {
+		poolsByJob[pool.JobName] = pool
+	}
+
+	for _, job := range o.schedulerCfg.Jobs {
+		switch job.Kind {
+		case constants.JobKindDispatch:
+			if err := o.RegisterDispatchJob(job); err != nil {
+				return fmt.Errorf("error registering dispatch job %s: %w", job.Name, err)
+			}
+		case constants.JobKindAPICall:
+			if err := o.RegisterAPICallJob(job); err != nil {
+				return fmt.Errorf("error registering api call job %s: %w", job.Name, err)
+			}
+		case "", constants.JobKindPool:
+			pool, ok := poolsByJob[job.Name]
+			if !ok {
+				return fmt.Errorf("job %s has no worker pool configured", job.Name)
+			}
+			if !pool.Enabled {
+				log.Printf("Worker pool %s is disabled, not registering it", job.Name)
+				continue
+			}
+			if err := o.RegisterWorkerPool(pool); err != nil {
+				return fmt.Errorf("error registering worker pool %s: %w", job.Name, err)
+			}
+		default:
+			return fmt.Errorf("job %s: unknown kind: %s", job.Name, job.Kind)
+		}
+	}
+
+	return nil
+}
+
+// RunStartupJobs runs the enabled jobs configured to run on start, each after
+// its start delay
+func (o *Orchestrator) RunStartupJobs() {
+	for _, job := range o.schedulerCfg.Jobs {
+		if !job.RunOnStart || !job.Enabled {
+			continue
+		}
+
+		name := job.Name
+		if job.StartDelay > 0 {
+			log.Printf("Running job %s in %s", name, job.StartDelay)
+		}
+		o.clock.AfterFunc(job.StartDelay, func() {
+			if err := o.RunJob(name); err != nil {
+				log.Printf("Failed to run %s: %v", name, err)
+			}
+		})
+	}
+}
+
+// jobConfig returns the configuration of a job
+func (o *Orchestrator) jobConfig(name string) (config.JobConfig, error) {
+	for _, job := range o.schedulerCfg.Jobs {
+		if job.Name == name {
+			return job, nil
+		}
+	}
+	return config.JobConfig{}, fmt.Errorf("job %s is not configured", name)
+}
+
+// RegisterWorkerPool creates and registers a worker pool, run by the
+// configured job named by its JobName
 func (o *Orchestrator) RegisterWorkerPool(cfg config.WorkerConfig) error {
-	pool := worker.NewPool(cfg.PoolSize)
+	job, err := o.jobConfig(cfg.JobName)
+	if err != nil {
+		return err
+	}
+
+	size := cfg.PoolSize
+	if cfg.Autoscale != nil {
+		size = cfg.Autoscale.MinWorkers
+	}
+	pool := worker.NewPool(cfg.JobName, size)
+	pool.SetClock(o.clock)
+	pool.SetRestartPolicy(cfg.Restart)
 
 	// Create and add workers based on type
-	for i := 0; i < cfg.PoolSize; i++ {
-		w, workerErr := o.workerDeps.WorkerFactory.CreateWorker(i, cfg.WorkerType, cfg.Source)
+	for i := 0; i < size; i++ {
+		w, workerErr := o.workerDeps.WorkerFactory.CreateWorker(i, cfg)
 		if workerErr != nil {
 			return fmt.Errorf("error creating worker: %w", workerErr)
 		}
@@ -52,45 +156,236 @@ func (o *Orchestrator) RegisterWorkerPool(cfg config.WorkerConfig) error {
 		}
 	}
 
+	if cfg.Autoscale != nil {
+		taskType, err := drainedTaskType(cfg)
+		if err != nil {
+			return err
+		}
+
+		depth := func(ctx context.Context) (int64, error) {
+			return o.workerDeps.TaskService.QueueLength(ctx, taskType)
+		}
+		pool.EnableAutoscaling(worker.NewAutoscaler(*cfg.Autoscale, depth), func(id int) (worker.Worker, error) {
+			return o.workerDeps.WorkerFactory.CreateWorker(id, cfg)
+		})
+	}
+
 	o.pools[cfg.JobName] = pool
-	if err := o.registerJobHandler(cfg.JobName, cfg.CronExpr, pool); err != nil {
+	if err := o.registerJobHandler(job, cfg, pool); err != nil {
 		return fmt.Errorf("error registering job: %w", err)
 	}
 
 	return nil
 }
 
+// RegisterDispatchJob schedules a job that enqueues one scrape task per enabled
+// stock and source, to be consumed by scraper pools running in queue mode on
+// any instance. An empty Sources list dispatches every enabled site.
+func (o *Orchestrator) RegisterDispatchJob(job config.JobConfig) error {
+	name := job.Name
+	return o.scheduler.AddJob(job, func(ctx context.Context) (*scheduler.JobResult, error) {
+		if !o.shouldRun(ctx, name, true) {
+			return nil, scheduler.ErrSkipped
+		}
+
+		added, err := o.workerDeps.TaskService.EnqueueScrapeCycle(ctx, job.Sources)
+		if err != nil {
+			return nil, fmt.Errorf("error dispatching scrape tasks: %w", err)
+		}
+
+		log.Printf("Job %s dispatched %d scrape tasks", name, added)
+		return &scheduler.JobResult{
+			ItemsProcessed: int64(added),
+			Summary:        "scrape tasks dispatched",
+		}, nil
+	})
+}
+
+// RegisterAPICallJob schedules a job that enqueues an api_call task per
+// configured call, to be performed by API worker pools on any instance
+func (o *Orchestrator) RegisterAPICallJob(job config.JobConfig) error {
+	name := job.Name
+	calls := make([]task.APICall, 0, len(job.APICalls))
+	for _, call := range job.APICalls {
+		calls = append(calls, task.NewAPICall(call))
+	}
+
+	return o.scheduler.AddJob(job, func(ctx context.Context) (*scheduler.JobResult, error) {
+		if !o.shouldRun(ctx, name, true) {
+			return nil, scheduler.ErrSkipped
+		}
+
+		added, err := o.workerDeps.TaskService.EnqueueAPICalls(ctx, calls)
+		if err != nil {
+			return nil, fmt.Errorf("error enqueueing api calls: %w", err)
+		}
+
+		return &scheduler.JobResult{
+			ItemsProcessed: int64(added),
+			Summary:        "api calls enqueued",
+		}, nil
+	})
+}
+
+// defaultRunWindow is how long a window-mode pool runs, leaving some buffer
+// before the job timeout
+const defaultRunWindow = 4 * time.Minute
+
 // registerJobHandler adds a job to the scheduler for a worker pool
-func (o *Orchestrator) registerJobHandler(name string, cronExpr string, pool *worker.Pool) error {
-	return o.scheduler.AddJob(name, cronExpr, 5*time.Minute, func(ctx context.Context) error {
+func (o *Orchestrator) registerJobHandler(job config.JobConfig, cfg config.WorkerConfig, pool *worker.Pool) error {
+	name := cfg.JobName
+	leaderOnly := !runsOnEveryInstance(cfg)
+	passMode := cfg.RunMode == constants.RunModePass
+	pool.SetRunToCompletion(passMode)
+
+	window := cfg.RunWindow
+	if window <= 0 {
+		window = defaultRunWindow
+	}
+
+	return o.scheduler.AddJob(job, func(ctx context.Context) (*scheduler.JobResult, error) {
+		if !o.shouldRun(ctx, name, leaderOnly) {
+			return nil, scheduler.ErrSkipped
+		}
+
 		log.Printf("Starting worker pool for job: %s", name)
 
+		// Round-robin scrapers hand out each enabled stock once per pass
+		if passMode && cfg.WorkerType == constants.WorkerTypeScraper && cfg.ScrapeMode != constants.ScrapeModeQueue {
+			o.workerDeps.WorkerFactory.WorkManager(cfg.Source).BeginPass()
+		}
+
 		// Create a context that can be cancelled
 		poolCtx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
+		before := pool.Stats()
+
 		// Start the worker pool
 		if err := pool.Start(poolCtx); err != nil {
-			return fmt.Errorf("error starting worker pool: %w", err)
+			return nil, fmt.Errorf("error starting worker pool: %w", err)
+		}
+
+		// A nil channel never fires, so pass mode has no time limit besides the job timeout
+		var windowElapsed <-chan time.Time
+		if !passMode {
+			windowElapsed = o.clock.After(window)
 		}
 
 		// Wait for the pool to finish or context to be cancelled
+		var jobErr error
+		var summary string
 		select {
 		case <-ctx.Done():
 			log.Printf("Job %s cancelled", name)
-		case <-time.After(4 * time.Minute): // Leave some buffer before timeout
+			summary = "cancelled"
+		case <-pool.Finished():
+			// Window-mode workers only exit when the pool is shut down
+			if passMode {
+				log.Printf("Job %s completed its pass", name)
+				summary = "pass complete"
+			} else {
+				log.Printf("Job %s workers stopped", name)
+				summary = "stopped"
+			}
+		case <-windowElapsed:
 			log.Printf("Job %s maximum runtime reached", name)
+			summary = "run window elapsed"
+		case crashErr := <-pool.CrashLoop():
+			log.Printf("Job %s aborted, pool is crash-looping: %v", name, crashErr)
+			jobErr = fmt.Errorf("worker pool crash-looping: %w", crashErr)
+			summary = "aborted"
 		}
 
 		// Stop the pool
 		pool.Stop()
 		pool.Wait()
 
+		after := pool.Stats()
 		log.Printf("Worker pool for job %s completed", name)
-		return nil
+		return &scheduler.JobResult{
+			ItemsProcessed: after.ItemsProcessed - before.ItemsProcessed,
+			ItemsFailed:    after.ItemsFailed - before.ItemsFailed,
+			Summary:        summary,
+		}, jobErr
 	})
 }
 
+// SetHistory records every job run in a history store, tagged with the ID of
+// this instance
+func (o *Orchestrator) SetHistory(store scheduler.HistoryStore, instanceID string) {
+	o.scheduler.SetHistory(store, instanceID)
+}
+
+// SetOverrides persists the changes made to jobs at runtime and applies them
+// on start
+func (o *Orchestrator) SetOverrides(store scheduler.OverrideStore) {
+	o.scheduler.SetOverrides(store)
+}
+
+// SetCalendars makes exchange calendars available to calendar-aware jobs. It
+// must be called before jobs are registered.
+func (o *Orchestrator) SetCalendars(calendars *calendar.Registry) {
+	o.scheduler.SetCalendars(calendars)
+}
+
+// SetElector enables leader election: jobs that must run once across the
+// cluster are skipped on instances that are not the current leader
+func (o *Orchestrator) SetElector(e *leader.Elector) {
+	o.elector = e
+}
+
+// shouldRun reports whether this instance should execute a job. Manual runs
+// execute on the instance they were requested from, leader or not.
+func (o *Orchestrator) shouldRun(ctx context.Context, name string, leaderOnly bool) bool {
+	if !leaderOnly || o.elector == nil || o.elector.IsLeader() {
+		return true
+	}
+	if scheduler.TriggerFromContext(ctx) == scheduler.TriggerManual {
+		return true
+	}
+
+	log.Printf("Skipping job %s: instance %s is not the leader", name, o.elector.InstanceID())
+	return false
+}
+
+// drainedTaskType returns the task type whose queue a pool consumes, which
+// drives autoscaling. Only queue-backed pools can autoscale.
+func drainedTaskType(cfg config.WorkerConfig) (string, error) {
+	switch {
+	case cfg.WorkerType == constants.WorkerTypeConsumer:
+		return constants.TaskTypeConsume, nil
+	case cfg.WorkerType == constants.WorkerTypeScraper && cfg.ScrapeMode == constants.ScrapeModeQueue:
+		return constants.TaskTypeScrape, nil
+	case cfg.WorkerType == constants.WorkerTypeAPI:
+		return constants.TaskTypeAPICall, nil
+	default:
+		return "", fmt.Errorf("pool %s cannot autoscale: it does not consume a queue", cfg.JobName)
+	}
+}
+
+// PoolStats returns the stats of every registered pool
+func (o *Orchestrator) PoolStats() []worker.PoolStats {
+	stats := make([]worker.PoolStats, 0, len(o.pools))
+	for name, pool := range o.pools {
+		poolStats := pool.Stats()
+		if p := o.workerDeps.WorkerFactory.Pipeline(name); p != nil {
+			poolStats.Pipeline = p.Stats()
+		}
+		stats = append(stats, poolStats)
+	}
+	return stats
+}
+
+// runsOnEveryInstance reports whether a pool pulls its work from a shared
+// queue, so running it on every instance spreads the load instead of
+// duplicating it
+func runsOnEveryInstance(cfg config.WorkerConfig) bool {
+	return cfg.WorkerType == constants.WorkerTypeConsumer ||
+		cfg.WorkerType == constants.WorkerTypeAPI ||
+		cfg.ScrapeMode == constants.ScrapeModeQueue
+}
+
 // Start starts the orchestrator
 func (o *Orchestrator) Start() {
 	o.scheduler.Start()
@@ -108,3 +403,38 @@ func (o *Orchestrator) RunJob(name string) error {
 	log.Printf("Starting job: %s", name)
 	return o.scheduler.RunJob(name)
 }
+
+// Jobs returns a snapshot of every job, sorted by name
+func (o *Orchestrator) Jobs() []scheduler.JobInfo {
+	return o.scheduler.Jobs()
+}
+
+// Job returns a snapshot of a job
+func (o *Orchestrator) Job(name string) (scheduler.JobInfo, error) {
+	return o.scheduler.Job(name)
+}
+
+// PauseJob stops firing the scheduled runs of a job until it is resumed
+func (o *Orchestrator) PauseJob(name string) error {
+	log.Printf("Pausing job: %s", name)
+	return o.scheduler.PauseJob(name)
+}
+
+// ResumeJob fires the scheduled runs of a paused job again
+func (o *Orchestrator) ResumeJob(name string) error {
+	log.Printf("Resuming job: %s", name)
+	return o.scheduler.ResumeJob(name)
+}
+
+// RescheduleJob changes the cron expression of a job; an empty expression
+// restores the configured one
+func (o *Orchestrator) RescheduleJob(name, cronExpr string) error {
+	log.Printf("Rescheduling job %s with %q", name, cronExpr)
+	return o.scheduler.RescheduleJob(name, cronExpr)
+}
+
+// CancelJob cancels the runs of a job in progress on this instance
+func (o *Orchestrator) CancelJob(name string) (int, error) {
+	log.Printf("Cancelling job: %s", name)
+	return o.scheduler.CancelJob(name)
+}
@@ -0,0 +1,287 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/leader"
+	"github.com/guillermoballester/propagatorGo/internal/scheduler"
+	"github.com/guillermoballester/propagatorGo/internal/worker"
+)
+
+var testStart = time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+// stubWorker runs a function each time it is started
+type stubWorker struct {
+	worker.BaseWorker
+	run    func(ctx context.Context, w *stubWorker) error
+	starts int32
+}
+
+func (w *stubWorker) Start(ctx context.Context) error {
+	w.SetActive(true)
+	atomic.AddInt32(&w.starts, 1)
+	return w.run(ctx, w)
+}
+
+func (w *stubWorker) startCount() int {
+	return int(atomic.LoadInt32(&w.starts))
+}
+
+// untilStopped keeps a worker running until the pool stops it, as workers
+// finish their current item before checking whether they should stop
+func untilStopped(ctx context.Context, w *stubWorker) error {
+	for w.IsActive() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return nil
+}
+
+// newTestOrchestrator creates an orchestrator on a fake clock
+func newTestOrchestrator() (*Orchestrator, *clock.Fake) {
+	fake := clock.NewFake(testStart)
+	o := NewOrchestrator(&config.SchedulerConfig{}, &WorkerDependencies{
+		WorkerFactory: worker.NewWorkerFactory(&config.Config{}, nil, nil, nil),
+	})
+	o.SetClock(fake)
+	return o, fake
+}
+
+// addPoolJob registers a job running a pool of one stub worker
+func addPoolJob(t *testing.T, o *Orchestrator, fake *clock.Fake, cfg config.WorkerConfig, restart config.RestartConfig,
+	run func(ctx context.Context, w *stubWorker) error) *stubWorker {
+	t.Helper()
+
+	w := &stubWorker{BaseWorker: worker.NewBaseWorker(1, cfg.JobName+"-1", cfg.WorkerType, fake), run: run}
+	pool := worker.NewPool(cfg.JobName, 1)
+	pool.SetClock(fake)
+	pool.SetRestartPolicy(restart)
+	if err := pool.AddWorker(w); err != nil {
+		t.Fatalf("Expected the worker to be added, got %v", err)
+	}
+	o.pools[cfg.JobName] = pool
+
+	job := config.JobConfig{Name: cfg.JobName, Enabled: true}
+	if err := o.registerJobHandler(job, cfg, pool); err != nil {
+		t.Fatalf("Expected the job to be registered, got %v", err)
+	}
+	return w
+}
+
+// waitFor waits for a condition reached by a job or worker goroutine
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitForRun waits for the run of a job to finish and returns the job
+func waitForRun(t *testing.T, o *Orchestrator, name string) scheduler.JobInfo {
+	t.Helper()
+
+	var info scheduler.JobInfo
+	waitFor(t, "job "+name+" to finish", func() bool {
+		info, _ = o.Job(name)
+		return info.LastResult != nil && info.Status != scheduler.StatusRunning
+	})
+	return info
+}
+
+func TestPoolJobEndsWhenRunWindowElapses(t *testing.T) {
+	o, fake := newTestOrchestrator()
+	cfg := config.WorkerConfig{JobName: "consume", WorkerType: constants.WorkerTypeConsumer,
+		RunMode: constants.RunModeWindow, RunWindow: time.Minute}
+	w := addPoolJob(t, o, fake, cfg, config.RestartConfig{}, untilStopped)
+
+	if err := o.RunJob("consume"); err != nil {
+		t.Fatalf("Expected the job to run, got %v", err)
+	}
+	fake.BlockUntil(1)
+
+	fake.Advance(time.Minute - time.Nanosecond)
+	time.Sleep(5 * time.Millisecond)
+	if info, _ := o.Job("consume"); info.Status != scheduler.StatusRunning {
+		t.Fatalf("Expected the job to run until the window elapsed, got %s", info.Status)
+	}
+
+	fake.Advance(time.Nanosecond)
+	info := waitForRun(t, o, "consume")
+	if info.Status != scheduler.StatusSucceeded || info.LastResult.Summary != "run window elapsed" {
+		t.Errorf("Expected the window to end the run, got %s: %+v", info.Status, info.LastResult)
+	}
+	if w.IsActive() {
+		t.Errorf("Expected the worker to be stopped")
+	}
+}
+
+func TestPoolJobEndsWhenPassCompletes(t *testing.T) {
+	o, fake := newTestOrchestrator()
+	cfg := config.WorkerConfig{JobName: "scrape", WorkerType: constants.WorkerTypeConsumer,
+		RunMode: constants.RunModePass}
+	addPoolJob(t, o, fake, cfg, config.RestartConfig{}, func(ctx context.Context, w *stubWorker) error {
+		for _, symbol := range []string{"AAPL", "MSFT", "NVDA"} {
+			w.Stats.RecordItemProcessed(worker.ItemLabels{Symbol: symbol}, time.Second)
+		}
+		return nil
+	})
+
+	if err := o.RunJob("scrape"); err != nil {
+		t.Fatalf("Expected the job to run, got %v", err)
+	}
+
+	info := waitForRun(t, o, "scrape")
+	if info.Status != scheduler.StatusSucceeded || info.LastResult.Summary != "pass complete" {
+		t.Errorf("Expected the pass to complete the run, got %s: %+v", info.Status, info.LastResult)
+	}
+	if info.LastResult.ItemsProcessed != 3 {
+		t.Errorf("Expected 3 items processed, got %d", info.LastResult.ItemsProcessed)
+	}
+}
+
+func TestPoolJobAbortsWhenCrashLooping(t *testing.T) {
+	o, fake := newTestOrchestrator()
+	cfg := config.WorkerConfig{JobName: "consume", WorkerType: constants.WorkerTypeConsumer,
+		RunMode: constants.RunModePass}
+	restart := config.RestartConfig{MaxRestarts: 2, Window: time.Minute, InitialBackoff: time.Second}
+	w := addPoolJob(t, o, fake, cfg, restart, func(context.Context, *stubWorker) error {
+		return errors.New("redis unavailable")
+	})
+
+	if err := o.RunJob("consume"); err != nil {
+		t.Fatalf("Expected the job to run, got %v", err)
+	}
+
+	for _, backoff := range []time.Duration{time.Second, 2 * time.Second} {
+		fake.BlockUntil(1)
+		fake.Advance(backoff)
+	}
+
+	info := waitForRun(t, o, "consume")
+	if info.Status != scheduler.StatusFailed || info.LastResult.Summary != "aborted" {
+		t.Errorf("Expected the crash loop to abort the run, got %s: %+v", info.Status, info.LastResult)
+	}
+	if !strings.Contains(info.LastError, "crash-looping") || !strings.Contains(info.LastError, "redis unavailable") {
+		t.Errorf("Expected the crash loop error, got %q", info.LastError)
+	}
+	if starts := w.startCount(); starts != 3 {
+		t.Errorf("Expected 3 starts, got %d", starts)
+	}
+	if restarts := o.PoolStats()[0].Restarts; restarts != 2 {
+		t.Errorf("Expected 2 restarts, got %d", restarts)
+	}
+}
+
+func TestLeaderOnlyJobOnFollower(t *testing.T) {
+	o, fake := newTestOrchestrator()
+	defer o.Stop()
+	// An elector that never campaigned does not hold the lease
+	o.SetElector(leader.NewElector(config.ClusterConfig{InstanceID: "follower"}, nil))
+
+	cfg := config.WorkerConfig{JobName: "scrape", WorkerType: constants.WorkerTypeScraper,
+		RunMode: constants.RunModeWindow, RunWindow: time.Minute}
+	w := addPoolJob(t, o, fake, cfg, config.RestartConfig{}, untilStopped)
+
+	// A manual trigger runs on the instance it was sent to
+	if err := o.RunJob("scrape"); err != nil {
+		t.Fatalf("Expected the job to run, got %v", err)
+	}
+	waitFor(t, "the worker to start", func() bool { return w.startCount() == 1 })
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	if info := waitForRun(t, o, "scrape"); info.Status != scheduler.StatusSucceeded {
+		t.Errorf("Expected the manual run to succeed on a follower, got %s", info.Status)
+	}
+
+	// Scheduled runs are left to the leader
+	if err := o.RescheduleJob("scrape", "0 * * * * *"); err != nil {
+		t.Fatalf("Expected the job to be rescheduled, got %v", err)
+	}
+	o.Start()
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	waitFor(t, "the scheduled run to be skipped", func() bool {
+		info, _ := o.Job("scrape")
+		return info.Status == scheduler.StatusSkipped
+	})
+	if starts := w.startCount(); starts != 1 {
+		t.Errorf("Expected the scheduled run not to start the worker, got %d starts", starts)
+	}
+}
+
+func TestShutdownDrainsPools(t *testing.T) {
+	o, fake := newTestOrchestrator()
+	cfg := config.WorkerConfig{JobName: "consume", WorkerType: constants.WorkerTypeConsumer,
+		RunMode: constants.RunModeWindow, RunWindow: time.Minute}
+	w := addPoolJob(t, o, fake, cfg, config.RestartConfig{}, untilStopped)
+
+	if err := o.RunJob("consume"); err != nil {
+		t.Fatalf("Expected the job to run, got %v", err)
+	}
+	waitFor(t, "the worker to start", func() bool { return w.startCount() == 1 })
+
+	report := o.Shutdown(context.Background())
+	if len(report.Pools) != 1 || !report.Pools[0].Drained {
+		t.Fatalf("Expected the pool to be drained, got %+v", report.Pools)
+	}
+	if report.JobsInterrupted || len(report.Errors) != 0 {
+		t.Errorf("Expected a clean shutdown, got %v", report.Errors)
+	}
+	if info, _ := o.Job("consume"); info.LastResult == nil || info.LastResult.Summary != "stopped" {
+		t.Errorf("Expected the run to record the stopped pool, got %+v", info.LastResult)
+	}
+	if err := o.RunJob("consume"); !errors.Is(err, scheduler.ErrShuttingDown) {
+		t.Errorf("Expected new runs to be refused, got %v", err)
+	}
+}
+
+func TestShutdownCancelsWorkAfterDeadline(t *testing.T) {
+	o, fake := newTestOrchestrator()
+	cfg := config.WorkerConfig{JobName: "consume", WorkerType: constants.WorkerTypeConsumer,
+		RunMode: constants.RunModeWindow, RunWindow: time.Minute}
+
+	// The worker is stuck on its item until cancelled, then requeues it
+	w := addPoolJob(t, o, fake, cfg, config.RestartConfig{}, func(ctx context.Context, w *stubWorker) error {
+		<-ctx.Done()
+		w.Stats.RecordRequeue()
+		return ctx.Err()
+	})
+
+	if err := o.RunJob("consume"); err != nil {
+		t.Fatalf("Expected the job to run, got %v", err)
+	}
+	waitFor(t, "the worker to start", func() bool { return w.startCount() == 1 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := o.Shutdown(ctx)
+
+	if len(report.Pools) != 1 || report.Pools[0].Drained {
+		t.Fatalf("Expected the pool not to be drained, got %+v", report.Pools)
+	}
+	if requeued := report.Pools[0].Stats.Requeued; requeued != 1 {
+		t.Errorf("Expected the in-flight task to be requeued, got %d", requeued)
+	}
+	if report.JobsInterrupted {
+		t.Errorf("Expected the cancelled run to return, got %v", report.Errors)
+	}
+	if info, _ := o.Job("consume"); info.LastResult == nil || info.LastResult.Summary != "cancelled" {
+		t.Errorf("Expected the run to be cancelled, got %+v", info.LastResult)
+	}
+}
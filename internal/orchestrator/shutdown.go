@@ -0,0 +1,145 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/worker"
+)
+
+// forceTimeout bounds each step that runs after the shutdown deadline, so
+// cancelled work can requeue its tasks and checkpoints can be written
+const forceTimeout = 10 * time.Second
+
+// ShutdownReport describes how the orchestrator shut down
+type ShutdownReport struct {
+	Duration        time.Duration
+	Pools           []PoolShutdown
+	JobsInterrupted bool // Job runs were still in progress after the deadline
+	CursorsSaved    int
+	Errors          []error
+}
+
+// PoolShutdown describes how a single pool shut down
+type PoolShutdown struct {
+	Name    string
+	Drained bool // Every worker finished its current item before the deadline
+	Stats   worker.PoolStats
+}
+
+// Shutdown stops the orchestrator in order: it stops accepting job runs, lets
+// pools finish their current items until ctx is done, cancels whatever is left
+// so interrupted tasks are requeued and pending batches flushed, waits for job
+// runs to return and saves the work manager cursors
+func (o *Orchestrator) Shutdown(ctx context.Context) *ShutdownReport {
+	start := o.clock.Now()
+	report := &ShutdownReport{}
+
+	// Stop accepting new jobs
+	o.scheduler.Close()
+
+	// Let workers finish their current item
+	drained := o.shutdownPools(ctx)
+
+	// Cancel what did not finish in time; workers requeue their tasks on cancellation
+	forced := false
+	for _, ok := range drained {
+		if !ok {
+			forced = true
+			break
+		}
+	}
+	if forced {
+		log.Println("Shutdown deadline reached, cancelling in-flight work")
+		o.scheduler.Cancel()
+
+		forceCtx, cancel := o.clock.WithTimeout(context.Background(), forceTimeout)
+		o.shutdownPools(forceCtx)
+		cancel()
+	}
+
+	// Wait for job runs to record their results
+	waitCtx, cancel := o.clock.WithTimeout(context.Background(), forceTimeout)
+	if err := o.scheduler.Wait(waitCtx); err != nil {
+		report.JobsInterrupted = true
+		report.Errors = append(report.Errors, fmt.Errorf("job runs still in progress: %w", err))
+	}
+	cancel()
+	o.scheduler.Stop()
+
+	// Persist where each source's round-robin stopped
+	checkpointCtx, cancel := context.WithTimeout(context.Background(), forceTimeout)
+	saved, err := o.workerDeps.WorkerFactory.Checkpoint(checkpointCtx)
+	cancel()
+	report.CursorsSaved = saved
+	if err != nil {
+		report.Errors = append(report.Errors, err)
+	}
+
+	for name, pool := range o.pools {
+		report.Pools = append(report.Pools, PoolShutdown{
+			Name:    name,
+			Drained: drained[name],
+			Stats:   pool.Stats(),
+		})
+	}
+
+	report.Duration = o.clock.Since(start)
+	return report
+}
+
+// shutdownPools stops every pool concurrently and reports which ones stopped
+// before ctx was done
+func (o *Orchestrator) shutdownPools(ctx context.Context) map[string]bool {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	drained := make(map[string]bool, len(o.pools))
+
+	for name, pool := range o.pools {
+		wg.Add(1)
+		go func(name string, pool *worker.Pool) {
+			defer wg.Done()
+
+			err := pool.Shutdown(ctx)
+			if err != nil {
+				log.Printf("Pool %s did not stop in time: %v", name, err)
+			}
+
+			mu.Lock()
+			drained[name] = err == nil
+			mu.Unlock()
+		}(name, pool)
+	}
+
+	wg.Wait()
+	return drained
+}
+
+// Log writes the report to the application log
+func (r *ShutdownReport) Log() {
+	var drained int
+	var requeued int64
+	for _, pool := range r.Pools {
+		if pool.Drained {
+			drained++
+		}
+		requeued += pool.Stats.Requeued
+	}
+
+	log.Printf("Shutdown completed in %s: %d/%d pools drained, %d tasks requeued, %d cursors saved",
+		r.Duration.Round(time.Millisecond), drained, len(r.Pools), requeued, r.CursorsSaved)
+
+	for _, pool := range r.Pools {
+		log.Printf("Pool %s: drained=%t, processed=%d, failed=%d, requeued=%d",
+			pool.Name, pool.Drained, pool.Stats.ItemsProcessed, pool.Stats.ItemsFailed, pool.Stats.Requeued)
+	}
+	if r.JobsInterrupted {
+		log.Println("Some job runs were still in progress when the orchestrator stopped")
+	}
+	for _, err := range r.Errors {
+		log.Printf("Shutdown error: %v", err)
+	}
+}
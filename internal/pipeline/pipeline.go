@@ -0,0 +1,185 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/database"
+)
+
+var (
+	// ErrDrop is returned by a stage to discard an article: later stages are
+	// not run and the article is not persisted
+	ErrDrop = errors.New("article dropped")
+
+	// ErrSkip is returned by a stage that does not apply to an article; the
+	// pipeline continues with the next stage
+	ErrSkip = errors.New("stage skipped")
+)
+
+// What a pipeline does when a stage fails
+const (
+	OnErrorFail     = "fail"     // The article fails and is not persisted
+	OnErrorContinue = "continue" // The failure is counted and the next stage runs
+	OnErrorDrop     = "drop"     // The article is dropped
+)
+
+// Stage processes an article between dequeue and persistence. Stages are
+// shared by every worker of a pool and must be safe for concurrent use.
+type Stage interface {
+	// Name identifies the stage in logs and metrics
+	Name() string
+
+	// Process inspects or modifies the article. It returns ErrDrop to discard
+	// the article and ErrSkip when it does not apply.
+	Process(ctx context.Context, article *database.Article) error
+}
+
+// StageStats describes how a stage has been doing
+type StageStats struct {
+	Name       string        `json:"name"`
+	Processed  int64         `json:"processed"`
+	Skipped    int64         `json:"skipped"`
+	Dropped    int64         `json:"dropped"`
+	Failed     int64         `json:"failed"`
+	TimedOut   int64         `json:"timed_out"`
+	AvgLatency time.Duration `json:"avg_latency"`
+	MaxLatency time.Duration `json:"max_latency"`
+}
+
+// step is a configured stage along with its metrics
+type step struct {
+	stage   Stage
+	timeout time.Duration
+	onError string
+
+	mu    sync.Mutex
+	stats StageStats
+	total time.Duration
+}
+
+// Pipeline runs articles through an ordered list of stages
+type Pipeline struct {
+	steps []*step
+}
+
+// New creates an empty pipeline
+func New() *Pipeline {
+	return &Pipeline{}
+}
+
+// Add appends a stage. A zero timeout leaves the stage bounded only by the
+// caller's context; onError is one of the OnError constants.
+func (p *Pipeline) Add(stage Stage, timeout time.Duration, onError string) error {
+	switch onError {
+	case "":
+		onError = OnErrorFail
+	case OnErrorFail, OnErrorContinue, OnErrorDrop:
+	default:
+		return fmt.Errorf("stage %s: unknown onError policy: %s", stage.Name(), onError)
+	}
+
+	p.steps = append(p.steps, &step{
+		stage:   stage,
+		timeout: timeout,
+		onError: onError,
+		stats:   StageStats{Name: stage.Name()},
+	})
+	return nil
+}
+
+// Len returns the number of stages
+func (p *Pipeline) Len() int {
+	return len(p.steps)
+}
+
+// Process runs an article through every stage in order. It returns ErrDrop
+// when a stage dropped the article, or the error of a failing stage.
+func (p *Pipeline) Process(ctx context.Context, article *database.Article) error {
+	for _, s := range p.steps {
+		err := s.run(ctx, article)
+		switch {
+		case err == nil, errors.Is(err, ErrSkip):
+			continue
+		case errors.Is(err, ErrDrop):
+			return fmt.Errorf("stage %s: %w", s.stage.Name(), ErrDrop)
+		case ctx.Err() != nil:
+			// The caller is shutting down, not the stage failing
+			return ctx.Err()
+		case s.onError == OnErrorContinue:
+			continue
+		case s.onError == OnErrorDrop:
+			return fmt.Errorf("stage %s failed: %v: %w", s.stage.Name(), err, ErrDrop)
+		default:
+			return fmt.Errorf("stage %s failed: %w", s.stage.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Stats returns the metrics of every stage, in pipeline order
+func (p *Pipeline) Stats() []StageStats {
+	stats := make([]StageStats, 0, len(p.steps))
+	for _, s := range p.steps {
+		stats = append(stats, s.snapshot())
+	}
+	return stats
+}
+
+// run executes the stage under its timeout and records the outcome
+func (s *step) run(ctx context.Context, article *database.Article) error {
+	stageCtx := ctx
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		stageCtx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := s.stage.Process(stageCtx, article)
+	elapsed := time.Since(start)
+
+	// A stage that ignored its deadline still counts as timed out
+	timedOut := ctx.Err() == nil && stageCtx.Err() == context.DeadlineExceeded
+	if timedOut && err == nil {
+		err = fmt.Errorf("timed out after %s", s.timeout)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Processed++
+	s.total += elapsed
+	if elapsed > s.stats.MaxLatency {
+		s.stats.MaxLatency = elapsed
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrSkip):
+		s.stats.Skipped++
+	case errors.Is(err, ErrDrop):
+		s.stats.Dropped++
+	default:
+		s.stats.Failed++
+		if timedOut {
+			s.stats.TimedOut++
+		}
+	}
+
+	return err
+}
+
+// snapshot returns a copy of the stage metrics
+func (s *step) snapshot() StageStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	if stats.Processed > 0 {
+		stats.AvgLatency = s.total / time.Duration(stats.Processed)
+	}
+	return stats
+}
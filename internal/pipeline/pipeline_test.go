@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/database"
+)
+
+// funcStage runs a function as a stage and counts its calls
+type funcStage struct {
+	name  string
+	fn    func(ctx context.Context, article *database.Article) error
+	calls int
+}
+
+func (s *funcStage) Name() string {
+	return s.name
+}
+
+func (s *funcStage) Process(ctx context.Context, article *database.Article) error {
+	s.calls++
+	return s.fn(ctx, article)
+}
+
+func returning(name string, err error) *funcStage {
+	return &funcStage{name: name, fn: func(context.Context, *database.Article) error { return err }}
+}
+
+func TestPipelineProcess(t *testing.T) {
+	errBroken := errors.New("broken")
+
+	tests := []struct {
+		name      string
+		err       error
+		onError   string
+		dropped   bool
+		failed    bool
+		lastCalls int
+	}{
+		{"success runs every stage", nil, "", false, false, 1},
+		{"skip runs the next stage", ErrSkip, "", false, false, 1},
+		{"drop stops the pipeline", ErrDrop, "", true, false, 0},
+		{"failure fails by default", errBroken, "", false, true, 0},
+		{"failure with fail policy", errBroken, OnErrorFail, false, true, 0},
+		{"failure with continue policy", errBroken, OnErrorContinue, false, false, 1},
+		{"failure with drop policy", errBroken, OnErrorDrop, true, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := returning("first", tt.err)
+			last := returning("last", nil)
+
+			p := New()
+			if err := p.Add(first, 0, tt.onError); err != nil {
+				t.Fatalf("Expected stage to be added, got %v", err)
+			}
+			if err := p.Add(last, 0, ""); err != nil {
+				t.Fatalf("Expected stage to be added, got %v", err)
+			}
+
+			err := p.Process(context.Background(), &database.Article{})
+			if dropped := errors.Is(err, ErrDrop); dropped != tt.dropped {
+				t.Errorf("Expected dropped %v, got %v", tt.dropped, err)
+			}
+			if failed := err != nil && !errors.Is(err, ErrDrop); failed != tt.failed {
+				t.Errorf("Expected failed %v, got %v", tt.failed, err)
+			}
+			if tt.failed && !errors.Is(err, errBroken) {
+				t.Errorf("Expected the stage error to be wrapped, got %v", err)
+			}
+			if last.calls != tt.lastCalls {
+				t.Errorf("Expected the last stage to run %d times, got %d", tt.lastCalls, last.calls)
+			}
+		})
+	}
+}
+
+func TestPipelineAddRejectsUnknownPolicy(t *testing.T) {
+	if err := New().Add(returning("first", nil), 0, "retry"); err == nil {
+		t.Errorf("Expected an error for an unknown onError policy")
+	}
+}
+
+func TestPipelineStats(t *testing.T) {
+	p := New()
+	p.Add(returning("skip", ErrSkip), 0, "")
+	p.Add(returning("fail", errors.New("broken")), 0, OnErrorContinue)
+	p.Add(returning("drop", ErrDrop), 0, "")
+
+	for i := 0; i < 3; i++ {
+		p.Process(context.Background(), &database.Article{})
+	}
+
+	stats := p.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("Expected stats for 3 stages, got %d", len(stats))
+	}
+	expected := []StageStats{
+		{Name: "skip", Processed: 3, Skipped: 3},
+		{Name: "fail", Processed: 3, Failed: 3},
+		{Name: "drop", Processed: 3, Dropped: 3},
+	}
+	for i, want := range expected {
+		got := stats[i]
+		if got.Name != want.Name || got.Processed != want.Processed || got.Skipped != want.Skipped ||
+			got.Failed != want.Failed || got.Dropped != want.Dropped || got.TimedOut != 0 {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestPipelineTimeouts(t *testing.T) {
+	// Honours its deadline
+	waiting := &funcStage{name: "waiting", fn: func(ctx context.Context, _ *database.Article) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	// Ignores its deadline and reports success
+	ignoring := &funcStage{name: "ignoring", fn: func(context.Context, *database.Article) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}}
+
+	for _, stage := range []*funcStage{waiting, ignoring} {
+		t.Run(stage.name, func(t *testing.T) {
+			p := New()
+			p.Add(stage, 5*time.Millisecond, "")
+
+			if err := p.Process(context.Background(), &database.Article{}); err == nil || errors.Is(err, ErrDrop) {
+				t.Errorf("Expected the stage to fail, got %v", err)
+			}
+			stats := p.Stats()[0]
+			if stats.Failed != 1 || stats.TimedOut != 1 {
+				t.Errorf("Expected 1 failure timed out, got %+v", stats)
+			}
+		})
+	}
+
+	// Cancelled by the caller, which is not a stage timeout
+	t.Run("cancelled", func(t *testing.T) {
+		p := New()
+		p.Add(waiting, time.Minute, "")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := p.Process(ctx, &database.Article{}); err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if stats := p.Stats()[0]; stats.TimedOut != 0 {
+			t.Errorf("Expected no timeout, got %+v", stats)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+// StageFactory creates a stage from its options
+type StageFactory func(options map[string]string) (Stage, error)
+
+// Registry maps stage names used in pool configurations to stage factories
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]StageFactory
+}
+
+// NewRegistry creates a registry holding the built-in stages
+func NewRegistry(cfg *config.Config) *Registry {
+	r := &Registry{
+		factories: make(map[string]StageFactory),
+	}
+
+	r.Register(StageNormalize, func(map[string]string) (Stage, error) {
+		return NewNormalizeStage(), nil
+	})
+	r.Register(StageTickerTags, func(map[string]string) (Stage, error) {
+		return NewTickerTagStage(cfg.StockList.Stocks), nil
+	})
+	r.Register(StageFilter, NewFilterStage)
+
+	return r
+}
+
+// Register adds or replaces a stage factory
+func (r *Registry) Register(name string, factory StageFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[name] = factory
+}
+
+// Build creates a pipeline from the stage configuration of a pool. An empty
+// configuration yields nil, meaning articles are persisted as dequeued.
+func (r *Registry) Build(stages []config.PipelineStageConfig) (*Pipeline, error) {
+	if len(stages) == 0 {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p := New()
+	for _, stageCfg := range stages {
+		factory, ok := r.factories[stageCfg.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown pipeline stage: %s", stageCfg.Name)
+		}
+
+		stage, err := factory(stageCfg.Options)
+		if err != nil {
+			return nil, fmt.Errorf("error creating stage %s: %w", stageCfg.Name, err)
+		}
+		if err := p.Add(stage, stageCfg.Timeout, stageCfg.OnError); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
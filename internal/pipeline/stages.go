@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/database"
+)
+
+// Names of the built-in stages
+const (
+	StageNormalize  = "normalize"
+	StageTickerTags = "ticker-tags"
+	StageFilter     = "filter"
+)
+
+// whitespace matches runs of whitespace, collapsed by the normalize stage
+var whitespace = regexp.MustCompile(`\s+`)
+
+// NormalizeStage trims and collapses whitespace in titles and texts and
+// upper-cases the symbol
+type NormalizeStage struct{}
+
+// NewNormalizeStage creates a normalize stage
+func NewNormalizeStage() *NormalizeStage {
+	return &NormalizeStage{}
+}
+
+// Name identifies the stage
+func (s *NormalizeStage) Name() string {
+	return StageNormalize
+}
+
+// Process normalizes the article in place
+func (s *NormalizeStage) Process(_ context.Context, article *database.Article) error {
+	article.Title = strings.TrimSpace(whitespace.ReplaceAllString(article.Title, " "))
+	article.Text = strings.TrimSpace(whitespace.ReplaceAllString(article.Text, " "))
+	article.URL = strings.TrimSpace(article.URL)
+	article.Symbol = strings.ToUpper(strings.TrimSpace(article.Symbol))
+	return nil
+}
+
+// TickerTagStage tags an article with every tracked symbol it mentions,
+// either as a cashtag ($AAPL) or by company name, as whole words
+type TickerTagStage struct {
+	mentions []tickerMention
+}
+
+// tickerMention matches the mentions of a tracked symbol
+type tickerMention struct {
+	symbol  string
+	pattern *regexp.Regexp
+}
+
+// NewTickerTagStage creates a ticker tagging stage for the tracked stocks
+func NewTickerTagStage(stocks []config.Stock) *TickerTagStage {
+	s := &TickerTagStage{}
+	for _, stock := range stocks {
+		if stock.Symbol == "" {
+			continue
+		}
+
+		// Cashtags are case sensitive, company names are not; neither may run
+		// into a neighbouring letter or digit, so $AMDX is not AMD
+		pattern := `\$` + regexp.QuoteMeta(stock.Symbol) + `(?:[^\pL\pN_]|$)`
+		if stock.Name != "" {
+			pattern += `|(?i:(?:^|[^\pL\pN_])` + regexp.QuoteMeta(stock.Name) + `(?:[^\pL\pN_]|$))`
+		}
+		s.mentions = append(s.mentions, tickerMention{
+			symbol:  stock.Symbol,
+			pattern: regexp.MustCompile(pattern),
+		})
+	}
+	return s
+}
+
+// Name identifies the stage
+func (s *TickerTagStage) Name() string {
+	return StageTickerTags
+}
+
+// Process adds the mentioned symbols to the article's tags
+func (s *TickerTagStage) Process(_ context.Context, article *database.Article) error {
+	content := article.Title + " " + article.Text
+
+	seen := make(map[string]bool, len(article.Tags))
+	for _, tag := range article.Tags {
+		seen[tag] = true
+	}
+
+	for _, mention := range s.mentions {
+		if seen[mention.symbol] {
+			continue
+		}
+		if mention.pattern.MatchString(content) {
+			article.Tags = append(article.Tags, mention.symbol)
+			seen[mention.symbol] = true
+		}
+	}
+	return nil
+}
+
+// FilterStage drops articles that are too short or whose title or text
+// mention blocked keywords
+type FilterStage struct {
+	minTextLength int
+	blocked       []string
+}
+
+// NewFilterStage creates a filter stage. Options: "minTextLength", the
+// minimum number of characters of text, and "blockedKeywords", a comma
+// separated list of case-insensitive keywords.
+func NewFilterStage(options map[string]string) (Stage, error) {
+	s := &FilterStage{}
+
+	if value, ok := options["minTextLength"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minTextLength %q: %w", value, err)
+		}
+		s.minTextLength = n
+	}
+
+	for _, keyword := range strings.Split(options["blockedKeywords"], ",") {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			s.blocked = append(s.blocked, keyword)
+		}
+	}
+
+	return s, nil
+}
+
+// Name identifies the stage
+func (s *FilterStage) Name() string {
+	return StageFilter
+}
+
+// Process drops articles that do not pass the filter
+func (s *FilterStage) Process(_ context.Context, article *database.Article) error {
+	if utf8.RuneCountInString(article.Text) < s.minTextLength {
+		return ErrDrop
+	}
+
+	if len(s.blocked) == 0 {
+		return nil
+	}
+	title := strings.ToLower(article.Title)
+	text := strings.ToLower(article.Text)
+	for _, keyword := range s.blocked {
+		if strings.Contains(title, keyword) || strings.Contains(text, keyword) {
+			return ErrDrop
+		}
+	}
+	return nil
+}
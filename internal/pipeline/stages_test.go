@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/database"
+)
+
+func TestNormalizeStage(t *testing.T) {
+	article := database.Article{
+		Title:  "  Apple \t beats\n estimates ",
+		Text:   "\nShares   rose.\n\nAnalysts cheered. ",
+		URL:    " https://example.com/a ",
+		Symbol: " aapl",
+	}
+
+	if err := NewNormalizeStage().Process(context.Background(), &article); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if article.Title != "Apple beats estimates" {
+		t.Errorf("Expected collapsed title, got %q", article.Title)
+	}
+	if article.Text != "Shares rose. Analysts cheered." {
+		t.Errorf("Expected collapsed text, got %q", article.Text)
+	}
+	if article.URL != "https://example.com/a" || article.Symbol != "AAPL" {
+		t.Errorf("Expected trimmed URL and upper-cased symbol, got %q and %q", article.URL, article.Symbol)
+	}
+}
+
+func TestTickerTagStage(t *testing.T) {
+	stage := NewTickerTagStage([]config.Stock{
+		{Symbol: "AMD", Name: "Advanced Micro Devices"},
+		{Symbol: "AAPL", Name: "Apple"},
+		{Symbol: "BRK.B", Name: "Berkshire Hathaway"},
+		{Symbol: "F"},
+	})
+
+	tests := []struct {
+		name     string
+		title    string
+		text     string
+		tags     []string
+		expected []string
+	}{
+		{"cashtag", "$AMD rallies", "", nil, []string{"AMD"}},
+		{"cashtag at the end of a sentence", "", "Traders bought $AMD.", nil, []string{"AMD"}},
+		{"longer cashtag is another symbol", "$AMDX and $AAPLE", "", nil, nil},
+		{"cashtags are case sensitive", "$amd", "", nil, nil},
+		{"cashtag with a dot", "$BRK.B gains", "", nil, []string{"BRK.B"}},
+		{"single letter cashtag", "$F recalls", "", nil, []string{"F"}},
+		{"bare symbol is not a cashtag", "AMD rallies", "", nil, nil},
+		{"company name in any case", "", "shares of APPLE rose", nil, []string{"AAPL"}},
+		{"company name inside a word", "Pineapple prices", "Applesauce", nil, nil},
+		{"company name across whitespace", "", "Berkshire Hathaway's stake", nil, []string{"BRK.B"}},
+		{"existing tags are kept once", "$AMD and Apple", "", []string{"AMD"}, []string{"AMD", "AAPL"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := database.Article{Title: tt.title, Text: tt.text, Tags: tt.tags}
+			if err := stage.Process(context.Background(), &article); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(article.Tags) != len(tt.expected) {
+				t.Fatalf("Expected tags %v, got %v", tt.expected, article.Tags)
+			}
+			for i, tag := range tt.expected {
+				if article.Tags[i] != tag {
+					t.Errorf("Expected tags %v, got %v", tt.expected, article.Tags)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestFilterStage(t *testing.T) {
+	stage, err := NewFilterStage(map[string]string{
+		"minTextLength":   "5",
+		"blockedKeywords": " Sponsored ,, press release",
+	})
+	if err != nil {
+		t.Fatalf("Expected filter stage, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		title string
+		text  string
+		drop  bool
+	}{
+		{"long enough", "Earnings", "Revenue grew", false},
+		{"too short", "Earnings", "Up", true},
+		{"length counts characters, not bytes", "Earnings", "ñandú", false},
+		{"multi-byte text too short", "Earnings", "ñañá", true},
+		{"blocked keyword in the title", "SPONSORED: buy now", "Revenue grew", true},
+		{"blocked keyword in the text", "Earnings", "This is a Press Release from the company", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := database.Article{Title: tt.title, Text: tt.text}
+			err := stage.Process(context.Background(), &article)
+			if tt.drop && err != ErrDrop {
+				t.Errorf("Expected ErrDrop, got %v", err)
+			}
+			if !tt.drop && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+
+	if _, err := NewFilterStage(map[string]string{"minTextLength": "long"}); err == nil {
+		t.Errorf("Expected an error for an invalid minTextLength")
+	}
+}
@@ -0,0 +1,66 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// renewLockScript extends a lock's expiry only if it is still held by the caller
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLockScript deletes a lock only if it is still held by the caller
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLock tries to take a lock for owner that expires after ttl.
+// Returns false if the lock is already held by someone else.
+func (r *RedisClient) AcquireLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock '%s': %w", key, err)
+	}
+	return ok, nil
+}
+
+// RenewLock extends the expiry of a lock held by owner.
+// Returns false if the lock expired or is now held by someone else.
+func (r *RedisClient) RenewLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	res, err := renewLockScript.Run(ctx, r.client, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew lock '%s': %w", key, err)
+	}
+	return res == 1, nil
+}
+
+// ReleaseLock releases a lock if it is still held by owner
+func (r *RedisClient) ReleaseLock(ctx context.Context, key, owner string) error {
+	if err := releaseLockScript.Run(ctx, r.client, []string{key}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release lock '%s': %w", key, err)
+	}
+	return nil
+}
+
+// LockHolder returns the current owner of a lock, or an empty string if nobody holds it
+func (r *RedisClient) LockHolder(ctx context.Context, key string) (string, error) {
+	owner, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read lock '%s': %w", key, err)
+	}
+	return owner, nil
+}
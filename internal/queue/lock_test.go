@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+// newTestRedisClient returns a client connected to an in-memory Redis server
+func newTestRedisClient(t *testing.T) (*RedisClient, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return &RedisClient{client: client}, server
+}
+
+func TestLockIsHeldByOneOwner(t *testing.T) {
+	r, _ := newTestRedisClient(t)
+	ctx := context.Background()
+
+	if ok, err := r.AcquireLock(ctx, "leader", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("Expected a to acquire the lock, got %v (%v)", ok, err)
+	}
+	if ok, err := r.AcquireLock(ctx, "leader", "b", time.Minute); err != nil || ok {
+		t.Errorf("Expected b to be refused the lock, got %v (%v)", ok, err)
+	}
+	if ok, err := r.RenewLock(ctx, "leader", "b", time.Minute); err != nil || ok {
+		t.Errorf("Expected b not to renew a's lock, got %v (%v)", ok, err)
+	}
+
+	// Releasing a lock held by someone else leaves it in place
+	if err := r.ReleaseLock(ctx, "leader", "b"); err != nil {
+		t.Fatalf("Expected no error releasing, got %v", err)
+	}
+	if holder, err := r.LockHolder(ctx, "leader"); err != nil || holder != "a" {
+		t.Errorf("Expected a to hold the lock, got %q (%v)", holder, err)
+	}
+
+	if err := r.ReleaseLock(ctx, "leader", "a"); err != nil {
+		t.Fatalf("Expected no error releasing, got %v", err)
+	}
+	if holder, err := r.LockHolder(ctx, "leader"); err != nil || holder != "" {
+		t.Errorf("Expected nobody to hold the lock, got %q (%v)", holder, err)
+	}
+}
+
+func TestLockExpiresUnlessRenewed(t *testing.T) {
+	r, server := newTestRedisClient(t)
+	ctx := context.Background()
+
+	if ok, err := r.AcquireLock(ctx, "leader", "a", 15*time.Second); err != nil || !ok {
+		t.Fatalf("Expected a to acquire the lock, got %v (%v)", ok, err)
+	}
+
+	server.FastForward(10 * time.Second)
+	if ok, err := r.RenewLock(ctx, "leader", "a", 15*time.Second); err != nil || !ok {
+		t.Fatalf("Expected a to renew its lock, got %v (%v)", ok, err)
+	}
+	server.FastForward(10 * time.Second)
+	if holder, _ := r.LockHolder(ctx, "leader"); holder != "a" {
+		t.Errorf("Expected the renewed lock to be held by a, got %q", holder)
+	}
+
+	server.FastForward(5 * time.Second)
+	if ok, err := r.RenewLock(ctx, "leader", "a", 15*time.Second); err != nil || ok {
+		t.Errorf("Expected an expired lock not to be renewed, got %v (%v)", ok, err)
+	}
+	if ok, err := r.AcquireLock(ctx, "leader", "b", 15*time.Second); err != nil || !ok {
+		t.Errorf("Expected b to acquire the expired lock, got %v (%v)", ok, err)
+	}
+}
+
+func TestLockErrors(t *testing.T) {
+	r, server := newTestRedisClient(t)
+	server.Close()
+
+	if _, err := r.AcquireLock(context.Background(), "leader", "a", time.Minute); err == nil {
+		t.Errorf("Expected an error when Redis is unreachable")
+	}
+}
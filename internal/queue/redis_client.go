@@ -45,6 +45,11 @@ func (r *RedisClient) Close() error {
 	return r.client.Close()
 }
 
+// Ping checks that Redis is reachable
+func (r *RedisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 // Enqueue adds an item to a Redis queue
 func (r *RedisClient) Enqueue(ctx context.Context, queueName string, message interface{}) error {
 	data, err := json.Marshal(message)
@@ -60,6 +65,41 @@ func (r *RedisClient) Enqueue(ctx context.Context, queueName string, message int
 	return nil
 }
 
+// enqueueIfEmptyScript pushes every message onto a queue only if it is empty,
+// in one step so concurrent producers cannot both fill it
+var enqueueIfEmptyScript = redis.NewScript(`
+if redis.call("LLEN", KEYS[1]) > 0 then
+	return 0
+end
+for i = 1, #ARGV do
+	redis.call("RPUSH", KEYS[1], ARGV[i])
+end
+return 1
+`)
+
+// EnqueueIfEmpty adds items to a Redis queue only if it is empty.
+// Returns false, adding nothing, when the queue already had items.
+func (r *RedisClient) EnqueueIfEmpty(ctx context.Context, queueName string, messages []interface{}) (bool, error) {
+	if len(messages) == 0 {
+		return false, nil
+	}
+
+	args := make([]interface{}, len(messages))
+	for i, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal task: %w", err)
+		}
+		args[i] = data
+	}
+
+	added, err := enqueueIfEmptyScript.Run(ctx, r.client, []string{queueName}, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to push tasks to queue '%s': %w", queueName, err)
+	}
+	return added == 1, nil
+}
+
 // Dequeue retrieves an item from a Redis queue with a timeout
 func (r *RedisClient) Dequeue(ctx context.Context, queueName string, timeoutSeconds int) ([]byte, error) {
 	timeout := time.Duration(timeoutSeconds) * time.Second
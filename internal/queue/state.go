@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/guillermoballester/propagatorGo/internal/model"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// cursorKeyPrefix namespaces the saved round-robin positions of each source
+	cursorKeyPrefix = "propagator:cursor:"
+
+	// symbolStateKeyPrefix namespaces the hash of symbol states of each source
+	symbolStateKeyPrefix = "propagator:symbol-state:"
+)
+
+// SaveCursor stores the symbol a source's round-robin should resume from
+func (r *RedisClient) SaveCursor(ctx context.Context, source, symbol string) error {
+	if err := r.client.Set(ctx, cursorKeyPrefix+source, symbol, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save cursor for source '%s': %w", source, err)
+	}
+	return nil
+}
+
+// LoadCursor returns the saved symbol for a source, or an empty string if none was saved
+func (r *RedisClient) LoadCursor(ctx context.Context, source string) (string, error) {
+	symbol, err := r.client.Get(ctx, cursorKeyPrefix+source).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load cursor for source '%s': %w", source, err)
+	}
+	return symbol, nil
+}
+
+// SaveSymbolState stores the scrape state of a symbol for its source
+func (r *RedisClient) SaveSymbolState(ctx context.Context, state model.SymbolState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state of %s: %w", state.Symbol, err)
+	}
+
+	if err := r.client.HSet(ctx, symbolStateKeyPrefix+state.Source, state.Symbol, data).Err(); err != nil {
+		return fmt.Errorf("failed to save state of %s for source '%s': %w", state.Symbol, state.Source, err)
+	}
+	return nil
+}
+
+// LoadSymbolStates returns the saved scrape state of every symbol of a source
+func (r *RedisClient) LoadSymbolStates(ctx context.Context, source string) ([]model.SymbolState, error) {
+	values, err := r.client.HGetAll(ctx, symbolStateKeyPrefix+source).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load symbol states for source '%s': %w", source, err)
+	}
+
+	states := make([]model.SymbolState, 0, len(values))
+	for symbol, data := range values {
+		var state model.SymbolState
+		if err := json.Unmarshal([]byte(data), &state); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal state of %s: %w", symbol, err)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
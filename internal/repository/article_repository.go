@@ -4,7 +4,10 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/guillermoballester/propagatorGo/internal/database"
 	"github.com/guillermoballester/propagatorGo/internal/database/sqlc"
@@ -33,69 +36,427 @@ func (r *ArticleRepository) SaveArticle(ctx context.Context, article database.Ar
 		SiteName:  article.SiteName,
 		ScrapedAt: article.ScrapedAt,
 		Symbol:    article.Symbol,
+		Tags:      articleTags(article),
 	}
 
 	_, err := r.queries.CreateArticle(ctx, params)
 	return err
 }
 
-// GetArticlesBySymbol retrieves articles for a specific stock symbol
-// Returns articles, total count, and error
-func (r *ArticleRepository) GetArticlesBySymbol(ctx context.Context, symbol string) ([]database.Article, int, error) {
-	dbArticles, err := r.queries.GetArticleBySymbol(ctx, symbol)
+// SaveOutcome describes what a bulk save did with a single article
+type SaveOutcome string
+
+const (
+	OutcomeInserted  SaveOutcome = "inserted"
+	OutcomeUpdated   SaveOutcome = "updated"
+	OutcomeUnchanged SaveOutcome = "unchanged"
+)
+
+// SaveResult reports the outcome of one article in a bulk save
+type SaveResult struct {
+	URL     string
+	ID      int64 // Zero when the row was left unchanged
+	Outcome SaveOutcome
+}
+
+// maxBulkRows bounds the rows sent in a single upsert statement
+const maxBulkRows = 1000
+
+// SaveArticles saves or updates many articles using multi-row upserts inside a
+// single transaction. Articles sharing a URL are collapsed (last one wins), and
+// rows whose content did not change are left untouched and reported as unchanged.
+// Results are returned in the order each URL first appears in the input.
+func (r *ArticleRepository) SaveArticles(ctx context.Context, articles []database.Article) ([]SaveResult, error) {
+	if len(articles) == 0 {
+		return []SaveResult{}, nil
+	}
+
+	// Deduplicate by URL, as ON CONFLICT cannot touch the same row twice in one statement
+	order := make([]string, 0, len(articles))
+	byURL := make(map[string]database.Article, len(articles))
+	for _, article := range articles {
+		if _, seen := byURL[article.URL]; !seen {
+			order = append(order, article.URL)
+		}
+		byURL[article.URL] = article
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, 0, err
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	queries := r.queries.WithTx(tx)
+	touched := make(map[string]SaveResult, len(order))
+	for start := 0; start < len(order); start += maxBulkRows {
+		end := start + maxBulkRows
+		if end > len(order) {
+			end = len(order)
+		}
+
+		chunk := make([]database.Article, 0, end-start)
+		for _, url := range order[start:end] {
+			chunk = append(chunk, byURL[url])
+		}
+
+		if err := upsertArticles(ctx, queries, chunk, touched); err != nil {
+			return nil, err
+		}
 	}
 
-	// Get total count for pagination
-	countQuery := `SELECT COUNT(*) FROM articles WHERE symbol = $1`
-	var total int
-	err = r.db.QueryRowContext(ctx, countQuery, symbol).Scan(&total)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing articles: %w", err)
+	}
+
+	results := make([]SaveResult, len(order))
+	for i, url := range order {
+		result, ok := touched[url]
+		if !ok {
+			result = SaveResult{URL: url, Outcome: OutcomeUnchanged}
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+// upsertArticles runs one multi-row upsert and records every row it inserted or updated
+func upsertArticles(ctx context.Context, queries *sqlc.Queries, articles []database.Article, touched map[string]SaveResult) error {
+	params := sqlc.UpsertArticlesParams{
+		Titles:     make([]string, len(articles)),
+		Urls:       make([]string, len(articles)),
+		Texts:      make([]string, len(articles)),
+		SiteNames:  make([]string, len(articles)),
+		ScrapedAts: make([]time.Time, len(articles)),
+		Symbols:    make([]string, len(articles)),
+		Tags:       make([]string, len(articles)),
+	}
+	for i, article := range articles {
+		params.Titles[i] = article.Title
+		params.Urls[i] = article.URL
+		params.Texts[i] = article.Text // Empty texts are stored as NULL
+		params.SiteNames[i] = article.SiteName
+		params.ScrapedAts[i] = article.ScrapedAt
+		params.Symbols[i] = article.Symbol
+		params.Tags[i] = strings.Join(articleTags(article), ",")
+	}
+
+	rows, err := queries.UpsertArticles(ctx, params)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error counting articles: %w", err)
+		return fmt.Errorf("error upserting articles: %w", err)
+	}
+
+	for _, row := range rows {
+		outcome := OutcomeUpdated
+		if row.Inserted {
+			outcome = OutcomeInserted
+		}
+		touched[row.Url] = SaveResult{URL: row.Url, ID: int64(row.ID), Outcome: outcome}
 	}
 
-	return mapSQLCArticlesToModels(dbArticles), total, nil
+	return nil
 }
 
-// GetArticlesBySite retrieves articles from a specific site
-// Returns articles, total count, and error
-func (r *ArticleRepository) GetArticlesBySite(ctx context.Context, siteName string) ([]database.Article, int, error) {
-	dbArticles, err := r.queries.GetArticleBySite(ctx, siteName)
+// ArticlePage is one page of articles, newest first
+type ArticlePage struct {
+	Articles []database.Article
+	Next     *ArticleCursor // Nil when there are no older articles
+	Prev     *ArticleCursor // Nil when there are no newer articles
+}
+
+// ListArticlesBySymbol retrieves a page of up to limit articles for a stock
+// symbol, starting from cursor, or from the newest article when it is nil
+func (r *ArticleRepository) ListArticlesBySymbol(ctx context.Context, symbol string, cursor *ArticleCursor, limit int) (ArticlePage, error) {
+	// One extra row tells whether there is a further page
+	fetch := int32(limit + 1)
+
+	var articles []database.Article
+	var err error
+	switch {
+	case cursor == nil:
+		var rows []sqlc.ListArticlesBySymbolRow
+		rows, err = r.queries.ListArticlesBySymbol(ctx, sqlc.ListArticlesBySymbolParams{
+			Symbol: symbol,
+			Limit:  fetch,
+		})
+		articles = mapArticleRows(rows)
+	case cursor.Backward:
+		var rows []sqlc.ListArticlesBySymbolAfterRow
+		rows, err = r.queries.ListArticlesBySymbolAfter(ctx, sqlc.ListArticlesBySymbolAfterParams{
+			Symbol:    symbol,
+			ScrapedAt: cursor.ScrapedAt,
+			ID:        int32(cursor.ID),
+			Limit:     fetch,
+		})
+		articles = mapArticleRows(rows)
+	default:
+		var rows []sqlc.ListArticlesBySymbolBeforeRow
+		rows, err = r.queries.ListArticlesBySymbolBefore(ctx, sqlc.ListArticlesBySymbolBeforeParams{
+			Symbol:    symbol,
+			ScrapedAt: cursor.ScrapedAt,
+			ID:        int32(cursor.ID),
+			Limit:     fetch,
+		})
+		articles = mapArticleRows(rows)
+	}
 	if err != nil {
-		return nil, 0, err
+		return ArticlePage{}, fmt.Errorf("error listing articles: %w", err)
 	}
 
-	// Get total count for pagination
-	countQuery := `SELECT COUNT(*) FROM articles WHERE site_name = $1`
-	var total int
-	err = r.db.QueryRowContext(ctx, countQuery, siteName).Scan(&total)
+	return newArticlePage(articles, cursor, limit), nil
+}
+
+// ListArticlesBySite retrieves a page of up to limit articles from a site,
+// starting from cursor, or from the newest article when it is nil
+func (r *ArticleRepository) ListArticlesBySite(ctx context.Context, siteName string, cursor *ArticleCursor, limit int) (ArticlePage, error) {
+	// One extra row tells whether there is a further page
+	fetch := int32(limit + 1)
+
+	var articles []database.Article
+	var err error
+	switch {
+	case cursor == nil:
+		var rows []sqlc.ListArticlesBySiteRow
+		rows, err = r.queries.ListArticlesBySite(ctx, sqlc.ListArticlesBySiteParams{
+			SiteName: siteName,
+			Limit:    fetch,
+		})
+		articles = mapArticleRows(rows)
+	case cursor.Backward:
+		var rows []sqlc.ListArticlesBySiteAfterRow
+		rows, err = r.queries.ListArticlesBySiteAfter(ctx, sqlc.ListArticlesBySiteAfterParams{
+			SiteName:  siteName,
+			ScrapedAt: cursor.ScrapedAt,
+			ID:        int32(cursor.ID),
+			Limit:     fetch,
+		})
+		articles = mapArticleRows(rows)
+	default:
+		var rows []sqlc.ListArticlesBySiteBeforeRow
+		rows, err = r.queries.ListArticlesBySiteBefore(ctx, sqlc.ListArticlesBySiteBeforeParams{
+			SiteName:  siteName,
+			ScrapedAt: cursor.ScrapedAt,
+			ID:        int32(cursor.ID),
+			Limit:     fetch,
+		})
+		articles = mapArticleRows(rows)
+	}
 	if err != nil {
-		return nil, 0, fmt.Errorf("error counting articles: %w", err)
+		return ArticlePage{}, fmt.Errorf("error listing articles: %w", err)
 	}
 
-	return mapSQLCArticlesToModels(dbArticles), total, nil
+	return newArticlePage(articles, cursor, limit), nil
 }
 
-// Helper function to map sqlc Article model to our domain model
-func mapSQLCArticleToModel(dbArticle sqlc.Article) database.Article {
-	return database.Article{
-		ID:        int64(dbArticle.ID),
-		Title:     dbArticle.Title,
-		URL:       dbArticle.Url,
-		Text:      dbArticle.Text.String,
-		SiteName:  dbArticle.SiteName,
-		ScrapedAt: dbArticle.ScrapedAt,
-		CreatedAt: dbArticle.CreatedAt,
-		Symbol:    dbArticle.Symbol,
+// newArticlePage builds a page from up to limit+1 articles read from cursor,
+// newest first when reading forward and oldest first when reading backward
+func newArticlePage(articles []database.Article, cursor *ArticleCursor, limit int) ArticlePage {
+	more := len(articles) > limit
+	if more {
+		articles = articles[:limit]
+	}
+
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		for i, j := 0, len(articles)-1; i < j; i, j = i+1, j-1 {
+			articles[i], articles[j] = articles[j], articles[i]
+		}
+	}
+
+	page := ArticlePage{Articles: articles}
+	if len(articles) == 0 {
+		// Nothing past the cursor, so the only way is back
+		if backward {
+			page.Next = articleCursor(cursor.ScrapedAt, cursor.ID, false)
+		} else if cursor != nil {
+			page.Prev = articleCursor(cursor.ScrapedAt, cursor.ID, true)
+		}
+		return page
 	}
+
+	first, last := articles[0], articles[len(articles)-1]
+	if (backward && more) || (!backward && cursor != nil) {
+		page.Prev = articleCursor(first.ScrapedAt, first.ID, true)
+	}
+	if (!backward && more) || backward {
+		page.Next = articleCursor(last.ScrapedAt, last.ID, false)
+	}
+	return page
 }
 
-// Helper function to map a slice of sqlc Articles to our domain models
-func mapSQLCArticlesToModels(dbArticles []sqlc.Article) []database.Article {
-	articles := make([]database.Article, len(dbArticles))
-	for i, dbArticle := range dbArticles {
-		articles[i] = mapSQLCArticleToModel(dbArticle)
+// CountArticlesBySymbol counts the articles for a stock symbol. Unless exact
+// is set, the query planner's estimate is returned, which costs the same
+// however many articles there are.
+func (r *ArticleRepository) CountArticlesBySymbol(ctx context.Context, symbol string, exact bool) (int, error) {
+	if !exact {
+		return r.estimateRows(ctx, `SELECT 1 FROM articles WHERE symbol = $1::text`, symbol)
+	}
+
+	total, err := r.queries.CountArticlesBySymbol(ctx, symbol)
+	if err != nil {
+		return 0, fmt.Errorf("error counting articles: %w", err)
+	}
+	return int(total), nil
+}
+
+// CountArticlesBySite counts the articles from a site, estimating the count
+// like CountArticlesBySymbol unless exact is set
+func (r *ArticleRepository) CountArticlesBySite(ctx context.Context, siteName string, exact bool) (int, error) {
+	if !exact {
+		return r.estimateRows(ctx, `SELECT 1 FROM articles WHERE site_name = $1::text`, siteName)
+	}
+
+	total, err := r.queries.CountArticlesBySite(ctx, siteName)
+	if err != nil {
+		return 0, fmt.Errorf("error counting articles: %w", err)
+	}
+	return int(total), nil
+}
+
+// estimateRows returns how many rows the query planner expects a query to
+// return, without running it
+func (r *ArticleRepository) estimateRows(ctx context.Context, query string, args ...interface{}) (int, error) {
+	var plan []byte
+	if err := r.db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&plan); err != nil {
+		return 0, fmt.Errorf("error estimating articles: %w", err)
+	}
+
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &explained); err != nil {
+		return 0, fmt.Errorf("error reading query plan: %w", err)
+	}
+	if len(explained) == 0 {
+		return 0, fmt.Errorf("error reading query plan: no plan returned")
+	}
+	return int(explained[0].Plan.Rows), nil
+}
+
+// ArticleSearch describes a full-text search over articles. Query takes web
+// search syntax: quoted phrases, OR, and a leading - to exclude a word. Empty
+// filters match every article.
+type ArticleSearch struct {
+	Query    string
+	Symbol   string
+	SiteName string
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+	Offset   int
+}
+
+// SearchResult is an article matching a search, along with its rank and a
+// snippet of its HTML-escaped text with the matching words wrapped in <mark>
+// tags
+type SearchResult struct {
+	Article database.Article
+	Rank    float64
+	Snippet string
+}
+
+// SearchArticles retrieves the articles matching a search, best matches first
+// Returns results, total count, and error
+func (r *ArticleRepository) SearchArticles(ctx context.Context, search ArticleSearch) ([]SearchResult, int, error) {
+	rows, err := r.queries.SearchArticles(ctx, sqlc.SearchArticlesParams{
+		Query:    search.Query,
+		Symbol:   nullString(search.Symbol),
+		SiteName: nullString(search.SiteName),
+		Since:    nullTime(search.Since),
+		Until:    nullTime(search.Until),
+		Limit:    int32(search.Limit),
+		Offset:   int32(search.Offset),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error searching articles: %w", err)
+	}
+
+	total, err := r.queries.CountSearchArticles(ctx, sqlc.CountSearchArticlesParams{
+		Query:    search.Query,
+		Symbol:   nullString(search.Symbol),
+		SiteName: nullString(search.SiteName),
+		Since:    nullTime(search.Since),
+		Until:    nullTime(search.Until),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting search results: %w", err)
+	}
+
+	results := make([]SearchResult, len(rows))
+	for i, row := range rows {
+		results[i] = SearchResult{
+			Article: database.Article{
+				ID:        int64(row.ID),
+				Title:     row.Title,
+				URL:       row.Url,
+				SiteName:  row.SiteName,
+				Symbol:    row.Symbol,
+				Tags:      row.Tags,
+				ScrapedAt: row.ScrapedAt,
+				CreatedAt: row.CreatedAt,
+			},
+			Rank:    float64(row.Rank),
+			Snippet: row.Snippet,
+		}
+	}
+
+	return results, int(total), nil
+}
+
+// articleRow holds the columns the article queries select. sqlc generates a
+// row type per query, each convertible to this one.
+type articleRow struct {
+	ID        int32
+	Title     string
+	Url       string
+	Text      sql.NullString
+	SiteName  string
+	ScrapedAt time.Time
+	CreatedAt time.Time
+	Symbol    string
+	Tags      []string
+}
+
+// articleRows lists the row types of the article queries
+type articleRows interface {
+	sqlc.ListArticlesBySymbolRow | sqlc.ListArticlesBySymbolAfterRow | sqlc.ListArticlesBySymbolBeforeRow |
+		sqlc.ListArticlesBySiteRow | sqlc.ListArticlesBySiteAfterRow | sqlc.ListArticlesBySiteBeforeRow
+}
+
+// mapArticleRows maps the rows of an article query to our domain models
+func mapArticleRows[T articleRows](rows []T) []database.Article {
+	articles := make([]database.Article, len(rows))
+	for i, row := range rows {
+		articles[i] = mapArticleRow(articleRow(row))
 	}
 	return articles
 }
+
+// mapArticleRow maps an article row to our domain model
+func mapArticleRow(row articleRow) database.Article {
+	return database.Article{
+		ID:        int64(row.ID),
+		Title:     row.Title,
+		URL:       row.Url,
+		Text:      row.Text.String,
+		SiteName:  row.SiteName,
+		ScrapedAt: row.ScrapedAt,
+		CreatedAt: row.CreatedAt,
+		Symbol:    row.Symbol,
+		Tags:      row.Tags,
+	}
+}
+
+// articleTags returns the tags to store for an article; the column does not
+// take NULL, so untagged articles get an empty list
+func articleTags(article database.Article) []string {
+	if article.Tags == nil {
+		return []string{}
+	}
+	return article.Tags
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/database"
+)
+
+// fakeResult is what the fake database answers to a query
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+}
+
+// fakeDB answers queries by their sqlc name and records the arguments they
+// were given
+type fakeDB struct {
+	results   map[string]fakeResult
+	args      map[string][]driver.Value
+	committed bool
+}
+
+// open returns a database connected to the fake
+func (f *fakeDB) open() *sql.DB {
+	f.args = make(map[string][]driver.Value)
+	return sql.OpenDB(f)
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+func (f *fakeDB) Prepare(string) (driver.Stmt, error)          { return nil, errors.New("not supported") }
+func (f *fakeDB) Close() error                                 { return nil }
+func (f *fakeDB) Begin() (driver.Tx, error)                    { return f, nil }
+func (f *fakeDB) Commit() error                                { f.committed = true; return nil }
+func (f *fakeDB) Rollback() error                              { return nil }
+
+func (f *fakeDB) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	for name, result := range f.results {
+		if !strings.HasPrefix(query, "-- name: "+name+" ") {
+			continue
+		}
+
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		f.args[name] = values
+
+		if result.err != nil {
+			return nil, result.err
+		}
+		return &fakeRows{columns: result.columns, rows: result.rows}, nil
+	}
+	return nil, errors.New("unexpected query")
+}
+
+// fakeRows iterates over the rows of a fake result
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSearchArticles(t *testing.T) {
+	scrapedAt := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "title", "url", "site_name", "symbol", "tags", "scraped_at", "created_at", "rank", "snippet"}
+
+	db := &fakeDB{results: map[string]fakeResult{
+		"SearchArticles": {columns: columns, rows: [][]driver.Value{
+			{int64(7), "Apple beats earnings", "https://news.example/7", "yahoo", "AAPL", []byte("{AAPL}"), scrapedAt, scrapedAt, 0.61,
+				"<mark>Apple</mark> &lt;b&gt;beats&lt;/b&gt; estimates"},
+			{int64(3), "Apple supplier results", "https://news.example/3", "yahoo", "AAPL", []byte("{}"), scrapedAt, scrapedAt, 0.2,
+				"<mark>Apple</mark> supplier"},
+		}},
+		"CountSearchArticles": {columns: []string{"count"}, rows: [][]driver.Value{{int64(12)}}},
+	}}
+	repo := NewArticleRepository(db.open())
+
+	results, total, err := repo.SearchArticles(context.Background(), ArticleSearch{
+		Query:  "apple earnings",
+		Symbol: "AAPL",
+		Since:  since,
+		Limit:  10,
+		Offset: 20,
+	})
+	if err != nil {
+		t.Fatalf("Expected the search to succeed, got %v", err)
+	}
+
+	// Filters left empty are passed as NULL so they match every article
+	expected := []driver.Value{"apple earnings", "AAPL", nil, since, nil, int64(20), int64(10)}
+	if args := db.args["SearchArticles"]; !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected search arguments %v, got %v", expected, args)
+	}
+	if args := db.args["CountSearchArticles"]; !reflect.DeepEqual(args, expected[:5]) {
+		t.Errorf("Expected count arguments %v, got %v", expected[:5], args)
+	}
+
+	if total != 12 || len(results) != 2 {
+		t.Fatalf("Expected 2 of 12 results, got %d of %d", len(results), total)
+	}
+	first := results[0]
+	if first.Article.ID != 7 || first.Article.URL != "https://news.example/7" || !first.Article.ScrapedAt.Equal(scrapedAt) ||
+		!reflect.DeepEqual(first.Article.Tags, []string{"AAPL"}) {
+		t.Errorf("Expected article 7, got %+v", first.Article)
+	}
+	if first.Rank < 0.6 || first.Rank > 0.62 || first.Snippet != "<mark>Apple</mark> &lt;b&gt;beats&lt;/b&gt; estimates" {
+		t.Errorf("Expected rank 0.61 and the snippet as returned, got %v and %q", first.Rank, first.Snippet)
+	}
+}
+
+func TestSearchArticlesError(t *testing.T) {
+	failure := errors.New("connection reset")
+	db := &fakeDB{results: map[string]fakeResult{"SearchArticles": {err: failure}}}
+	repo := NewArticleRepository(db.open())
+
+	_, _, err := repo.SearchArticles(context.Background(), ArticleSearch{Query: "apple", Limit: 10})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the query error, got %v", err)
+	}
+}
+
+func TestListArticlesBySymbol(t *testing.T) {
+	scrapedAt := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	columns := []string{"id", "title", "url", "text", "site_name", "scraped_at", "created_at", "symbol", "tags"}
+
+	db := &fakeDB{results: map[string]fakeResult{
+		"ListArticlesBySymbolBefore": {columns: columns, rows: [][]driver.Value{
+			{int64(8), "Apple beats earnings", "https://news.example/8", "Revenue rose", "yahoo", scrapedAt, scrapedAt, "AAPL", []byte("{AAPL,TSM}")},
+			{int64(7), "Apple supplier results", "https://news.example/7", nil, "yahoo", scrapedAt, scrapedAt, "AAPL", []byte("{}")},
+		}},
+	}}
+	repo := NewArticleRepository(db.open())
+
+	page, err := repo.ListArticlesBySymbol(context.Background(), "AAPL", articleCursor(scrapedAt, 9, false), 5)
+	if err != nil {
+		t.Fatalf("Expected the articles to be listed, got %v", err)
+	}
+
+	expected := []driver.Value{"AAPL", scrapedAt, int64(9), int64(6)}
+	if args := db.args["ListArticlesBySymbolBefore"]; !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected arguments %v, got %v", expected, args)
+	}
+	if len(page.Articles) != 2 || page.Articles[0].Text != "Revenue rose" || page.Articles[1].Text != "" {
+		t.Errorf("Expected articles 8 and 7 with their text, got %+v", page.Articles)
+	}
+	if len(page.Articles) == 2 && (!reflect.DeepEqual(page.Articles[0].Tags, []string{"AAPL", "TSM"}) || len(page.Articles[1].Tags) != 0) {
+		t.Errorf("Expected the stored tags to be read back, got %v and %v", page.Articles[0].Tags, page.Articles[1].Tags)
+	}
+}
+
+func TestSaveArticleStoresTags(t *testing.T) {
+	scrapedAt := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	columns := []string{"id", "title", "url", "text", "site_name", "scraped_at", "created_at", "symbol", "tags"}
+	db := &fakeDB{results: map[string]fakeResult{
+		"CreateArticle": {columns: columns, rows: [][]driver.Value{
+			{int64(1), "Apple and TSMC", "https://news.example/1", nil, "yahoo", scrapedAt, scrapedAt, "AAPL", []byte("{AAPL,TSM}")},
+		}},
+	}}
+	repo := NewArticleRepository(db.open())
+
+	tests := []struct {
+		tags     []string
+		expected string
+	}{
+		{[]string{"AAPL", "TSM"}, `{"AAPL","TSM"}`},
+		{nil, "{}"},
+	}
+
+	for _, tt := range tests {
+		article := database.Article{Title: "Apple and TSMC", URL: "https://news.example/1", SiteName: "yahoo",
+			ScrapedAt: scrapedAt, Symbol: "AAPL", Tags: tt.tags}
+		if err := repo.SaveArticle(context.Background(), article); err != nil {
+			t.Fatalf("Expected the article to be saved, got %v", err)
+		}
+
+		args := db.args["CreateArticle"]
+		if len(args) != 7 || args[6] != tt.expected {
+			t.Errorf("Expected tags %v to be stored as %s, got arguments %v", tt.tags, tt.expected, args)
+		}
+	}
+}
+
+func TestSaveArticlesReportsOutcomePerRow(t *testing.T) {
+	scrapedAt := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	db := &fakeDB{results: map[string]fakeResult{
+		// The unchanged article is not returned by the upsert
+		"UpsertArticles": {columns: []string{"id", "url", "inserted"}, rows: [][]driver.Value{
+			{int64(11), "https://news.example/1", true},
+			{int64(4), "https://news.example/2", false},
+		}},
+	}}
+	repo := NewArticleRepository(db.open())
+
+	articles := []database.Article{
+		{Title: "Apple beats", URL: "https://news.example/1", SiteName: "yahoo", ScrapedAt: scrapedAt, Symbol: "AAPL",
+			Tags: []string{"AAPL", "TSM"}},
+		{Title: "Apple supplier", URL: "https://news.example/2", Text: "Revenue rose", SiteName: "yahoo", ScrapedAt: scrapedAt, Symbol: "AAPL"},
+		{Title: "Apple beats estimates", URL: "https://news.example/1", SiteName: "yahoo", ScrapedAt: scrapedAt, Symbol: "AAPL",
+			Tags: []string{"AAPL", "TSM"}},
+		{Title: "Apple flat", URL: "https://news.example/3", SiteName: "yahoo", ScrapedAt: scrapedAt, Symbol: "AAPL"},
+	}
+	results, err := repo.SaveArticles(context.Background(), articles)
+	if err != nil {
+		t.Fatalf("Expected the articles to be saved, got %v", err)
+	}
+	if !db.committed {
+		t.Errorf("Expected the transaction to be committed")
+	}
+
+	expected := []SaveResult{
+		{URL: "https://news.example/1", ID: 11, Outcome: OutcomeInserted},
+		{URL: "https://news.example/2", ID: 4, Outcome: OutcomeUpdated},
+		{URL: "https://news.example/3", Outcome: OutcomeUnchanged},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected results %v, got %v", expected, results)
+	}
+
+	// Duplicated URLs are sent once, with the last version of the article
+	args := db.args["UpsertArticles"]
+	if len(args) != 7 {
+		t.Fatalf("Expected 7 array arguments, got %v", args)
+	}
+	sent := []driver.Value{args[0], args[1], args[2], args[6]}
+	expectedSent := []driver.Value{
+		`{"Apple beats estimates","Apple supplier","Apple flat"}`,
+		`{"https://news.example/1","https://news.example/2","https://news.example/3"}`,
+		`{"","Revenue rose",""}`,
+		`{"AAPL,TSM","",""}`,
+	}
+	if !reflect.DeepEqual(sent, expectedSent) {
+		t.Errorf("Expected titles, URLs, texts and tags %v, got %v", expectedSent, sent)
+	}
+}
+
+func TestSaveArticlesError(t *testing.T) {
+	failure := errors.New("value too long for type character varying(255)")
+	db := &fakeDB{results: map[string]fakeResult{"UpsertArticles": {err: failure}}}
+	repo := NewArticleRepository(db.open())
+
+	_, err := repo.SaveArticles(context.Background(), []database.Article{{URL: "https://news.example/1"}})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected the upsert error, got %v", err)
+	}
+	if db.committed {
+		t.Errorf("Expected the transaction not to be committed")
+	}
+}
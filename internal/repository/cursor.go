@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a cursor token cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ArticleCursor marks a position in a list of articles ordered newest first.
+// Pages read forward hold the articles after it, that is older ones, and
+// pages read backward the articles before it.
+type ArticleCursor struct {
+	ScrapedAt time.Time
+	ID        int64
+	Backward  bool
+}
+
+// cursorToken is the encoded form of a cursor
+type cursorToken struct {
+	ScrapedAt time.Time `json:"t"`
+	ID        int64     `json:"id"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// Encode returns the cursor as an opaque token
+func (c ArticleCursor) Encode() string {
+	data, _ := json.Marshal(cursorToken(c))
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeArticleCursor parses a token returned by ArticleCursor.Encode
+func DecodeArticleCursor(token string) (ArticleCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ArticleCursor{}, ErrInvalidCursor
+	}
+
+	// Article IDs are 32-bit in the database, so larger IDs would wrap around
+	var t cursorToken
+	if err := json.Unmarshal(data, &t); err != nil || t.ID <= 0 || t.ID > math.MaxInt32 || t.ScrapedAt.IsZero() {
+		return ArticleCursor{}, ErrInvalidCursor
+	}
+	return ArticleCursor(t), nil
+}
+
+// articleCursor returns the cursor positioned at an article
+func articleCursor(scrapedAt time.Time, id int64, backward bool) *ArticleCursor {
+	return &ArticleCursor{ScrapedAt: scrapedAt, ID: id, Backward: backward}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/database"
+)
+
+func TestArticleCursorRoundTrip(t *testing.T) {
+	c := ArticleCursor{
+		ScrapedAt: time.Date(2026, 3, 2, 14, 30, 0, 123456000, time.UTC),
+		ID:        42,
+		Backward:  true,
+	}
+
+	decoded, err := DecodeArticleCursor(c.Encode())
+	if err != nil {
+		t.Fatalf("Expected cursor to decode, got %v", err)
+	}
+	if !decoded.ScrapedAt.Equal(c.ScrapedAt) || decoded.ID != c.ID || decoded.Backward != c.Backward {
+		t.Errorf("Expected %+v, got %+v", c, decoded)
+	}
+
+	tooLarge := ArticleCursor{ScrapedAt: c.ScrapedAt, ID: math.MaxInt32 + 1}.Encode()
+	for _, token := range []string{"not a cursor", "e30", "eyJpZCI6MX0", tooLarge} {
+		if _, err := DecodeArticleCursor(token); err != ErrInvalidCursor {
+			t.Errorf("Expected ErrInvalidCursor for %q, got %v", token, err)
+		}
+	}
+}
+
+func TestNewArticlePage(t *testing.T) {
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	articlesFrom := func(ids ...int64) []database.Article {
+		articles := make([]database.Article, len(ids))
+		for i, id := range ids {
+			articles[i] = database.Article{ID: id, ScrapedAt: start.Add(time.Duration(id) * time.Minute)}
+		}
+		return articles
+	}
+	ids := func(page ArticlePage) []int64 {
+		result := make([]int64, len(page.Articles))
+		for i, article := range page.Articles {
+			result[i] = article.ID
+		}
+		return result
+	}
+
+	// First page with more to come
+	page := newArticlePage(articlesFrom(9, 8, 7), nil, 2)
+	if got := ids(page); len(got) != 2 || got[0] != 9 || got[1] != 8 {
+		t.Errorf("Expected articles [9 8], got %v", got)
+	}
+	if page.Prev != nil || page.Next == nil || page.Next.ID != 8 || page.Next.Backward {
+		t.Errorf("Expected only a forward next cursor at 8, got next %+v prev %+v", page.Next, page.Prev)
+	}
+
+	// Last page read forward
+	page = newArticlePage(articlesFrom(7), articleCursor(start, 8, false), 2)
+	if page.Next != nil || page.Prev == nil || page.Prev.ID != 7 || !page.Prev.Backward {
+		t.Errorf("Expected only a backward prev cursor at 7, got next %+v prev %+v", page.Next, page.Prev)
+	}
+
+	// Page read backward, oldest first, with newer articles left
+	page = newArticlePage(articlesFrom(8, 9, 10), articleCursor(start, 7, true), 2)
+	if got := ids(page); len(got) != 2 || got[0] != 9 || got[1] != 8 {
+		t.Errorf("Expected articles [9 8], got %v", got)
+	}
+	if page.Prev == nil || page.Prev.ID != 9 || page.Next == nil || page.Next.ID != 8 {
+		t.Errorf("Expected prev cursor at 9 and next cursor at 8, got next %+v prev %+v", page.Next, page.Prev)
+	}
+
+	// Nothing newer than the cursor
+	page = newArticlePage(articlesFrom(), articleCursor(start, 9, true), 2)
+	if page.Prev != nil || page.Next == nil || page.Next.ID != 9 {
+		t.Errorf("Expected only a next cursor at 9, got next %+v prev %+v", page.Next, page.Prev)
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/guillermoballester/propagatorGo/internal/database"
+	"github.com/guillermoballester/propagatorGo/internal/database/sqlc"
+)
+
+// JobOverrideRepository handles database operations for job overrides
+type JobOverrideRepository struct {
+	queries *sqlc.Queries
+}
+
+// NewJobOverrideRepository creates a new job override repository
+func NewJobOverrideRepository(db *sql.DB) *JobOverrideRepository {
+	return &JobOverrideRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+// ListOverrides returns the overrides of every job that has one
+func (r *JobOverrideRepository) ListOverrides(ctx context.Context) ([]database.JobOverride, error) {
+	rows, err := r.queries.ListJobOverrides(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing job overrides: %w", err)
+	}
+
+	overrides := make([]database.JobOverride, len(rows))
+	for i, row := range rows {
+		overrides[i] = database.JobOverride{
+			JobName:   row.JobName,
+			Paused:    row.Paused,
+			CronExpr:  row.CronExpr.String,
+			UpdatedAt: row.UpdatedAt,
+		}
+	}
+	return overrides, nil
+}
+
+// SaveOverride stores the override of a job. An override that changes
+// nothing is deleted, so the job follows its configuration again.
+func (r *JobOverrideRepository) SaveOverride(ctx context.Context, override database.JobOverride) error {
+	if !override.Paused && override.CronExpr == "" {
+		if err := r.queries.DeleteJobOverride(ctx, override.JobName); err != nil {
+			return fmt.Errorf("error deleting override of job %s: %w", override.JobName, err)
+		}
+		return nil
+	}
+
+	err := r.queries.UpsertJobOverride(ctx, sqlc.UpsertJobOverrideParams{
+		JobName:  override.JobName,
+		Paused:   override.Paused,
+		CronExpr: nullString(override.CronExpr),
+	})
+	if err != nil {
+		return fmt.Errorf("error saving override of job %s: %w", override.JobName, err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/database"
+	"github.com/guillermoballester/propagatorGo/internal/database/sqlc"
+)
+
+// ErrJobRunNotFound is returned when a job run does not exist
+var ErrJobRunNotFound = errors.New("job run not found")
+
+// JobRunRepository handles database operations for job runs
+type JobRunRepository struct {
+	queries *sqlc.Queries
+}
+
+// JobRunFilter narrows down a job run listing. Empty fields match every run.
+type JobRunFilter struct {
+	JobName    string
+	Status     string
+	Trigger    string
+	InstanceID string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+	Offset     int
+}
+
+// NewJobRunRepository creates a new job run repository
+func NewJobRunRepository(db *sql.DB) *JobRunRepository {
+	return &JobRunRepository{
+		queries: sqlc.New(db),
+	}
+}
+
+// StartRun records a run that just started and sets its ID
+func (r *JobRunRepository) StartRun(ctx context.Context, run *database.JobRun) error {
+	id, err := r.queries.CreateJobRun(ctx, sqlc.CreateJobRunParams{
+		JobName:     run.JobName,
+		Trigger:     run.Trigger,
+		Status:      run.Status,
+		StartedAt:   run.StartedAt,
+		InstanceID:  run.InstanceID,
+		ParentRunID: sql.NullInt64{Int64: run.ParentRunID, Valid: run.ParentRunID != 0},
+	})
+	if err != nil {
+		return fmt.Errorf("error recording start of job run: %w", err)
+	}
+
+	run.ID = id
+	return nil
+}
+
+// FinishRun records the outcome of a run started with StartRun
+func (r *JobRunRepository) FinishRun(ctx context.Context, run *database.JobRun) error {
+	err := r.queries.FinishJobRun(ctx, sqlc.FinishJobRunParams{
+		ID:             run.ID,
+		Status:         run.Status,
+		FinishedAt:     nullTime(run.FinishedAt),
+		Error:          nullString(run.Error),
+		ItemsProcessed: run.ItemsProcessed,
+		ItemsFailed:    run.ItemsFailed,
+		Attempts:       int32(run.Attempts),
+		Summary:        nullString(run.Summary),
+	})
+	if err != nil {
+		return fmt.Errorf("error recording end of job run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+// ListRuns returns the runs matching a filter, latest first, along with the
+// number of matching runs
+func (r *JobRunRepository) ListRuns(ctx context.Context, filter JobRunFilter) ([]database.JobRun, int, error) {
+	dbRuns, err := r.queries.ListJobRuns(ctx, sqlc.ListJobRunsParams{
+		JobName:    nullString(filter.JobName),
+		Status:     nullString(filter.Status),
+		Trigger:    nullString(filter.Trigger),
+		InstanceID: nullString(filter.InstanceID),
+		Since:      nullTime(filter.Since),
+		Until:      nullTime(filter.Until),
+		Limit:      int32(filter.Limit),
+		Offset:     int32(filter.Offset),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing job runs: %w", err)
+	}
+
+	total, err := r.queries.CountJobRuns(ctx, sqlc.CountJobRunsParams{
+		JobName:    nullString(filter.JobName),
+		Status:     nullString(filter.Status),
+		Trigger:    nullString(filter.Trigger),
+		InstanceID: nullString(filter.InstanceID),
+		Since:      nullTime(filter.Since),
+		Until:      nullTime(filter.Until),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting job runs: %w", err)
+	}
+
+	runs := make([]database.JobRun, len(dbRuns))
+	for i, dbRun := range dbRuns {
+		runs[i] = mapSQLCJobRunToModel(dbRun)
+	}
+	return runs, int(total), nil
+}
+
+// GetRun returns a single run
+func (r *JobRunRepository) GetRun(ctx context.Context, id int64) (database.JobRun, error) {
+	dbRun, err := r.queries.GetJobRun(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.JobRun{}, ErrJobRunNotFound
+	}
+	if err != nil {
+		return database.JobRun{}, fmt.Errorf("error getting job run %d: %w", id, err)
+	}
+	return mapSQLCJobRunToModel(dbRun), nil
+}
+
+// ListDownstreamRuns returns every run triggered, directly or not, by a run,
+// oldest first
+func (r *JobRunRepository) ListDownstreamRuns(ctx context.Context, id int64) ([]database.JobRun, error) {
+	rows, err := r.queries.ListDownstreamJobRuns(ctx, sql.NullInt64{Int64: id, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("error listing runs downstream of job run %d: %w", id, err)
+	}
+
+	runs := make([]database.JobRun, len(rows))
+	for i, row := range rows {
+		runs[i] = mapSQLCJobRunToModel(sqlc.JobRun(row))
+	}
+	return runs, nil
+}
+
+// LastScheduledRunStart returns when the last scheduled or catch-up run of a
+// job started, or the zero time if it never ran
+func (r *JobRunRepository) LastScheduledRunStart(ctx context.Context, jobName string) (time.Time, error) {
+	startedAt, err := r.queries.GetLastScheduledJobRunStart(ctx, jobName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error getting last run of job %s: %w", jobName, err)
+	}
+	return startedAt, nil
+}
+
+// PruneRuns deletes the runs started before a point in time. Returns the
+// number of runs deleted.
+func (r *JobRunRepository) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := r.queries.DeleteJobRunsBefore(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning job runs: %w", err)
+	}
+	return deleted, nil
+}
+
+// AbandonRuns marks the runs of a job still running and started before a
+// point in time as abandoned, finished at finishedAt. Returns the number of
+// runs marked.
+func (r *JobRunRepository) AbandonRuns(ctx context.Context, jobName string, startedBefore, finishedAt time.Time) (int64, error) {
+	abandoned, err := r.queries.AbandonJobRuns(ctx, sqlc.AbandonJobRunsParams{
+		JobName:       jobName,
+		StartedBefore: startedBefore,
+		FinishedAt:    nullTime(finishedAt),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error abandoning runs of job %s: %w", jobName, err)
+	}
+	return abandoned, nil
+}
+
+// mapSQLCJobRunToModel converts a sqlc job run to the domain model
+func mapSQLCJobRunToModel(dbRun sqlc.JobRun) database.JobRun {
+	return database.JobRun{
+		ID:             dbRun.ID,
+		JobName:        dbRun.JobName,
+		Trigger:        dbRun.Trigger,
+		ParentRunID:    dbRun.ParentRunID.Int64,
+		Status:         dbRun.Status,
+		StartedAt:      dbRun.StartedAt,
+		FinishedAt:     dbRun.FinishedAt.Time,
+		Error:          dbRun.Error.String,
+		ItemsProcessed: dbRun.ItemsProcessed,
+		ItemsFailed:    dbRun.ItemsFailed,
+		Attempts:       int(dbRun.Attempts),
+		Summary:        dbRun.Summary.String,
+		InstanceID:     dbRun.InstanceID,
+	}
+}
+
+// nullString maps an empty string to NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullTime maps a zero time to NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
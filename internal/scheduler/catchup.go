@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// defaultCatchUpLimit bounds the missed runs replayed by the "all" policy
+	defaultCatchUpLimit = 5
+
+	// maxMissedRuns bounds how many missed activations are counted
+	maxMissedRuns = 10000
+)
+
+// catchUp looks up when each job last ran on schedule and applies the job's
+// catch-up policy to the runs it missed since. Jobs that never ran are not
+// caught up.
+func (s *Scheduler) catchUp(now time.Time) {
+	type scheduledJob struct {
+		*Job
+		schedule cron.Schedule
+	}
+
+	// Paused jobs have no schedule, so they are not caught up
+	s.jobsMutex.RLock()
+	var jobs []scheduledJob
+	for _, job := range s.jobs {
+		if job.Enabled && job.schedule != nil && job.CatchUp != "" && job.CatchUp != constants.CatchUpNone {
+			jobs = append(jobs, scheduledJob{Job: job, schedule: job.schedule})
+		}
+	}
+	s.jobsMutex.RUnlock()
+
+	for _, job := range jobs {
+		ctx, cancel := context.WithTimeout(s.ctx, historyTimeout)
+		last, err := s.history.LastScheduledRunStart(ctx, job.Name)
+		cancel()
+		if err != nil {
+			log.Printf("Not catching up job %s: %v", job.Name, err)
+			continue
+		}
+		if last.IsZero() {
+			continue
+		}
+
+		missed := missedRuns(job.schedule, last, now)
+		if missed == 0 {
+			continue
+		}
+
+		runs := 1
+		if job.CatchUp == constants.CatchUpAll {
+			limit := job.CatchUpLimit
+			if limit <= 0 {
+				limit = defaultCatchUpLimit
+			}
+			runs = missed
+			if runs > limit {
+				runs = limit
+			}
+		}
+
+		log.Printf("Job %s missed %d scheduled runs since %s, catching up with %d run(s)",
+			job.Name, missed, last.Format(time.RFC3339), runs)
+		go s.runCatchUp(job.Name, runs)
+	}
+}
+
+// runCatchUp runs a job several times in a row to replace missed runs
+func (s *Scheduler) runCatchUp(name string, runs int) {
+	for i := 0; i < runs; i++ {
+		if s.isClosed() {
+			log.Printf("Job %s caught up %d of %d missed runs before shutdown", name, i, runs)
+			return
+		}
+		s.executeJob(name, TriggerCatchUp, 0)
+	}
+	log.Printf("Job %s caught up %d missed run(s)", name, runs)
+}
+
+// missedRuns counts the activations of a schedule after last, up to now
+func missedRuns(schedule cron.Schedule, last, now time.Time) int {
+	count := 0
+	for next := schedule.Next(last); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		count++
+		if count == maxMissedRuns {
+			break
+		}
+	}
+	return count
+}
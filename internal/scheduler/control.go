@@ -0,0 +1,305 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/database"
+)
+
+// ErrJobNotRunning is returned when cancelling a job that has no run in progress
+var ErrJobNotRunning = errors.New("job is not running")
+
+// ErrInvalidSchedule is returned when rescheduling a job with an invalid cron expression
+var ErrInvalidSchedule = errors.New("invalid cron expression")
+
+// overrideSyncInterval is how often the stored overrides are applied, so
+// changes made through another instance reach this one
+const overrideSyncInterval = 30 * time.Second
+
+// OverrideStore persists the changes made to jobs at runtime, so they survive
+// restarts and reach every instance
+type OverrideStore interface {
+	ListOverrides(ctx context.Context) ([]database.JobOverride, error)
+	SaveOverride(ctx context.Context, override database.JobOverride) error
+}
+
+// JobInfo is a snapshot of a job's settings and state
+type JobInfo struct {
+	Name         string
+	Description  string
+	Enabled      bool
+	Paused       bool
+	CronExpr     string
+	Overlap      string
+	DependsOn    []string
+	OnFailure    []string
+	Status       JobStatus
+	ActiveRuns   int
+	Queued       bool
+	LastRun      time.Time
+	NextRun      time.Time
+	LastError    string
+	LastRunTime  time.Duration
+	LastAttempts int
+	LastResult   *JobResult
+}
+
+// SetOverrides applies and persists the changes made to jobs at runtime. It
+// must be called before Start.
+func (s *Scheduler) SetOverrides(store OverrideStore) {
+	s.overrides = store
+}
+
+// Jobs returns a snapshot of every job, sorted by name
+func (s *Scheduler) Jobs() []JobInfo {
+	s.jobsMutex.RLock()
+	defer s.jobsMutex.RUnlock()
+
+	jobs := make([]JobInfo, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job.info())
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs
+}
+
+// Job returns a snapshot of a job
+func (s *Scheduler) Job(name string) (JobInfo, error) {
+	s.jobsMutex.RLock()
+	defer s.jobsMutex.RUnlock()
+
+	job, exists := s.jobs[name]
+	if !exists {
+		return JobInfo{}, fmt.Errorf("job '%s': %w", name, ErrJobNotFound)
+	}
+	return job.info(), nil
+}
+
+// info takes a snapshot of a job; callers must hold s.jobsMutex
+func (j *Job) info() JobInfo {
+	info := JobInfo{
+		Name:         j.Name,
+		Description:  j.Description,
+		Enabled:      j.Enabled,
+		Paused:       j.Paused,
+		CronExpr:     j.CronExpr,
+		Overlap:      j.Overlap,
+		DependsOn:    j.DependsOn,
+		OnFailure:    j.OnFailure,
+		Status:       j.Status,
+		ActiveRuns:   len(j.runs),
+		Queued:       j.queued,
+		LastRun:      j.LastRun,
+		NextRun:      j.NextRun,
+		LastRunTime:  j.LastRunTime,
+		LastAttempts: j.LastAttempts,
+		LastResult:   j.LastResult,
+	}
+	if j.LastError != nil {
+		info.LastError = j.LastError.Error()
+	}
+	return info
+}
+
+// PauseJob stops firing the scheduled runs of a job until it is resumed. The
+// job still runs when triggered manually or by other jobs.
+func (s *Scheduler) PauseJob(name string) error {
+	return s.updateJob(name, func(override *database.JobOverride) {
+		override.Paused = true
+	})
+}
+
+// ResumeJob fires the scheduled runs of a paused job again
+func (s *Scheduler) ResumeJob(name string) error {
+	return s.updateJob(name, func(override *database.JobOverride) {
+		override.Paused = false
+	})
+}
+
+// RescheduleJob changes the cron expression of a job. An empty expression
+// restores the configured one.
+func (s *Scheduler) RescheduleJob(name, cronExpr string) error {
+	if cronExpr != "" {
+		if _, err := cronParser.Parse(cronExpr); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+	}
+
+	return s.updateJob(name, func(override *database.JobOverride) {
+		override.CronExpr = cronExpr
+	})
+}
+
+// CancelJob cancels the runs of a job in progress on this instance. Returns
+// the number of runs cancelled.
+func (s *Scheduler) CancelJob(name string) (int, error) {
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	job, exists := s.jobs[name]
+	if !exists {
+		return 0, fmt.Errorf("job '%s': %w", name, ErrJobNotFound)
+	}
+
+	cancelled := 0
+	for run := range job.runs {
+		if run.cancelReason == "" {
+			run.cancelReason = "cancelled on request"
+			run.cancel()
+			cancelled++
+		}
+	}
+	if cancelled == 0 {
+		return 0, fmt.Errorf("job '%s': %w", name, ErrJobNotRunning)
+	}
+
+	log.Printf("Cancelled %d run(s) of job %s on request", cancelled, name)
+	return cancelled, nil
+}
+
+// updateJob changes the override of a job, persists it, then applies it
+func (s *Scheduler) updateJob(name string, change func(override *database.JobOverride)) error {
+	s.adminMutex.Lock()
+	defer s.adminMutex.Unlock()
+
+	s.jobsMutex.RLock()
+	job, exists := s.jobs[name]
+	var override database.JobOverride
+	if exists {
+		override = job.override()
+	}
+	s.jobsMutex.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("job '%s': %w", name, ErrJobNotFound)
+	}
+	if !job.Enabled {
+		return fmt.Errorf("job '%s': %w", name, ErrJobDisabled)
+	}
+
+	change(&override)
+	if override.CronExpr == job.config.CronExpr {
+		override.CronExpr = ""
+	}
+
+	if s.overrides != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+		err := s.overrides.SaveOverride(ctx, override)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	return s.applyOverride(job, override)
+}
+
+// override returns the changes made to a job's configuration; callers must
+// hold s.jobsMutex
+func (j *Job) override() database.JobOverride {
+	override := database.JobOverride{JobName: j.Name, Paused: j.Paused}
+	if j.CronExpr != j.config.CronExpr {
+		override.CronExpr = j.CronExpr
+	}
+	return override
+}
+
+// applyOverride reschedules a job after the changes made to it, restoring its
+// previous schedule if the new one is invalid. Callers must hold s.jobsMutex.
+func (s *Scheduler) applyOverride(job *Job, override database.JobOverride) error {
+	cronExpr := override.CronExpr
+	if cronExpr == "" {
+		cronExpr = job.config.CronExpr
+	}
+	if job.Paused == override.Paused && job.CronExpr == cronExpr {
+		return nil
+	}
+
+	paused, previous := job.Paused, job.CronExpr
+	s.unscheduleJob(job)
+	job.Paused, job.CronExpr = override.Paused, cronExpr
+
+	if err := s.scheduleJob(job); err != nil {
+		job.Paused, job.CronExpr = paused, previous
+		if restoreErr := s.scheduleJob(job); restoreErr != nil {
+			log.Printf("Error restoring the schedule of job %s: %v", job.Name, restoreErr)
+		}
+		return fmt.Errorf("error rescheduling job %s: %w", job.Name, err)
+	}
+
+	switch {
+	case job.Paused:
+		log.Printf("Job %s is paused", job.Name)
+	case job.CronExpr == "":
+		log.Printf("Job %s runs only when triggered", job.Name)
+	default:
+		log.Printf("Job %s is scheduled with %q, next run at %s",
+			job.Name, job.CronExpr, job.NextRun.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// syncOverrides applies the stored overrides to every enabled job. Jobs
+// without one go back to their configuration.
+func (s *Scheduler) syncOverrides() {
+	s.adminMutex.Lock()
+	defer s.adminMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(s.ctx, historyTimeout)
+	overrides, err := s.overrides.ListOverrides(ctx)
+	cancel()
+	if err != nil {
+		log.Printf("Error loading job overrides: %v", err)
+		return
+	}
+
+	byJob := make(map[string]database.JobOverride, len(overrides))
+	for _, override := range overrides {
+		byJob[override.JobName] = override
+	}
+
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	for name, job := range s.jobs {
+		if !job.Enabled {
+			continue
+		}
+
+		override, ok := byJob[name]
+		if !ok {
+			override = database.JobOverride{JobName: name}
+		}
+		if err := s.applyOverride(job, override); err != nil {
+			log.Printf("Error applying override of job %s: %v", name, err)
+		}
+	}
+}
+
+// watchOverrides periodically applies the stored overrides
+func (s *Scheduler) watchOverrides() {
+	ticker := s.clock.NewTicker(overrideSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if s.isClosed() {
+				return
+			}
+			s.syncOverrides()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
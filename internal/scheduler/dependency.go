@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+// ValidateDAG checks that the jobs a job depends on or runs on failure exist
+// and that these links do not form a cycle
+func ValidateDAG(jobs []config.JobConfig) error {
+	known := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		known[job.Name] = true
+	}
+
+	// Edges point from a job to the jobs it may trigger
+	edges := make(map[string][]string, len(jobs))
+	for _, job := range jobs {
+		seen := make(map[string]bool, len(job.DependsOn))
+		for _, dep := range job.DependsOn {
+			if !known[dep] {
+				return fmt.Errorf("job %s depends on unknown job %s", job.Name, dep)
+			}
+			if seen[dep] {
+				return fmt.Errorf("job %s depends on %s twice", job.Name, dep)
+			}
+			seen[dep] = true
+			edges[dep] = append(edges[dep], job.Name)
+		}
+		for _, hook := range job.OnFailure {
+			if !known[hook] {
+				return fmt.Errorf("job %s runs unknown job %s on failure", job.Name, hook)
+			}
+			edges[job.Name] = append(edges[job.Name], hook)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(jobs))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			// The cycle is the part of the path starting at this job
+			start := 0
+			for i, n := range path {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("job dependency cycle: %s", strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, next := range edges[name] {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, job := range jobs {
+		if err := visit(job.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// triggerDownstream runs the jobs that follow a finished run: the jobs whose
+// dependencies are now all complete after a success, or the failure hooks
+// after a failure. Only successful runs complete a dependency; a skipped run
+// leaves its dependents waiting. runID is the recorded run, zero when not recorded.
+func (s *Scheduler) triggerDownstream(job *Job, status JobStatus, runID int64) {
+	switch status {
+	case StatusSucceeded:
+		for _, name := range s.completeDependency(job.Name, runID) {
+			log.Printf("Job %s completed, triggering dependent job %s", job.Name, name)
+			go s.executeJob(name, TriggerDependency, runID)
+		}
+	case StatusFailed:
+		for _, name := range job.OnFailure {
+			log.Printf("Job %s failed, triggering failure hook %s", job.Name, name)
+			go s.executeJob(name, TriggerOnFailure, runID)
+		}
+	}
+}
+
+// completeDependency marks a job as completed for every job depending on it.
+// Returns the jobs whose dependencies are now all complete, resetting them
+// for the next round.
+func (s *Scheduler) completeDependency(upstream string, runID int64) []string {
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	var ready []string
+	for name, job := range s.jobs {
+		if !dependsOn(job, upstream) {
+			continue
+		}
+
+		job.satisfied[upstream] = runID
+		complete := true
+		for _, dep := range job.DependsOn {
+			if _, ok := job.satisfied[dep]; !ok {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			job.satisfied = make(map[string]int64)
+			ready = append(ready, name)
+		}
+	}
+
+	sort.Strings(ready)
+	return ready
+}
+
+// dependsOn reports whether a job lists another among its dependencies
+func dependsOn(job *Job, upstream string) bool {
+	for _, dep := range job.DependsOn {
+		if dep == upstream {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+func TestValidateDAGAcceptsDiamond(t *testing.T) {
+	jobs := []config.JobConfig{
+		{Name: "dispatch"},
+		{Name: "scrape-a", DependsOn: []string{"dispatch"}},
+		{Name: "scrape-b", DependsOn: []string{"dispatch"}},
+		{Name: "consume", DependsOn: []string{"scrape-a", "scrape-b"}},
+		{Name: "alert"},
+	}
+	jobs[1].OnFailure = []string{"alert"}
+
+	if err := ValidateDAG(jobs); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestValidateDAGRejectsCycle(t *testing.T) {
+	jobs := []config.JobConfig{
+		{Name: "a", DependsOn: []string{"c"}},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "c", DependsOn: []string{"b"}},
+	}
+
+	err := ValidateDAG(jobs)
+	if err == nil {
+		t.Fatal("Expected a cycle error, got nil")
+	}
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("Expected the cycle a -> b -> c -> a in %q", err.Error())
+	}
+}
+
+func TestValidateDAGRejectsCycleThroughFailureHook(t *testing.T) {
+	jobs := []config.JobConfig{
+		{Name: "scrape", DependsOn: []string{"fallback"}, OnFailure: []string{"fallback"}},
+		{Name: "fallback"},
+	}
+
+	if err := ValidateDAG(jobs); err == nil {
+		t.Error("Expected a cycle error, got nil")
+	}
+}
+
+func TestValidateDAGRejectsSelfDependency(t *testing.T) {
+	jobs := []config.JobConfig{
+		{Name: "a", DependsOn: []string{"a"}},
+	}
+
+	if err := ValidateDAG(jobs); err == nil {
+		t.Error("Expected a cycle error, got nil")
+	}
+}
+
+func TestValidateDAGRejectsUnknownJob(t *testing.T) {
+	jobs := []config.JobConfig{
+		{Name: "consume", DependsOn: []string{"scrape"}},
+	}
+
+	if err := ValidateDAG(jobs); err == nil {
+		t.Error("Expected an unknown job error, got nil")
+	}
+}
+
+func TestSkippedUpstreamDoesNotTriggerDependents(t *testing.T) {
+	s, _ := newTestScheduler(t)
+
+	var skip atomic.Bool
+	skip.Store(true)
+	err := s.AddJob(config.JobConfig{Name: "dispatch", Enabled: true}, func(context.Context) (*JobResult, error) {
+		if skip.Load() {
+			return nil, ErrSkipped
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+
+	var runs int32
+	err = s.AddJob(config.JobConfig{Name: "consume", DependsOn: []string{"dispatch"}, Enabled: true},
+		func(context.Context) (*JobResult, error) {
+			atomic.AddInt32(&runs, 1)
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+
+	if err := s.RunJob("dispatch"); err != nil {
+		t.Fatalf("Expected the job to run, got %v", err)
+	}
+	waitFor(t, "the skipped run", func() bool { return jobState(t, s, "dispatch").Status == StatusSkipped })
+	time.Sleep(5 * time.Millisecond)
+	if n := atomic.LoadInt32(&runs); n != 0 {
+		t.Fatalf("Expected the dependent job not to run after a skipped run, got %d runs", n)
+	}
+
+	skip.Store(false)
+	if err := s.RunJob("dispatch"); err != nil {
+		t.Fatalf("Expected the job to run, got %v", err)
+	}
+	waitFor(t, "the dependent run", func() bool { return atomic.LoadInt32(&runs) == 1 })
+}
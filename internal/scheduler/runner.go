@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+
+	"github.com/robfig/cron/v3"
+)
+
+// entryID identifies a schedule entry of a runner; zero is never used
+type entryID int
+
+// entry is a function fired on a schedule
+type entry struct {
+	schedule cron.Schedule
+	next     time.Time
+	fire     func()
+}
+
+// runner fires functions on their cron schedules, waiting on a clock so it
+// can be driven by a fake clock in tests. Each function runs in its own
+// goroutine.
+type runner struct {
+	clock   clock.Clock
+	mu      sync.Mutex
+	entries map[entryID]*entry
+	lastID  entryID
+	running bool
+	wake    chan struct{} // Signals that the entries changed
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// newRunner creates a runner waiting on a clock
+func newRunner(c clock.Clock) *runner {
+	return &runner{
+		clock:   c,
+		entries: make(map[entryID]*entry),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Schedule adds a function fired on a schedule, returning its entry
+func (r *runner) Schedule(schedule cron.Schedule, fire func()) entryID {
+	r.mu.Lock()
+	r.lastID++
+	id := r.lastID
+	r.entries[id] = &entry{
+		schedule: schedule,
+		next:     schedule.Next(r.clock.Now()),
+		fire:     fire,
+	}
+	r.mu.Unlock()
+
+	r.notify()
+	return id
+}
+
+// Remove removes an entry
+func (r *runner) Remove(id entryID) {
+	r.mu.Lock()
+	delete(r.entries, id)
+	r.mu.Unlock()
+
+	r.notify()
+}
+
+// Next returns when an entry fires next, or false if there is no such entry
+func (r *runner) Next(id entryID) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[id]
+	if !ok {
+		return time.Time{}, false
+	}
+	return e.next, true
+}
+
+// Start starts firing entries. Calls while running are ignored.
+func (r *runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return
+	}
+	r.running = true
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.loop(r.stop, r.done)
+}
+
+// Stop stops firing entries and waits for the loop to exit. Functions already
+// fired keep running.
+func (r *runner) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = false
+	close(r.stop)
+	done := r.done
+	r.mu.Unlock()
+
+	<-done
+}
+
+// loop waits for the earliest entry, fires the entries due and starts over
+func (r *runner) loop(stop, done chan struct{}) {
+	defer close(done)
+
+	for {
+		var timer clock.Timer
+		var due <-chan time.Time
+		if next, ok := r.earliest(); ok {
+			timer = r.clock.NewTimer(next.Sub(r.clock.Now()))
+			due = timer.C()
+		}
+
+		select {
+		case <-due:
+			r.fireDue()
+		case <-r.wake:
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// earliest returns when the next entry is due, or false if there is none
+func (r *runner) earliest() (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var earliest time.Time
+	for _, e := range r.entries {
+		if e.next.IsZero() {
+			continue
+		}
+		if earliest.IsZero() || e.next.Before(earliest) {
+			earliest = e.next
+		}
+	}
+	return earliest, !earliest.IsZero()
+}
+
+// fireDue fires every entry due and computes when each fires next
+func (r *runner) fireDue() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	for _, e := range r.entries {
+		if e.next.IsZero() || e.next.After(now) {
+			continue
+		}
+		go e.fire()
+		e.next = e.schedule.Next(now)
+	}
+}
+
+// notify wakes the loop so it waits for the entries as they are now
+func (r *runner) notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
@@ -2,12 +2,17 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/guillermoballester/propagatorGo/internal/calendar"
+	"github.com/guillermoballester/propagatorGo/internal/clock"
 	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/database"
 
 	"github.com/robfig/cron/v3"
 )
@@ -20,36 +25,154 @@ const (
 	StatusRunning   JobStatus = "running"
 	StatusSucceeded JobStatus = "succeeded"
 	StatusFailed    JobStatus = "failed"
+	StatusSkipped   JobStatus = "skipped"
+	StatusCancelled JobStatus = "cancelled" // Replaced by a newer run or cancelled on request
+	StatusAbandoned JobStatus = "abandoned" // Left running by an instance that stopped
 )
 
+// ErrSkipped is returned by a job function that decided not to run,
+// e.g. because another instance is responsible for it
+var ErrSkipped = errors.New("job skipped")
+
+// ErrShuttingDown is returned when a job is requested after Close
+var ErrShuttingDown = errors.New("scheduler is shutting down")
+
+// ErrJobDisabled is returned when a disabled job is requested
+var ErrJobDisabled = errors.New("job is disabled")
+
+// ErrJobNotFound is returned when a job that was not added is requested
+var ErrJobNotFound = errors.New("job not found")
+
+// Triggers say what started a job run
+const (
+	TriggerScheduled  = "scheduled"  // Fired by the job's cron schedule
+	TriggerManual     = "manual"     // Requested through RunJob
+	TriggerDependency = "dependency" // Every job it depends on completed
+	TriggerOnFailure  = "on-failure" // A job listing it as failure hook failed
+	TriggerCatchUp    = "catch-up"   // Replaces a scheduled run missed while no instance was running
+)
+
+// triggerKey is the context key holding the trigger of a job run
+type triggerKey struct{}
+
+// TriggerFromContext returns what started the job run a context belongs to,
+// or an empty string outside of a run
+func TriggerFromContext(ctx context.Context) string {
+	trigger, _ := ctx.Value(triggerKey{}).(string)
+	return trigger
+}
+
+const (
+	// defaultRetryBackoff is the delay before the first retry of a failed run
+	defaultRetryBackoff = 30 * time.Second
+
+	// maxRetryBackoff caps the delay between retries
+	maxRetryBackoff = 10 * time.Minute
+
+	// defaultHistoryRetention is how long job runs are kept when the config sets no retention
+	defaultHistoryRetention = 30 * 24 * time.Hour
+
+	// pruneInterval is how often job runs older than the retention are deleted
+	pruneInterval = time.Hour
+
+	// historyTimeout bounds each write to the history store
+	historyTimeout = 5 * time.Second
+)
+
+// HistoryStore persists every job run
+type HistoryStore interface {
+	StartRun(ctx context.Context, run *database.JobRun) error
+	FinishRun(ctx context.Context, run *database.JobRun) error
+	PruneRuns(ctx context.Context, before time.Time) (int64, error)
+	LastScheduledRunStart(ctx context.Context, jobName string) (time.Time, error)
+
+	// AbandonRuns marks the runs of a job still running and started before
+	// startedBefore as abandoned
+	AbandonRuns(ctx context.Context, jobName string, startedBefore, finishedAt time.Time) (int64, error)
+}
+
+// JobResult summarises what a job run accomplished
+type JobResult struct {
+	ItemsProcessed int64
+	ItemsFailed    int64
+	Summary        string
+}
+
+// JobFunc is the work performed by a job. It may return a nil result.
+type JobFunc func(ctx context.Context) (*JobResult, error)
+
 // Job represents a schedulable task
 type Job struct {
-	cronID      cron.EntryID
-	Name        string
-	Func        func(ctx context.Context) error
-	Timeout     time.Duration
-	Status      JobStatus
-	LastRun     time.Time
-	NextRun     time.Time
-	LastError   error
-	LastRunTime time.Duration
-}
-
-// Scheduler manages scheduled jobs using robfig/cron
+	entryID      entryID // Zero when the job is not scheduled
+	Name         string
+	Description  string
+	Func         JobFunc
+	Timeout      time.Duration // Per attempt
+	RetryCount   int
+	RetryBackoff time.Duration
+	Enabled      bool
+	Paused       bool     // Scheduled runs are not fired
+	CronExpr     string   // Current cron expression, empty when the job only runs when triggered
+	Overlap      string   // Overlap policy
+	DependsOn    []string // Jobs that must complete before this one runs
+	OnFailure    []string // Jobs run when this one fails
+	CatchUp      string   // Catch-up policy for missed runs
+	CatchUpLimit int
+	Status       JobStatus
+	LastRun      time.Time
+	NextRun      time.Time
+	LastError    error
+	LastRunTime  time.Duration
+	LastAttempts int // Attempts made by the last run
+	LastResult   *JobResult
+
+	config   config.JobConfig
+	schedule cron.Schedule // Nil when the job is not scheduled
+
+	runs   map[*activeRun]struct{} // Runs in progress
+	idle   chan struct{}           // Closed once the runs in progress have ended
+	queued bool                    // A queue-one run is waiting for the current run
+
+	// satisfied holds the dependencies completed since the job was last
+	// triggered by them, with the ID of the run that completed each
+	satisfied map[string]int64
+}
+
+// activeRun is a run in progress
+type activeRun struct {
+	ctx          context.Context
+	cancel       context.CancelFunc
+	cancelReason string // Why the run was cancelled, empty unless it was
+}
+
+// Scheduler manages scheduled jobs, using robfig/cron schedules
 type Scheduler struct {
-	cron      *cron.Cron
+	clock     clock.Clock
+	runner    *runner
 	jobs      map[string]*Job
 	jobsMutex sync.RWMutex
 	ctx       context.Context
 	cancel    context.CancelFunc
 	config    *config.SchedulerConfig
+	closed    bool           // No new job runs are accepted
+	running   sync.WaitGroup // Job runs in progress
+
+	history    HistoryStore
+	instanceID string // Recorded with every run
+
+	calendars *calendar.Registry // Exchange calendars for calendar-aware jobs
+
+	overrides  OverrideStore
+	adminMutex sync.Mutex // Serializes changes to job overrides
 }
 
 // NewScheduler creates a new scheduler
 func NewScheduler(cfg *config.SchedulerConfig) *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
+	c := clock.New()
 	return &Scheduler{
-		cron:   cron.New(cron.WithSeconds()),
+		clock:  c,
+		runner: newRunner(c),
 		jobs:   make(map[string]*Job),
 		ctx:    ctx,
 		cancel: cancel,
@@ -57,122 +180,577 @@ func NewScheduler(cfg *config.SchedulerConfig) *Scheduler {
 	}
 }
 
-// AddJob schedules a new job with a cron expression
-func (s *Scheduler) AddJob(name, cronExpr string, timeout time.Duration, jobFunc func(ctx context.Context) error) error {
+// SetClock makes the scheduler tell the time and wait on a clock other than
+// the wall clock, e.g. a fake clock in tests. It must be called before jobs
+// are added.
+func (s *Scheduler) SetClock(c clock.Clock) {
+	s.clock = c
+	s.runner = newRunner(c)
+}
+
+// SetHistory records every job run in a history store, tagged with the ID of
+// this instance. It must be called before Start.
+func (s *Scheduler) SetHistory(store HistoryStore, instanceID string) {
+	s.history = store
+	s.instanceID = instanceID
+}
+
+// SetCalendars makes exchange calendars available to calendar-aware jobs.
+// It must be called before jobs using them are added.
+func (s *Scheduler) SetCalendars(calendars *calendar.Registry) {
+	s.calendars = calendars
+}
+
+// AddJob registers a job described by its configuration. Jobs without a
+// timeout get the scheduler's default timeout. Disabled jobs are registered
+// but never scheduled nor run.
+func (s *Scheduler) AddJob(cfg config.JobConfig, jobFunc JobFunc) error {
 	s.jobsMutex.Lock()
 	defer s.jobsMutex.Unlock()
 
-	if _, exists := s.jobs[name]; exists {
-		return fmt.Errorf("job '%s' already exists", name)
+	if _, exists := s.jobs[cfg.Name]; exists {
+		return fmt.Errorf("job '%s' already exists", cfg.Name)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 && s.config != nil {
+		timeout = s.config.DefaultTimeout
+	}
+
+	backoff := cfg.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
 	}
 
 	job := &Job{
-		Name:    name,
-		Func:    jobFunc,
-		Timeout: timeout,
-		Status:  StatusIdle,
+		Name:         cfg.Name,
+		Description:  cfg.Description,
+		Func:         jobFunc,
+		Timeout:      timeout,
+		RetryCount:   cfg.RetryCount,
+		RetryBackoff: backoff,
+		Enabled:      cfg.Enabled,
+		CronExpr:     cfg.CronExpr,
+		Overlap:      cfg.Overlap,
+		DependsOn:    cfg.DependsOn,
+		OnFailure:    cfg.OnFailure,
+		CatchUp:      cfg.CatchUp,
+		CatchUpLimit: cfg.CatchUpLimit,
+		Status:       StatusIdle,
+		config:       cfg,
+		runs:         make(map[*activeRun]struct{}),
+		satisfied:    make(map[string]int64),
 	}
 
-	parser := cron.NewParser(
-		cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow,
-	)
+	if !cfg.Enabled {
+		log.Printf("Job %s is disabled, not scheduling it", cfg.Name)
+		s.jobs[cfg.Name] = job
+		return nil
+	}
 
-	schedule, err := parser.Parse(cronExpr)
-	if err != nil {
-		return fmt.Errorf("invalid cron expression: %w", err)
+	if err := s.scheduleJob(job); err != nil {
+		return err
 	}
+	s.jobs[cfg.Name] = job
+	return nil
+}
 
-	job.NextRun = schedule.Next(time.Now())
+// scheduleJob adds a job to the cron schedule under its current cron
+// expression. Jobs without one only run when triggered, and paused jobs are
+// left out. Callers must hold s.jobsMutex.
+func (s *Scheduler) scheduleJob(job *Job) error {
+	if job.CronExpr == "" || job.Paused {
+		return nil
+	}
 
-	entryID, addErr := s.cron.AddFunc(cronExpr, func() {
-		s.executeJob(name)
-	})
-	if addErr != nil {
-		return fmt.Errorf("failed to schedule job: %w", addErr)
+	cfg := job.config
+	cfg.CronExpr = job.CronExpr
+	schedule, err := s.schedule(cfg)
+	if err != nil {
+		return err
 	}
 
-	job.cronID = entryID
-	s.jobs[name] = job
+	job.schedule = schedule
+	name := job.Name
+	job.entryID = s.runner.Schedule(schedule, func() {
+		s.executeJob(name, TriggerScheduled, 0)
+	})
+	job.NextRun, _ = s.runner.Next(job.entryID)
 	return nil
 }
 
-// executeJob runs a job and updates its status
-func (s *Scheduler) executeJob(name string) {
+// unscheduleJob removes a job from the cron schedule; callers must hold
+// s.jobsMutex
+func (s *Scheduler) unscheduleJob(job *Job) {
+	if job.entryID != 0 {
+		s.runner.Remove(job.entryID)
+		job.entryID = 0
+	}
+	job.schedule = nil
+	job.NextRun = time.Time{}
+}
+
+// cronParser parses cron expressions with a leading seconds field
+var cronParser = cron.NewParser(
+	cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow,
+)
+
+// schedule builds the schedule of a job from its cron expression and, for
+// calendar-aware jobs, the trading sessions of its exchange
+func (s *Scheduler) schedule(cfg config.JobConfig) (cron.Schedule, error) {
+	inSession, err := cronParser.Parse(cfg.CronExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	if cfg.Calendar == nil {
+		return inSession, nil
+	}
+
+	if s.calendars == nil {
+		return nil, fmt.Errorf("job %s uses a calendar but no calendars are loaded", cfg.Name)
+	}
+	cal, ok := s.calendars.Get(cfg.Calendar.Exchange)
+	if !ok {
+		return nil, fmt.Errorf("job %s: unknown exchange calendar: %s", cfg.Name, cfg.Calendar.Exchange)
+	}
+
+	sessions, err := calendar.ParseSessions(cfg.Calendar.Sessions)
+	if err != nil {
+		return nil, fmt.Errorf("job %s: %w", cfg.Name, err)
+	}
+
+	var offSession calendar.Schedule
+	if cfg.Calendar.OffSessionCronExpr != "" {
+		if offSession, err = cronParser.Parse(cfg.Calendar.OffSessionCronExpr); err != nil {
+			return nil, fmt.Errorf("invalid off-session cron expression: %w", err)
+		}
+	}
+
+	return calendar.NewSessionSchedule(cal, sessions, inSession, offSession), nil
+}
+
+// executeJob runs a job under its overlap policy, updates its status, records
+// the run and triggers the jobs downstream of it. parentRunID is the recorded
+// run that triggered this one, if any.
+func (s *Scheduler) executeJob(name, trigger string, parentRunID int64) {
+	job, run, skipReason := s.admit(name, trigger)
+	if run == nil {
+		if skipReason != "" {
+			s.recordSkipped(name, trigger, parentRunID, skipReason)
+		}
+		return
+	}
+	defer s.running.Done()
+
+	startTime := s.clock.Now()
+	record := s.recordStart(name, trigger, parentRunID, startTime)
+	result, attempts, err := s.runWithRetries(run.ctx, job)
+	status := s.finish(job, run, result, attempts, err, s.clock.Since(startTime))
+	s.recordFinish(record, status, result, attempts, err, run.cancelReason)
+
+	var runID int64
+	if record != nil {
+		runID = record.ID
+	}
+	s.triggerDownstream(job, status, runID)
+}
+
+// admit applies the job's overlap policy to a new run, waiting for previous
+// runs to end when the policy asks for it. It returns a nil run when the run
+// must not start, along with the reason to record if it was skipped.
+func (s *Scheduler) admit(name, trigger string) (*Job, *activeRun, string) {
 	s.jobsMutex.Lock()
-	job, exists := s.jobs[name]
-	if !exists {
+	defer s.jobsMutex.Unlock()
+
+	waiting := false // This run holds the job's queue-one slot
+	for {
+		job, exists := s.jobs[name]
+		if !exists {
+			return nil, nil, ""
+		}
+
+		if s.closed || s.ctx.Err() != nil {
+			if waiting {
+				job.queued = false
+			}
+			log.Printf("Not running job %s: scheduler is shutting down", name)
+			return nil, nil, ""
+		}
+
+		if !job.Enabled {
+			log.Printf("Not running %s run of job %s: job is disabled", trigger, name)
+			return nil, nil, ""
+		}
+
+		if len(job.runs) == 0 || job.Overlap == constants.OverlapAllow {
+			if waiting {
+				job.queued = false
+			}
+			return job, s.startRun(job, trigger), ""
+		}
+
+		switch job.Overlap {
+		case constants.OverlapQueueOne:
+			if !waiting {
+				if job.queued {
+					log.Printf("Skipping %s run of job %s: a run is already queued", trigger, name)
+					return nil, nil, "a run is already queued"
+				}
+				job.queued = true
+				waiting = true
+				log.Printf("Queueing %s run of job %s until the current run ends", trigger, name)
+			}
+		case constants.OverlapReplace:
+			for r := range job.runs {
+				if r.cancelReason == "" {
+					r.cancelReason = "replaced by a newer run"
+					r.cancel()
+				}
+			}
+			log.Printf("Cancelling the current run of job %s for a %s run", name, trigger)
+		default:
+			log.Printf("Skipping %s run of job %s: previous run still in progress", trigger, name)
+			return nil, nil, "previous run still in progress"
+		}
+
+		// Wait without holding the lock, then check again
+		idle := job.idle
 		s.jobsMutex.Unlock()
-		return
+		select {
+		case <-idle:
+		case <-s.ctx.Done():
+		}
+		s.jobsMutex.Lock()
 	}
+}
 
+// startRun registers a new run of a job; callers must hold s.jobsMutex
+func (s *Scheduler) startRun(job *Job, trigger string) *activeRun {
+	ctx, cancel := context.WithCancel(context.WithValue(s.ctx, triggerKey{}, trigger))
+	run := &activeRun{ctx: ctx, cancel: cancel}
+
+	if len(job.runs) == 0 {
+		job.idle = make(chan struct{})
+	}
+	job.runs[run] = struct{}{}
 	job.Status = StatusRunning
-	job.LastRun = time.Now()
-	s.jobsMutex.Unlock()
+	job.LastRun = s.clock.Now()
+	s.running.Add(1)
+	return run
+}
 
-	ctx := s.ctx
+// finish unregisters a run and updates the job with its outcome. Returns the
+// status of the run.
+func (s *Scheduler) finish(job *Job, run *activeRun, result *JobResult, attempts int, err error, elapsed time.Duration) JobStatus {
+	s.jobsMutex.Lock()
+	defer s.jobsMutex.Unlock()
+
+	run.cancel()
+	delete(job.runs, run)
+	if len(job.runs) == 0 {
+		close(job.idle)
+	}
+
+	job.LastRunTime = elapsed
+	job.LastAttempts = attempts
+	job.LastResult = result
+	if result != nil {
+		log.Printf("Job %s finished in %s: %d processed, %d failed (%s)",
+			job.Name, elapsed.Round(time.Second), result.ItemsProcessed, result.ItemsFailed, result.Summary)
+	}
+
+	var status JobStatus
+	switch {
+	case run.cancelReason != "":
+		status = StatusCancelled
+		job.LastError = err
+	case errors.Is(err, ErrSkipped):
+		status = StatusSkipped
+	case err != nil:
+		status = StatusFailed
+		job.LastError = err
+	default:
+		status = StatusSucceeded
+		job.LastError = nil
+	}
+
+	// A newer run's status takes precedence over this one's
+	if len(job.runs) == 0 {
+		job.Status = status
+	}
+
+	if next, ok := s.runner.Next(job.entryID); ok {
+		job.NextRun = next
+	}
+	return status
+}
+
+// runWithRetries runs a job, retrying failed attempts with exponential
+// backoff up to the job's retry count. Skipped runs are not retried, nor are
+// runs that were cancelled or interrupted by shutdown. Returns the number of
+// attempts made.
+func (s *Scheduler) runWithRetries(ctx context.Context, job *Job) (*JobResult, int, error) {
+	backoff := job.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		result, err := s.runAttempt(ctx, job)
+		if err == nil || errors.Is(err, ErrSkipped) || attempt > job.RetryCount || ctx.Err() != nil || s.isClosed() {
+			return result, attempt, err
+		}
+
+		log.Printf("Job %s failed (attempt %d of %d), retrying in %s: %v",
+			job.Name, attempt, job.RetryCount+1, backoff, err)
+
+		select {
+		case <-s.clock.After(backoff):
+		case <-ctx.Done():
+			return result, attempt, err
+		}
+
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// runAttempt runs a job once under its timeout
+func (s *Scheduler) runAttempt(ctx context.Context, job *Job) (*JobResult, error) {
 	if job.Timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(s.ctx, job.Timeout)
+		ctx, cancel = s.clock.WithTimeout(ctx, job.Timeout)
 		defer cancel()
 	}
 
-	startTime := time.Now()
-	err := job.Func(ctx)
-	elapsed := time.Since(startTime)
+	return job.Func(ctx)
+}
 
-	s.jobsMutex.Lock()
-	defer s.jobsMutex.Unlock()
+// recordStart records a run that just started, returning nil when there is
+// no history store or the run could not be recorded
+func (s *Scheduler) recordStart(name, trigger string, parentRunID int64, startedAt time.Time) *database.JobRun {
+	if s.history == nil {
+		return nil
+	}
 
-	job, exists = s.jobs[name]
-	if !exists {
+	run := &database.JobRun{
+		JobName:     name,
+		Trigger:     trigger,
+		ParentRunID: parentRunID,
+		Status:      string(StatusRunning),
+		StartedAt:   startedAt,
+		InstanceID:  s.instanceID,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+
+	if err := s.history.StartRun(ctx, run); err != nil {
+		log.Printf("Error recording run of job %s: %v", name, err)
+		return nil
+	}
+	return run
+}
+
+// recordFinish records the outcome of a run recorded by recordStart
+func (s *Scheduler) recordFinish(run *database.JobRun, status JobStatus, result *JobResult, attempts int, err error, cancelReason string) {
+	if run == nil {
 		return
 	}
 
-	job.LastRunTime = elapsed
-	if err != nil {
-		job.Status = StatusFailed
-		job.LastError = err
-	} else {
-		job.Status = StatusSucceeded
-		job.LastError = nil
+	run.FinishedAt = s.clock.Now()
+	run.Attempts = attempts
+	run.Status = string(status)
+	if err != nil && !errors.Is(err, ErrSkipped) {
+		run.Error = err.Error()
 	}
+	if status == StatusCancelled {
+		run.Summary = cancelReason
+	}
+	if result != nil {
+		run.ItemsProcessed = result.ItemsProcessed
+		run.ItemsFailed = result.ItemsFailed
+		run.Summary = result.Summary
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
 
-	entry := s.cron.Entry(job.cronID)
-	job.NextRun = entry.Schedule.Next(time.Now())
+	if err := s.history.FinishRun(ctx, run); err != nil {
+		log.Printf("Error recording outcome of job %s run %d: %v", run.JobName, run.ID, err)
+	}
 }
 
-// RunJob executes a job immediately, regardless of its schedule
-func (s *Scheduler) RunJob(name string) error {
+// recordSkipped records a run that was not started because of the job's
+// overlap policy
+func (s *Scheduler) recordSkipped(name, trigger string, parentRunID int64, reason string) {
+	run := s.recordStart(name, trigger, parentRunID, s.clock.Now())
+	if run == nil {
+		return
+	}
+
+	run.FinishedAt = run.StartedAt
+	run.Status = string(StatusSkipped)
+	run.Summary = reason
+
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+
+	if err := s.history.FinishRun(ctx, run); err != nil {
+		log.Printf("Error recording skipped run of job %s: %v", name, err)
+	}
+}
+
+// abandonStaleRuns marks as abandoned the runs recorded as running that have
+// outlived every attempt the job could make, left behind by an instance that
+// crashed. Jobs without a timeout are left alone.
+func (s *Scheduler) abandonStaleRuns(now time.Time) {
 	s.jobsMutex.RLock()
-	job, exists := s.jobs[name]
+	limits := make(map[string]time.Duration, len(s.jobs))
+	for name, job := range s.jobs {
+		if limit := maxRunTime(job); limit > 0 {
+			limits[name] = limit
+		}
+	}
 	s.jobsMutex.RUnlock()
 
-	if !exists {
-		return fmt.Errorf("job '%s' not found", name)
+	for name, limit := range limits {
+		ctx, cancel := context.WithTimeout(s.ctx, historyTimeout)
+		abandoned, err := s.history.AbandonRuns(ctx, name, now.Add(-limit), now)
+		cancel()
+		if err != nil {
+			log.Printf("Error abandoning stale runs of job %s: %v", name, err)
+		} else if abandoned > 0 {
+			log.Printf("Marked %d runs of job %s as abandoned", abandoned, name)
+		}
+	}
+}
+
+// maxRunTime returns the longest a run of a job can take, every attempt and
+// retry backoff included, or zero when its attempts have no timeout
+func maxRunTime(job *Job) time.Duration {
+	if job.Timeout <= 0 {
+		return 0
 	}
 
-	// Only run if job is not already running
+	total := job.Timeout
+	backoff := job.RetryBackoff
+	for i := 0; i < job.RetryCount; i++ {
+		total += backoff + job.Timeout
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+	return total
+}
+
+// pruneHistory periodically deletes job runs older than the retention
+func (s *Scheduler) pruneHistory() {
+	retention := defaultHistoryRetention
+	if s.config != nil && s.config.HistoryRetention > 0 {
+		retention = s.config.HistoryRetention
+	}
+
+	ticker := s.clock.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(s.ctx, historyTimeout)
+		deleted, err := s.history.PruneRuns(ctx, s.clock.Now().Add(-retention))
+		cancel()
+		if err != nil {
+			log.Printf("Error pruning job history: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Pruned %d job runs older than %s", deleted, retention)
+		}
+
+		select {
+		case <-ticker.C():
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// isClosed reports whether the scheduler stopped accepting job runs
+func (s *Scheduler) isClosed() bool {
+	s.jobsMutex.RLock()
+	defer s.jobsMutex.RUnlock()
+
+	return s.closed
+}
+
+// RunJob executes a job immediately, regardless of its schedule
+func (s *Scheduler) RunJob(name string) error {
 	s.jobsMutex.RLock()
-	isRunning := job.Status == StatusRunning
+	job, exists := s.jobs[name]
+	closed := s.closed
 	s.jobsMutex.RUnlock()
 
-	if isRunning {
-		return fmt.Errorf("job '%s' is already running", name)
+	if closed {
+		return ErrShuttingDown
+	}
+	if !exists {
+		return fmt.Errorf("job '%s': %w", name, ErrJobNotFound)
+	}
+	if !job.Enabled {
+		return fmt.Errorf("job '%s': %w", name, ErrJobDisabled)
 	}
 
-	go s.executeJob(name)
+	// The job's overlap policy decides what happens if it is already running
+	go s.executeJob(name, TriggerManual, 0)
 	return nil
 }
 
 // Start begins the scheduler
 func (s *Scheduler) Start() {
-	s.cron.Start()
+	if s.overrides != nil {
+		s.syncOverrides()
+		go s.watchOverrides()
+	}
+	if s.history != nil {
+		go s.pruneHistory()
+		go s.abandonStaleRuns(s.clock.Now())
+		go s.catchUp(s.clock.Now())
+	}
+	s.runner.Start()
 	log.Println("Scheduler started")
 }
 
+// Close stops firing scheduled jobs and refuses new runs, while letting the
+// runs in progress continue
+func (s *Scheduler) Close() {
+	s.jobsMutex.Lock()
+	s.closed = true
+	s.jobsMutex.Unlock()
+
+	s.runner.Stop()
+	log.Println("Scheduler closed to new job runs")
+}
+
+// Cancel cancels the context of every job run in progress
+func (s *Scheduler) Cancel() {
+	s.cancel()
+}
+
+// Wait blocks until the job runs in progress have returned or ctx is done
+func (s *Scheduler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Stop gracefully shuts down the scheduler
 func (s *Scheduler) Stop() {
 	s.cancel()
-	s.cron.Stop()
+	s.runner.Stop()
 	log.Println("Scheduler stopped")
 }
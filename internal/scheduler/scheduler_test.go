@@ -0,0 +1,456 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/database"
+)
+
+var testStart = time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+// memHistory is a history store kept in memory
+type memHistory struct {
+	mu   sync.Mutex
+	runs []database.JobRun
+	last time.Time // Returned as the last scheduled run of every job
+}
+
+func (h *memHistory) StartRun(_ context.Context, run *database.JobRun) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	run.ID = int64(len(h.runs) + 1)
+	h.runs = append(h.runs, *run)
+	return nil
+}
+
+func (h *memHistory) FinishRun(_ context.Context, run *database.JobRun) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.runs[run.ID-1] = *run
+	return nil
+}
+
+func (h *memHistory) PruneRuns(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (h *memHistory) LastScheduledRunStart(context.Context, string) (time.Time, error) {
+	return h.last, nil
+}
+
+func (h *memHistory) AbandonRuns(_ context.Context, jobName string, startedBefore, finishedAt time.Time) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var abandoned int64
+	for i, run := range h.runs {
+		if run.JobName == jobName && run.Status == string(StatusRunning) && run.StartedAt.Before(startedBefore) {
+			h.runs[i].Status = string(StatusAbandoned)
+			h.runs[i].FinishedAt = finishedAt
+			abandoned++
+		}
+	}
+	return abandoned, nil
+}
+
+// count returns the number of runs matching a predicate
+func (h *memHistory) count(match func(run database.JobRun) bool) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := 0
+	for _, run := range h.runs {
+		if match(run) {
+			n++
+		}
+	}
+	return n
+}
+
+func withStatus(status JobStatus) func(run database.JobRun) bool {
+	return func(run database.JobRun) bool { return run.Status == string(status) }
+}
+
+// newTestScheduler creates a scheduler running on a fake clock
+func newTestScheduler(t *testing.T) (*Scheduler, *clock.Fake) {
+	t.Helper()
+
+	fake := clock.NewFake(testStart)
+	s := NewScheduler(&config.SchedulerConfig{})
+	s.SetClock(fake)
+	t.Cleanup(s.Stop)
+	return s, fake
+}
+
+// waitFor waits for a condition reached by goroutines of the scheduler
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// jobState returns a snapshot of a job, failing the test if it does not exist
+func jobState(t *testing.T, s *Scheduler, name string) JobInfo {
+	t.Helper()
+
+	job, err := s.Job(name)
+	if err != nil {
+		t.Fatalf("Expected job %s, got %v", name, err)
+	}
+	return job
+}
+
+func TestScheduledJobFiresOnCron(t *testing.T) {
+	s, fake := newTestScheduler(t)
+
+	var runs int32
+	err := s.AddJob(config.JobConfig{Name: "dispatch", CronExpr: "0 */5 * * * *", Enabled: true},
+		func(context.Context) (*JobResult, error) {
+			atomic.AddInt32(&runs, 1)
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+
+	if next := jobState(t, s, "dispatch").NextRun; !next.Equal(testStart.Add(5 * time.Minute)) {
+		t.Errorf("Expected next run at %s, got %s", testStart.Add(5*time.Minute), next)
+	}
+
+	s.Start()
+	fake.BlockUntil(1)
+	fake.Advance(4 * time.Minute)
+	if n := atomic.LoadInt32(&runs); n != 0 {
+		t.Fatalf("Expected no run before the schedule, got %d", n)
+	}
+
+	fake.Advance(time.Minute)
+	waitFor(t, "first run", func() bool { return atomic.LoadInt32(&runs) == 1 })
+
+	fake.BlockUntil(1)
+	fake.Advance(5 * time.Minute)
+	waitFor(t, "second run", func() bool { return atomic.LoadInt32(&runs) == 2 })
+
+	waitFor(t, "next run", func() bool {
+		return jobState(t, s, "dispatch").NextRun.Equal(testStart.Add(15 * time.Minute))
+	})
+}
+
+func TestJobTimeout(t *testing.T) {
+	s, fake := newTestScheduler(t)
+
+	err := s.AddJob(config.JobConfig{Name: "slow", Timeout: time.Minute, Enabled: true},
+		func(ctx context.Context) (*JobResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	if err := s.RunJob("slow"); err != nil {
+		t.Fatalf("Expected no error running job, got %v", err)
+	}
+
+	// The only waiter is the job's timeout
+	fake.BlockUntil(1)
+	fake.Advance(59 * time.Second)
+	if status := jobState(t, s, "slow").Status; status != StatusRunning {
+		t.Fatalf("Expected job running before its timeout, got %s", status)
+	}
+
+	fake.Advance(time.Second)
+	waitFor(t, "job to fail", func() bool { return jobState(t, s, "slow").Status == StatusFailed })
+
+	job := jobState(t, s, "slow")
+	if job.LastError != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected error %q, got %q", context.DeadlineExceeded, job.LastError)
+	}
+	if job.LastRunTime != time.Minute {
+		t.Errorf("Expected run time 1m0s, got %s", job.LastRunTime)
+	}
+}
+
+func TestJobRetriesWithBackoff(t *testing.T) {
+	s, fake := newTestScheduler(t)
+
+	var attempts int32
+	err := s.AddJob(config.JobConfig{Name: "flaky", RetryCount: 2, RetryBackoff: 10 * time.Second, Enabled: true},
+		func(context.Context) (*JobResult, error) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				return nil, errors.New("source unavailable")
+			}
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	if err := s.RunJob("flaky"); err != nil {
+		t.Fatalf("Expected no error running job, got %v", err)
+	}
+
+	// Backoff doubles between retries
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Second)
+	fake.BlockUntil(1)
+	fake.Advance(19 * time.Second)
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Fatalf("Expected 2 attempts before the second backoff elapsed, got %d", n)
+	}
+	fake.Advance(time.Second)
+
+	waitFor(t, "job to succeed", func() bool { return jobState(t, s, "flaky").Status == StatusSucceeded })
+	if job := jobState(t, s, "flaky"); job.LastAttempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", job.LastAttempts)
+	}
+}
+
+func TestOverlapSkip(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	history := &memHistory{}
+	s.SetHistory(history, "test")
+
+	release := make(chan struct{})
+	var started int32
+	err := s.AddJob(config.JobConfig{Name: "scrape", Enabled: true},
+		func(context.Context) (*JobResult, error) {
+			atomic.AddInt32(&started, 1)
+			<-release
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	_ = s.RunJob("scrape")
+	waitFor(t, "first run", func() bool { return atomic.LoadInt32(&started) == 1 })
+
+	_ = s.RunJob("scrape")
+	waitFor(t, "skipped run", func() bool { return history.count(withStatus(StatusSkipped)) == 1 })
+
+	close(release)
+	waitFor(t, "run to end", func() bool { return history.count(withStatus(StatusSucceeded)) == 1 })
+	if n := atomic.LoadInt32(&started); n != 1 {
+		t.Errorf("Expected 1 run started, got %d", n)
+	}
+}
+
+func TestOverlapQueueOne(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	history := &memHistory{}
+	s.SetHistory(history, "test")
+
+	release := make(chan struct{})
+	var started int32
+	err := s.AddJob(config.JobConfig{Name: "consume", Overlap: constants.OverlapQueueOne, Enabled: true},
+		func(context.Context) (*JobResult, error) {
+			atomic.AddInt32(&started, 1)
+			<-release
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	_ = s.RunJob("consume")
+	waitFor(t, "first run", func() bool { return atomic.LoadInt32(&started) == 1 })
+
+	_ = s.RunJob("consume")
+	waitFor(t, "queued run", func() bool { return jobState(t, s, "consume").Queued })
+
+	// Only one run waits, the next one is skipped
+	_ = s.RunJob("consume")
+	waitFor(t, "skipped run", func() bool { return history.count(withStatus(StatusSkipped)) == 1 })
+
+	close(release)
+	waitFor(t, "both runs to end", func() bool { return history.count(withStatus(StatusSucceeded)) == 2 })
+	if n := atomic.LoadInt32(&started); n != 2 {
+		t.Errorf("Expected 2 runs started, got %d", n)
+	}
+}
+
+func TestOverlapReplace(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	history := &memHistory{}
+	s.SetHistory(history, "test")
+
+	var started int32
+	err := s.AddJob(config.JobConfig{Name: "refresh", Overlap: constants.OverlapReplace, Enabled: true},
+		func(ctx context.Context) (*JobResult, error) {
+			if atomic.AddInt32(&started, 1) == 1 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	_ = s.RunJob("refresh")
+	waitFor(t, "first run", func() bool { return atomic.LoadInt32(&started) == 1 })
+
+	_ = s.RunJob("refresh")
+	waitFor(t, "replacing run to end", func() bool { return history.count(withStatus(StatusSucceeded)) == 1 })
+
+	if n := history.count(withStatus(StatusCancelled)); n != 1 {
+		t.Errorf("Expected 1 cancelled run, got %d", n)
+	}
+}
+
+func TestCloseStopsNewRunsAndWaitsForRunsInProgress(t *testing.T) {
+	s, fake := newTestScheduler(t)
+
+	release := make(chan struct{})
+	var started int32
+	err := s.AddJob(config.JobConfig{Name: "consume", CronExpr: "0 * * * * *", Enabled: true},
+		func(context.Context) (*JobResult, error) {
+			atomic.AddInt32(&started, 1)
+			<-release
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	waitFor(t, "scheduled run", func() bool { return atomic.LoadInt32(&started) == 1 })
+
+	s.Close()
+	if err := s.RunJob("consume"); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected %v, got %v", ErrShuttingDown, err)
+	}
+
+	// The schedule no longer fires
+	fake.Advance(5 * time.Minute)
+
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Wait(expired); err == nil {
+		t.Error("Expected Wait to give up while a run is in progress")
+	}
+
+	close(release)
+	if err := s.Wait(context.Background()); err != nil {
+		t.Errorf("Expected no error waiting for runs, got %v", err)
+	}
+	if n := atomic.LoadInt32(&started); n != 1 {
+		t.Errorf("Expected 1 run after closing, got %d", n)
+	}
+}
+
+func TestCancelInterruptsRunsInProgress(t *testing.T) {
+	s, _ := newTestScheduler(t)
+
+	var started int32
+	err := s.AddJob(config.JobConfig{Name: "scrape", Enabled: true},
+		func(ctx context.Context) (*JobResult, error) {
+			atomic.AddInt32(&started, 1)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	_ = s.RunJob("scrape")
+	waitFor(t, "run", func() bool { return atomic.LoadInt32(&started) == 1 })
+
+	s.Close()
+	s.Cancel()
+	if err := s.Wait(context.Background()); err != nil {
+		t.Errorf("Expected no error waiting for runs, got %v", err)
+	}
+}
+
+func TestCatchUpReplaysMissedRunsUpToLimit(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	history := &memHistory{last: testStart.Add(-10 * time.Minute)}
+	s.SetHistory(history, "test")
+
+	err := s.AddJob(config.JobConfig{
+		Name:         "dispatch",
+		CronExpr:     "0 * * * * *",
+		Enabled:      true,
+		CatchUp:      constants.CatchUpAll,
+		CatchUpLimit: 3,
+	}, func(context.Context) (*JobResult, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	caughtUp := func(run database.JobRun) bool {
+		return run.Trigger == TriggerCatchUp && run.Status == string(StatusSucceeded)
+	}
+	waitFor(t, "catch-up runs", func() bool { return history.count(caughtUp) == 3 })
+
+	// Give a fourth run the chance to show up
+	time.Sleep(10 * time.Millisecond)
+	if n := history.count(caughtUp); n != 3 {
+		t.Errorf("Expected 3 catch-up runs, got %d", n)
+	}
+}
+
+func TestStartAbandonsStaleRuns(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	history := &memHistory{runs: []database.JobRun{
+		// Longer than a minute attempt, a 10s backoff and a second attempt
+		{ID: 1, JobName: "scrape", Status: string(StatusRunning), StartedAt: testStart.Add(-3 * time.Minute)},
+		// Possibly still running on another instance
+		{ID: 2, JobName: "scrape", Status: string(StatusRunning), StartedAt: testStart.Add(-2 * time.Minute)},
+		{ID: 3, JobName: "scrape", Status: string(StatusSucceeded), StartedAt: testStart.Add(-time.Hour)},
+		// No timeout, so never abandoned
+		{ID: 4, JobName: "unbounded", Status: string(StatusRunning), StartedAt: testStart.Add(-24 * time.Hour)},
+	}}
+	s.SetHistory(history, "test")
+
+	noop := func(context.Context) (*JobResult, error) { return nil, nil }
+	if err := s.AddJob(config.JobConfig{Name: "scrape", Timeout: time.Minute, RetryCount: 1, RetryBackoff: 10 * time.Second, Enabled: true}, noop); err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	if err := s.AddJob(config.JobConfig{Name: "unbounded", Enabled: true}, noop); err != nil {
+		t.Fatalf("Expected no error adding job, got %v", err)
+	}
+	s.Start()
+
+	waitFor(t, "abandoned runs", func() bool { return history.count(withStatus(StatusAbandoned)) == 1 })
+
+	history.mu.Lock()
+	defer history.mu.Unlock()
+	if history.runs[0].Status != string(StatusAbandoned) || !history.runs[0].FinishedAt.Equal(testStart) {
+		t.Errorf("Expected run 1 abandoned at %v, got %+v", testStart, history.runs[0])
+	}
+	for _, run := range history.runs[1:] {
+		if run.Status == string(StatusAbandoned) {
+			t.Errorf("Expected run %d to be left alone, got %s", run.ID, run.Status)
+		}
+	}
+}
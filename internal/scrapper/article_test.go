@@ -1,6 +1,9 @@
 package scraper
 
 import (
+	"errors"
+	"fmt"
+	neturl "net/url"
 	"testing"
 	"time"
 
@@ -98,3 +101,24 @@ func TestResetArticles(t *testing.T) {
 		t.Errorf("Expected 0 articles after reset, got %d", len(s.articles))
 	}
 }
+
+func TestRedirectedToConsent(t *testing.T) {
+	redirect := func(target string) error {
+		err := &neturl.Error{
+			Op:  "Get",
+			URL: target,
+			Err: errors.New("Not following redirect because its not in AllowedDomains"),
+		}
+		return fmt.Errorf("error scraping yahoo: %w", err)
+	}
+
+	if !redirectedToConsent(redirect("https://consent.yahoo.com/v2/collectConsent?sessionId=1")) {
+		t.Errorf("Expected a redirect to a consent host to be detected")
+	}
+	if redirectedToConsent(redirect("https://login.yahoo.com/")) {
+		t.Errorf("Expected a redirect to another host not to be detected")
+	}
+	if redirectedToConsent(errors.New("redirect to consent.yahoo.com failed")) {
+		t.Errorf("Expected an untyped error not to be detected")
+	}
+}
@@ -0,0 +1,266 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+const (
+	defaultBreakerWindow      = 20
+	defaultBreakerMinRequests = 10
+	defaultBreakerFailureRate = 0.5
+	defaultOpenDuration       = time.Minute
+	defaultMaxOpenDuration    = 15 * time.Minute
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Scrapes go through
+	BreakerOpen     BreakerState = "open"      // Scrapes are rejected
+	BreakerHalfOpen BreakerState = "half-open" // A single probe is in flight
+)
+
+// ErrCircuitOpen is returned for scrapes rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker suspends scraping a source that keeps failing, and probes it
+// periodically so scraping resumes automatically once it recovers
+type CircuitBreaker struct {
+	source string
+	cfg    config.CircuitBreakerConfig
+	now    func() time.Time
+
+	mu          sync.Mutex
+	state       BreakerState
+	outcomes    []bool // Ring buffer of recent outcomes, true for failures
+	next        int
+	count       int
+	openedAt    time.Time
+	openFor     time.Duration
+	probing     bool
+	trips       int64
+	rejected    int64
+	lastErr     string
+	lastChanged time.Time
+}
+
+// BreakerStats describes the state of a circuit breaker
+type BreakerStats struct {
+	Source      string       `json:"source"`
+	State       BreakerState `json:"state"`
+	Requests    int          `json:"requests"` // Scrapes in the window
+	Failures    int          `json:"failures"` // Failed scrapes in the window
+	FailureRate float64      `json:"failure_rate"`
+	Trips       int64        `json:"trips"`
+	Rejected    int64        `json:"rejected"`
+	OpenedAt    time.Time    `json:"opened_at,omitempty"`
+	NextProbeAt time.Time    `json:"next_probe_at,omitempty"`
+	LastError   string       `json:"last_error,omitempty"`
+	LastChanged time.Time    `json:"last_changed,omitempty"`
+}
+
+// NewCircuitBreaker creates a closed circuit breaker for a source
+func NewCircuitBreaker(source string, cfg config.CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = defaultBreakerWindow
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = defaultBreakerMinRequests
+	}
+	if cfg.MinRequests > cfg.WindowSize {
+		cfg.MinRequests = cfg.WindowSize
+	}
+	if cfg.FailureRate <= 0 || cfg.FailureRate > 1 {
+		cfg.FailureRate = defaultBreakerFailureRate
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = defaultOpenDuration
+	}
+	if cfg.MaxOpenDuration < cfg.OpenDuration {
+		cfg.MaxOpenDuration = defaultMaxOpenDuration
+		if cfg.MaxOpenDuration < cfg.OpenDuration {
+			cfg.MaxOpenDuration = cfg.OpenDuration
+		}
+	}
+
+	return &CircuitBreaker{
+		source:   source,
+		cfg:      cfg,
+		now:      time.Now,
+		state:    BreakerClosed,
+		outcomes: make([]bool, cfg.WindowSize),
+	}
+}
+
+// Allow reports whether a scrape may go through. Once the open period has
+// elapsed, a single caller is let through as a probe.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Before(b.openedAt.Add(b.openFor)) {
+			b.rejected++
+			return fmt.Errorf("%w for source %s", ErrCircuitOpen, b.source)
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			b.rejected++
+			return fmt.Errorf("%w for source %s", ErrCircuitOpen, b.source)
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record reports the outcome of a scrape that Allow let through. Cancelled
+// scrapes say nothing about the source and are ignored, while consent pages
+// trip the breaker at once since every following scrape would hit them too.
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		if b.state == BreakerHalfOpen {
+			b.probing = false
+		}
+		return
+	}
+
+	failed := err != nil
+	if failed {
+		b.lastErr = err.Error()
+	}
+
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+		if failed {
+			b.trip(b.openFor * 2)
+			return
+		}
+		b.reset()
+		b.setState(BreakerClosed)
+		return
+	}
+
+	if b.state != BreakerClosed {
+		return
+	}
+
+	b.outcomes[b.next] = failed
+	b.next = (b.next + 1) % len(b.outcomes)
+	if b.count < len(b.outcomes) {
+		b.count++
+	}
+
+	if errors.Is(err, ErrConsentPage) {
+		b.trip(b.cfg.OpenDuration)
+		return
+	}
+
+	requests, failures := b.window()
+	if requests >= b.cfg.MinRequests && float64(failures)/float64(requests) >= b.cfg.FailureRate {
+		b.trip(b.cfg.OpenDuration)
+	}
+}
+
+// Stats returns the current state of the breaker
+func (b *CircuitBreaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	requests, failures := b.window()
+	stats := BreakerStats{
+		Source:      b.source,
+		State:       b.state,
+		Requests:    requests,
+		Failures:    failures,
+		Trips:       b.trips,
+		Rejected:    b.rejected,
+		LastError:   b.lastErr,
+		LastChanged: b.lastChanged,
+	}
+	if requests > 0 {
+		stats.FailureRate = float64(failures) / float64(requests)
+	}
+	if b.state != BreakerClosed {
+		stats.OpenedAt = b.openedAt
+		stats.NextProbeAt = b.openedAt.Add(b.openFor)
+	}
+	return stats
+}
+
+// RetryAfter returns how long until the breaker lets a probe through, or zero
+// when scrapes may go through now
+func (b *CircuitBreaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerOpen {
+		return 0
+	}
+	wait := b.openedAt.Add(b.openFor).Sub(b.now())
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// trip opens the breaker for d, capped at the maximum open duration; callers must hold b.mu
+func (b *CircuitBreaker) trip(d time.Duration) {
+	if d > b.cfg.MaxOpenDuration {
+		d = b.cfg.MaxOpenDuration
+	}
+	b.openedAt = b.now()
+	b.openFor = d
+	b.trips++
+	b.setState(BreakerOpen)
+	log.Printf("Circuit breaker for source %s opened for %s, last error: %s", b.source, d, b.lastErr)
+}
+
+// reset clears the outcome window; callers must hold b.mu
+func (b *CircuitBreaker) reset() {
+	for i := range b.outcomes {
+		b.outcomes[i] = false
+	}
+	b.next = 0
+	b.count = 0
+	b.openFor = 0
+}
+
+// setState changes the state and logs the transition; callers must hold b.mu
+func (b *CircuitBreaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	if state != BreakerOpen {
+		log.Printf("Circuit breaker for source %s is %s", b.source, state)
+	}
+	b.state = state
+	b.lastChanged = b.now()
+}
+
+// window returns the number of recorded and failed scrapes; callers must hold b.mu
+func (b *CircuitBreaker) window() (int, int) {
+	failures := 0
+	for i := 0; i < b.count; i++ {
+		if b.outcomes[i] {
+			failures++
+		}
+	}
+	return b.count, failures
+}
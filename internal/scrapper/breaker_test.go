@@ -0,0 +1,109 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+func newTestBreaker(now *time.Time) *CircuitBreaker {
+	b := NewCircuitBreaker("test", config.CircuitBreakerConfig{
+		WindowSize:      4,
+		MinRequests:     4,
+		FailureRate:     0.5,
+		OpenDuration:    time.Minute,
+		MaxOpenDuration: 3 * time.Minute,
+	})
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func TestBreakerTripsOnFailureRate(t *testing.T) {
+	now := time.Now()
+	b := newTestBreaker(&now)
+	scrapeErr := errors.New("status 500")
+
+	for _, err := range []error{nil, scrapeErr, nil} {
+		if allowErr := b.Allow(); allowErr != nil {
+			t.Fatalf("Expected scrape to be allowed, got %v", allowErr)
+		}
+		b.Record(err)
+	}
+	if b.Stats().State != BreakerClosed {
+		t.Errorf("Expected breaker to stay closed below min requests, got %s", b.Stats().State)
+	}
+
+	b.Record(scrapeErr)
+	if b.Stats().State != BreakerOpen {
+		t.Fatalf("Expected breaker to open at 2/4 failures, got %s", b.Stats().State)
+	}
+
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if b.Stats().Rejected != 1 {
+		t.Errorf("Expected 1 rejected scrape, got %d", b.Stats().Rejected)
+	}
+}
+
+func TestBreakerProbeRecovers(t *testing.T) {
+	now := time.Now()
+	b := newTestBreaker(&now)
+	b.Record(fmt.Errorf("%w: redirect", ErrConsentPage))
+	if b.Stats().State != BreakerOpen {
+		t.Fatalf("Expected consent page to open the breaker, got %s", b.Stats().State)
+	}
+
+	now = now.Add(time.Minute)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Expected probe to be allowed, got %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected a second caller to be rejected during the probe, got %v", err)
+	}
+
+	b.Record(nil)
+	stats := b.Stats()
+	if stats.State != BreakerClosed {
+		t.Errorf("Expected successful probe to close the breaker, got %s", stats.State)
+	}
+	if stats.Requests != 0 {
+		t.Errorf("Expected window to be reset, got %d requests", stats.Requests)
+	}
+}
+
+func TestBreakerFailedProbeBacksOff(t *testing.T) {
+	now := time.Now()
+	b := newTestBreaker(&now)
+	b.Record(ErrConsentPage)
+
+	for _, want := range []time.Duration{2 * time.Minute, 3 * time.Minute} {
+		now = now.Add(b.RetryAfter())
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Expected probe to be allowed, got %v", err)
+		}
+		b.Record(errors.New("status 503"))
+
+		if got := b.RetryAfter(); got != want {
+			t.Errorf("Expected to wait %s after a failed probe, got %s", want, got)
+		}
+	}
+}
+
+func TestBreakerIgnoresCancellation(t *testing.T) {
+	now := time.Now()
+	b := newTestBreaker(&now)
+
+	for i := 0; i < 4; i++ {
+		b.Record(context.Canceled)
+	}
+
+	stats := b.Stats()
+	if stats.State != BreakerClosed || stats.Requests != 0 {
+		t.Errorf("Expected cancelled scrapes to be ignored, got state %s with %d requests", stats.State, stats.Requests)
+	}
+}
@@ -2,10 +2,13 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	neturl "net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/guillermoballester/propagatorGo/internal/config"
@@ -17,6 +20,10 @@ import (
 
 const Yahoo = "yahoo"
 
+// ErrConsentPage is returned when a site answers with a cookie consent page
+// instead of the requested content
+var ErrConsentPage = errors.New("redirected to a consent page")
+
 // SiteConfig stores the selector configuration for each website
 type SiteConfig struct {
 	Name                 string   `json:"name"`
@@ -77,16 +84,47 @@ func (s *NewsScraper) Scrape(ctx context.Context, symbol string) ([]model.Articl
 	log.Printf("Scraping %s for symbol %s from URL: %s", s.config.Name, symbol, url)
 	s.registerHTMLHandlers(ctxCollector)
 
+	// Sites that require consent redirect there instead of serving the page
+	var consent int32
+	ctxCollector.OnResponse(func(r *colly.Response) {
+		if isConsentHost(r.Request.URL.Host) {
+			atomic.StoreInt32(&consent, 1)
+		}
+	})
+
 	done, errChan := s.startScraping(ctx, ctxCollector, url)
 
 	err := s.waitForCompletion(ctx, done, errChan, ctxCollector)
 	if err != nil {
+		// Redirects outside the allowed domains fail before any response
+		if redirectedToConsent(err) {
+			return s.GetArticles(), fmt.Errorf("%w: %v", ErrConsentPage, err)
+		}
 		return s.GetArticles(), err
 	}
+	if atomic.LoadInt32(&consent) == 1 {
+		return nil, fmt.Errorf("%w while scraping %s for %s", ErrConsentPage, s.config.Name, symbol)
+	}
 
 	return s.GetArticles(), nil
 }
 
+// isConsentHost reports whether a host serves cookie consent pages
+func isConsentHost(host string) bool {
+	return strings.HasPrefix(strings.ToLower(host), "consent.")
+}
+
+// redirectedToConsent reports whether a scrape failed on a redirect to a
+// consent page, which net/http reports with the redirect target as its URL
+func redirectedToConsent(err error) bool {
+	var urlErr *neturl.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	target, parseErr := neturl.Parse(urlErr.URL)
+	return parseErr == nil && isConsentHost(target.Host)
+}
+
 // buildURL replaces template parameters in the URL
 func (s *NewsScraper) buildURL(symbol string) string {
 	url := s.config.URL
@@ -141,6 +179,13 @@ func (s *NewsScraper) startScraping(ctx context.Context, collector *colly.Collec
 	go func() {
 		defer wg.Done()
 
+		// A panic in a colly callback would otherwise take down the whole process
+		defer func() {
+			if r := recover(); r != nil {
+				errChan <- fmt.Errorf("panic scraping %s: %v", s.config.Name, r)
+			}
+		}()
+
 		select {
 		case <-ctx.Done():
 			errChan <- ctx.Err()
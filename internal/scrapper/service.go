@@ -19,6 +19,8 @@ type Service struct {
 	taskService   *task.Service
 	scrapersMutex sync.RWMutex
 	scrapers      map[string]*NewsScraper
+	breakersMutex sync.Mutex
+	breakers      map[string]*CircuitBreaker
 }
 
 // NewScraperService creates a new scraper service
@@ -28,17 +30,26 @@ func NewScraperService(cfg *config.Config, redis *queue.RedisClient, taskSvc *ta
 		redisClient: redis,
 		taskService: taskSvc,
 		scrapers:    make(map[string]*NewsScraper),
+		breakers:    make(map[string]*CircuitBreaker),
 	}
 }
 
-// ScrapeAndPublish performs both scraping and publishing in one operation
+// ScrapeAndPublish performs both scraping and publishing in one operation.
+// Scrapes of a source whose circuit breaker is open fail with ErrCircuitOpen.
 func (s *Service) ScrapeAndPublish(ctx context.Context, source string, symbol string) ([]model.ArticleData, error) {
 	// Get the scraper for this source
 	scraper, err := s.GetScraper(source)
 	if err != nil {
 		return nil, fmt.Errorf("error getting scraper: %w", err)
 	}
+
+	breaker := s.Breaker(source)
+	if err := breaker.Allow(); err != nil {
+		return nil, err
+	}
+
 	articles, err := scraper.Scrape(ctx, symbol)
+	breaker.Record(err)
 	if err != nil {
 		return nil, fmt.Errorf("error scraping: %w", err)
 	}
@@ -56,6 +67,31 @@ func (s *Service) ScrapeAndPublish(ctx context.Context, source string, symbol st
 	return articles, nil
 }
 
+// Breaker returns (or creates) the circuit breaker of a source
+func (s *Service) Breaker(source string) *CircuitBreaker {
+	s.breakersMutex.Lock()
+	defer s.breakersMutex.Unlock()
+
+	breaker, exists := s.breakers[source]
+	if !exists {
+		breaker = NewCircuitBreaker(source, s.config.Scraper.CircuitBreaker)
+		s.breakers[source] = breaker
+	}
+	return breaker
+}
+
+// BreakerStats returns the state of the circuit breaker of every source scraped so far
+func (s *Service) BreakerStats() []BreakerStats {
+	s.breakersMutex.Lock()
+	defer s.breakersMutex.Unlock()
+
+	stats := make([]BreakerStats, 0, len(s.breakers))
+	for _, breaker := range s.breakers {
+		stats = append(stats, breaker.Stats())
+	}
+	return stats
+}
+
 // GetScraper returns (or creates) a scraper for a specific source
 func (s *Service) GetScraper(source string) (*NewsScraper, error) {
 	s.scrapersMutex.RLock()
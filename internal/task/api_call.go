@@ -0,0 +1,189 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+)
+
+// APICall describes an HTTP request carried by an api_call task, and where
+// its response goes. Handler and FollowUp override the pool's defaults.
+type APICall struct {
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           string            `json:"body,omitempty"`
+	ExpectedStatus []int             `json:"expected_status,omitempty"` // Any 2xx when empty
+	Timeout        time.Duration     `json:"timeout,omitempty"`
+	MaxAttempts    int               `json:"max_attempts,omitempty"` // Attempts for network errors, 429 and 5xx
+	Handler        string            `json:"handler,omitempty"`      // Registered handler receiving the response
+	FollowUp       string            `json:"follow_up,omitempty"`    // Task type the response is enqueued as
+	Symbol         string            `json:"symbol,omitempty"`       // Optional, for stats breakdowns
+	Source         string            `json:"source,omitempty"`       // Optional, for stats breakdowns
+}
+
+// APIResponse is the outcome of an API call, passed to handlers and
+// follow-up tasks
+type APIResponse struct {
+	Call       APICall           `json:"call"`
+	Status     int               `json:"status"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body"`
+	Duration   time.Duration     `json:"duration"`
+	ReceivedAt time.Time         `json:"received_at"`
+}
+
+// NewAPICall creates a call from its configuration in an apiCall job
+func NewAPICall(cfg config.APICallConfig) APICall {
+	return APICall{
+		Method:         cfg.Method,
+		URL:            cfg.URL,
+		Headers:        cfg.Headers,
+		Body:           cfg.Body,
+		ExpectedStatus: cfg.ExpectedStatus,
+		Timeout:        cfg.Timeout,
+		MaxAttempts:    cfg.MaxAttempts,
+		Handler:        cfg.Handler,
+		FollowUp:       cfg.FollowUp,
+		Source:         cfg.Source,
+	}
+}
+
+// Expects reports whether a status code is one the call expects
+func (c *APICall) Expects(status int) bool {
+	if len(c.ExpectedStatus) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, expected := range c.ExpectedStatus {
+		if status == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the call can be executed
+func (c *APICall) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("api call has no URL")
+	}
+	switch c.Method {
+	case "", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead:
+		return nil
+	default:
+		return fmt.Errorf("unsupported HTTP method: %s", c.Method)
+	}
+}
+
+// CreateAPICallTask creates a new api_call task for an HTTP request
+func (s *Service) CreateAPICallTask(call APICall) *Task {
+	task := NewTask(constants.TaskTypeAPICall)
+	task.SetParam("call", call)
+	task.SetParam("attempt", 1)
+	return task
+}
+
+// symbolPlaceholder is replaced by each enabled stock symbol in templated calls
+const symbolPlaceholder = "{symbol}"
+
+// EnqueueAPICalls adds an api_call task per call. Calls whose URL, headers or
+// body contain {symbol} are expanded into one task per enabled stock.
+// Returns the number of tasks added.
+func (s *Service) EnqueueAPICalls(ctx context.Context, calls []APICall) (int, error) {
+	var tasksAdded int
+	for _, call := range calls {
+		for _, expanded := range s.expandAPICall(call) {
+			if err := expanded.Validate(); err != nil {
+				return tasksAdded, err
+			}
+			if err := s.EnqueueTask(ctx, s.CreateAPICallTask(expanded)); err != nil {
+				log.Printf("Error enqueueing api call %s %s: %v", expanded.Method, expanded.URL, err)
+				continue
+			}
+			tasksAdded++
+		}
+	}
+
+	log.Printf("Added %d tasks to %s queue", tasksAdded, QueueName(constants.TaskTypeAPICall))
+	return tasksAdded, nil
+}
+
+// expandAPICall returns one call per enabled stock for templated calls, or
+// the call itself
+func (s *Service) expandAPICall(call APICall) []APICall {
+	templated := strings.Contains(call.URL, symbolPlaceholder) || strings.Contains(call.Body, symbolPlaceholder)
+	for _, value := range call.Headers {
+		templated = templated || strings.Contains(value, symbolPlaceholder)
+	}
+	if !templated {
+		return []APICall{call}
+	}
+
+	var calls []APICall
+	for _, stock := range s.config.StockList.Stocks {
+		if !stock.Enabled {
+			continue
+		}
+
+		expanded := call
+		expanded.Symbol = stock.Symbol
+		expanded.URL = strings.ReplaceAll(call.URL, symbolPlaceholder, stock.Symbol)
+		expanded.Body = strings.ReplaceAll(call.Body, symbolPlaceholder, stock.Symbol)
+		expanded.Headers = make(map[string]string, len(call.Headers))
+		for key, value := range call.Headers {
+			expanded.Headers[key] = strings.ReplaceAll(value, symbolPlaceholder, stock.Symbol)
+		}
+		calls = append(calls, expanded)
+	}
+	return calls
+}
+
+// GetAPICall extracts the HTTP request from an api_call task
+func (t *Task) GetAPICall() (*APICall, error) {
+	if t.Type != constants.TaskTypeAPICall {
+		return nil, fmt.Errorf("task is not an api_call task")
+	}
+
+	callData, ok := t.Params["call"]
+	if !ok {
+		return nil, fmt.Errorf("api call not found in task")
+	}
+
+	// Params hold the decoded JSON, so round-trip them into the struct
+	callJSON, err := json.Marshal(callData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal api call: %w", err)
+	}
+
+	var call APICall
+	if err := json.Unmarshal(callJSON, &call); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal api call: %w", err)
+	}
+
+	return &call, nil
+}
+
+// Attempt returns how many times the task has been tried, starting at 1
+func (t *Task) Attempt() int {
+	value, ok := t.Params["attempt"]
+	if !ok {
+		return 1
+	}
+
+	// Numbers decode from JSON as float64
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 1
+	}
+}
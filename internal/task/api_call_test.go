@@ -0,0 +1,204 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+)
+
+// memoryQueue keeps enqueued tasks in memory, one JSON message per task
+type memoryQueue struct {
+	queues map[string][][]byte
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{queues: make(map[string][][]byte)}
+}
+
+func (q *memoryQueue) Enqueue(ctx context.Context, queueName string, task interface{}) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	q.queues[queueName] = append(q.queues[queueName], data)
+	return nil
+}
+
+func (q *memoryQueue) EnqueueIfEmpty(ctx context.Context, queueName string, tasks []interface{}) (bool, error) {
+	if len(q.queues[queueName]) > 0 {
+		return false, nil
+	}
+	for _, task := range tasks {
+		if err := q.Enqueue(ctx, queueName, task); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (q *memoryQueue) Dequeue(ctx context.Context, queueName string, timeout int) ([]byte, error) {
+	// Like BLPOP once its timeout elapsed
+	if len(q.queues[queueName]) == 0 {
+		return nil, nil
+	}
+	data := q.queues[queueName][0]
+	q.queues[queueName] = q.queues[queueName][1:]
+	return data, nil
+}
+
+func (q *memoryQueue) QueueLength(ctx context.Context, queueName string) (int64, error) {
+	return int64(len(q.queues[queueName])), nil
+}
+
+func (q *memoryQueue) ClearQueue(ctx context.Context, queueName string) error {
+	delete(q.queues, queueName)
+	return nil
+}
+
+// dequeuedCalls decodes the api calls waiting on a queue
+func dequeuedCalls(t *testing.T, q *memoryQueue) []APICall {
+	var calls []APICall
+	for _, data := range q.queues[QueueName(constants.TaskTypeAPICall)] {
+		var task Task
+		if err := json.Unmarshal(data, &task); err != nil {
+			t.Fatalf("Expected a task, got %v", err)
+		}
+		call, err := task.GetAPICall()
+		if err != nil {
+			t.Fatalf("Expected an api call, got %v", err)
+		}
+		calls = append(calls, *call)
+	}
+	return calls
+}
+
+func TestEnqueueAPICallsExpandsSymbols(t *testing.T) {
+	cfg := &config.Config{StockList: config.StockList{Stocks: []config.Stock{
+		{Symbol: "AAPL", Enabled: true},
+		{Symbol: "TSLA", Enabled: false},
+		{Symbol: "MSFT", Enabled: true},
+	}}}
+	q := newMemoryQueue()
+	s := NewService(cfg, q)
+
+	added, err := s.EnqueueAPICalls(context.Background(), []APICall{
+		{
+			URL:     "https://api.example.com/quote/{symbol}",
+			Headers: map[string]string{"X-Symbol": "{symbol}", "Accept": "application/json"},
+			Body:    `{"ticker":"{symbol}"}`,
+		},
+		{URL: "https://api.example.com/market"},
+	})
+	if err != nil {
+		t.Fatalf("Expected calls to enqueue, got %v", err)
+	}
+	if added != 3 {
+		t.Fatalf("Expected 3 tasks, got %d", added)
+	}
+
+	calls := dequeuedCalls(t, q)
+	for i, symbol := range []string{"AAPL", "MSFT"} {
+		call := calls[i]
+		if call.Symbol != symbol || call.URL != "https://api.example.com/quote/"+symbol {
+			t.Errorf("Expected call for %s, got %s (%s)", symbol, call.URL, call.Symbol)
+		}
+		if call.Headers["X-Symbol"] != symbol || call.Headers["Accept"] != "application/json" {
+			t.Errorf("Expected headers for %s, got %v", symbol, call.Headers)
+		}
+		if call.Body != `{"ticker":"`+symbol+`"}` {
+			t.Errorf("Expected body for %s, got %s", symbol, call.Body)
+		}
+	}
+	if calls[2].URL != "https://api.example.com/market" || calls[2].Symbol != "" {
+		t.Errorf("Expected the untemplated call as is, got %+v", calls[2])
+	}
+}
+
+func TestEnqueueAPICallsRejectsInvalidCalls(t *testing.T) {
+	q := newMemoryQueue()
+	s := NewService(&config.Config{}, q)
+
+	added, err := s.EnqueueAPICalls(context.Background(), []APICall{
+		{URL: "https://api.example.com/a"},
+		{Method: "TRACE", URL: "https://api.example.com/b"},
+		{URL: "https://api.example.com/c"},
+	})
+	if err == nil {
+		t.Fatalf("Expected an error for the TRACE call")
+	}
+	if added != 1 || len(q.queues[QueueName(constants.TaskTypeAPICall)]) != 1 {
+		t.Errorf("Expected only the call before the invalid one, got %d", added)
+	}
+}
+
+func TestAPICallValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		call  APICall
+		valid bool
+	}{
+		{"method defaults to GET", APICall{URL: "https://example.com"}, true},
+		{"supported method", APICall{Method: "PATCH", URL: "https://example.com"}, true},
+		{"missing URL", APICall{Method: "GET"}, false},
+		{"unsupported method", APICall{Method: "CONNECT", URL: "https://example.com"}, false},
+		{"methods are case sensitive", APICall{Method: "get", URL: "https://example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestAPICallExpects(t *testing.T) {
+	tests := []struct {
+		expected []int
+		status   int
+		want     bool
+	}{
+		{nil, 200, true},
+		{nil, 204, true},
+		{nil, 299, true},
+		{nil, 301, false},
+		{nil, 404, false},
+		{[]int{200, 404}, 404, true},
+		{[]int{200, 404}, 201, false},
+	}
+
+	for _, tt := range tests {
+		call := APICall{ExpectedStatus: tt.expected}
+		if got := call.Expects(tt.status); got != tt.want {
+			t.Errorf("Expected %v for status %d with %v, got %v", tt.want, tt.status, tt.expected, got)
+		}
+	}
+}
+
+func TestTaskNotBefore(t *testing.T) {
+	task := NewTask(constants.TaskTypeAPICall)
+	if !task.NotBefore().IsZero() {
+		t.Errorf("Expected a new task to be due, got %v", task.NotBefore())
+	}
+
+	at := time.Date(2026, 3, 2, 15, 0, 0, 500, time.UTC)
+	task.SetNotBefore(at)
+
+	// Survives the trip through the queue
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Expected task to marshal, got %v", err)
+	}
+	var decoded Task
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected task to unmarshal, got %v", err)
+	}
+	if !decoded.NotBefore().Equal(at) {
+		t.Errorf("Expected not before %v, got %v", at, decoded.NotBefore())
+	}
+}
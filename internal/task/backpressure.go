@@ -0,0 +1,206 @@
+package task
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+)
+
+const (
+	defaultCheckInterval = 2 * time.Second
+	defaultThrottleDelay = 5 * time.Second
+
+	// maxCheckFailures is how many consecutive failed length checks release
+	// backpressure, so producers are not paused forever while the queue
+	// cannot be read
+	maxCheckFailures = 3
+)
+
+// Backpressure tracks the length of a queue against its watermarks. It engages
+// once the queue reaches the high watermark and releases only after it drains
+// to the low watermark, so producers do not flap around a single threshold.
+type Backpressure struct {
+	queueSvc  QueueService
+	queueName string
+	cfg       config.QueueConfig
+	clock     clock.Clock
+
+	mu           sync.Mutex
+	failures     int // Consecutive failed length checks
+	engaged      bool
+	engagedAt    time.Time
+	lastCheck    time.Time
+	lastLength   int64
+	engagements  int64
+	totalEngaged time.Duration
+	waits        int64
+}
+
+// BackpressureStats is a snapshot of a queue's backpressure state
+type BackpressureStats struct {
+	Queue         string        `json:"queue"`
+	Mode          string        `json:"mode"`
+	HighWatermark int64         `json:"high_watermark"`
+	LowWatermark  int64         `json:"low_watermark"`
+	Length        int64         `json:"length"`
+	Engaged       bool          `json:"engaged"`
+	EngagedSince  time.Time     `json:"engaged_since,omitempty"`
+	Engagements   int64         `json:"engagements"`
+	TotalEngaged  time.Duration `json:"total_engaged"`
+	Waits         int64         `json:"waits"`
+}
+
+// NewBackpressure creates a backpressure gate for a queue
+func NewBackpressure(cfg config.QueueConfig, queue QueueService) *Backpressure {
+	if cfg.Mode == "" {
+		cfg.Mode = constants.BackpressureModePause
+	}
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = defaultCheckInterval
+	}
+	if cfg.Mode == constants.BackpressureModeThrottle && cfg.ThrottleDelay <= 0 {
+		cfg.ThrottleDelay = defaultThrottleDelay
+	}
+
+	return &Backpressure{
+		queueSvc:  queue,
+		queueName: QueueName(cfg.TaskType),
+		cfg:       cfg,
+		clock:     clock.New(),
+	}
+}
+
+// SetClock replaces the clock used to sample the queue and to wait, e.g.
+// with a fake clock in tests
+func (b *Backpressure) SetClock(c clock.Clock) {
+	b.clock = c
+}
+
+// Wait blocks the producer while backpressure is engaged. In pause mode it
+// returns once the queue has drained to the low watermark; in throttle mode it
+// only adds a delay. Returns the context error if cancelled while waiting.
+func (b *Backpressure) Wait(ctx context.Context) error {
+	if !b.check(ctx) {
+		return nil
+	}
+
+	b.mu.Lock()
+	b.waits++
+	b.mu.Unlock()
+
+	if b.cfg.Mode == constants.BackpressureModeThrottle {
+		return sleepContext(ctx, b.clock, b.cfg.ThrottleDelay)
+	}
+
+	for b.check(ctx) {
+		if err := sleepContext(ctx, b.clock, b.cfg.CheckInterval); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Snapshot returns the current backpressure state
+func (b *Backpressure) Snapshot() BackpressureStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := BackpressureStats{
+		Queue:         b.queueName,
+		Mode:          b.cfg.Mode,
+		HighWatermark: b.cfg.HighWatermark,
+		LowWatermark:  b.cfg.LowWatermark,
+		Length:        b.lastLength,
+		Engaged:       b.engaged,
+		Engagements:   b.engagements,
+		TotalEngaged:  b.totalEngaged,
+		Waits:         b.waits,
+	}
+	if b.engaged {
+		stats.EngagedSince = b.engagedAt
+		stats.TotalEngaged += b.clock.Since(b.engagedAt)
+	}
+	return stats
+}
+
+// check samples the queue length, at most once per check interval, and
+// reports whether backpressure is engaged. The queue is sampled without
+// holding the lock, so a slow Redis does not block other producers. After
+// maxCheckFailures failed samples in a row, backpressure is released until
+// the queue can be read again.
+func (b *Backpressure) check(ctx context.Context) bool {
+	b.mu.Lock()
+	if b.clock.Since(b.lastCheck) < b.cfg.CheckInterval {
+		engaged := b.engaged
+		b.mu.Unlock()
+		return engaged
+	}
+	// Claim this sample so concurrent callers keep the current state meanwhile
+	b.lastCheck = b.clock.Now()
+	b.mu.Unlock()
+
+	length, err := b.queueSvc.QueueLength(ctx, b.queueName)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.failures++
+		log.Printf("Error checking length of queue %s (%d in a row): %v", b.queueName, b.failures, err)
+
+		// Keep the previous state through brief errors, but fail open
+		if b.engaged && b.failures >= maxCheckFailures {
+			b.release()
+			log.Printf("Backpressure released on %s: queue length unavailable after %d checks",
+				b.queueName, b.failures)
+		}
+		return b.engaged
+	}
+	if b.failures >= maxCheckFailures {
+		log.Printf("Length of queue %s available again after %d failed checks", b.queueName, b.failures)
+	}
+	b.failures = 0
+	b.lastLength = length
+
+	switch {
+	case !b.engaged && length >= b.cfg.HighWatermark:
+		b.engaged = true
+		b.engagedAt = b.clock.Now()
+		b.engagements++
+		log.Printf("Backpressure engaged on %s: length %d reached high watermark %d (mode: %s)",
+			b.queueName, length, b.cfg.HighWatermark, b.cfg.Mode)
+	case b.engaged && length <= b.cfg.LowWatermark:
+		engagedFor := b.release()
+		log.Printf("Backpressure released on %s: length %d drained to low watermark %d after %s",
+			b.queueName, length, b.cfg.LowWatermark, engagedFor.Round(time.Second))
+	}
+
+	return b.engaged
+}
+
+// release disengages backpressure and returns how long it was engaged;
+// callers must hold b.mu
+func (b *Backpressure) release() time.Duration {
+	engagedFor := b.clock.Since(b.engagedAt)
+	b.engaged = false
+	b.totalEngaged += engagedFor
+	return engagedFor
+}
+
+// sleepContext sleeps for d on a clock or until the context is cancelled
+func sleepContext(ctx context.Context, c clock.Clock, d time.Duration) error {
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
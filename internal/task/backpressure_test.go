@@ -0,0 +1,158 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+)
+
+var testStart = time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+// lengthQueue reports a queue length set by the test, or an error when set
+type lengthQueue struct {
+	*memoryQueue
+	mu     sync.Mutex
+	length int64
+	err    error
+}
+
+func (q *lengthQueue) QueueLength(context.Context, string) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.length, q.err
+}
+
+func (q *lengthQueue) set(length int64, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.length, q.err = length, err
+}
+
+// newTestBackpressure creates a pause-mode gate with watermarks 100 and 20
+func newTestBackpressure() (*Backpressure, *lengthQueue, *clock.Fake) {
+	fake := clock.NewFake(testStart)
+	queue := &lengthQueue{memoryQueue: newMemoryQueue()}
+	b := NewBackpressure(config.QueueConfig{
+		TaskType:      constants.TaskTypeConsume,
+		HighWatermark: 100,
+		LowWatermark:  20,
+		CheckInterval: time.Second,
+	}, queue)
+	b.SetClock(fake)
+	return b, queue, fake
+}
+
+func TestBackpressureHysteresis(t *testing.T) {
+	b, queue, fake := newTestBackpressure()
+
+	tests := []struct {
+		length  int64
+		engaged bool
+	}{
+		{50, false},
+		{100, true}, // Engages at the high watermark
+		{60, true},  // Stays engaged between the watermarks
+		{21, true},
+		{20, false}, // Releases at the low watermark
+		{60, false}, // Stays released between the watermarks
+		{150, true},
+	}
+
+	for _, tt := range tests {
+		queue.set(tt.length, nil)
+		fake.Advance(time.Second)
+		if engaged := b.check(context.Background()); engaged != tt.engaged {
+			t.Errorf("Expected engaged %v at length %d, got %v", tt.engaged, tt.length, engaged)
+		}
+	}
+
+	stats := b.Snapshot()
+	if stats.Engagements != 2 || stats.TotalEngaged != 3*time.Second || stats.Length != 150 {
+		t.Errorf("Expected 2 engagements lasting 3s and length 150, got %d lasting %s and length %d",
+			stats.Engagements, stats.TotalEngaged, stats.Length)
+	}
+}
+
+func TestBackpressureSamplesOncePerInterval(t *testing.T) {
+	b, queue, fake := newTestBackpressure()
+
+	queue.set(100, nil)
+	fake.Advance(time.Second)
+	b.check(context.Background())
+
+	// The drained queue is not seen until the interval elapsed
+	queue.set(0, nil)
+	fake.Advance(time.Second - time.Nanosecond)
+	if !b.check(context.Background()) {
+		t.Errorf("Expected the previous sample to be used within the interval")
+	}
+	fake.Advance(time.Nanosecond)
+	if b.check(context.Background()) {
+		t.Errorf("Expected a new sample once the interval elapsed")
+	}
+}
+
+func TestBackpressureFailsOpenWhenLengthUnavailable(t *testing.T) {
+	b, queue, fake := newTestBackpressure()
+
+	queue.set(100, nil)
+	fake.Advance(time.Second)
+	b.check(context.Background())
+
+	// Brief errors keep the previous state
+	queue.set(0, errors.New("connection refused"))
+	for i := 1; i < maxCheckFailures; i++ {
+		fake.Advance(time.Second)
+		if !b.check(context.Background()) {
+			t.Fatalf("Expected backpressure to stay engaged after %d failed checks", i)
+		}
+	}
+
+	fake.Advance(time.Second)
+	if b.check(context.Background()) {
+		t.Fatalf("Expected backpressure to be released after %d failed checks", maxCheckFailures)
+	}
+	if stats := b.Snapshot(); stats.Engaged || stats.TotalEngaged != time.Duration(maxCheckFailures)*time.Second {
+		t.Errorf("Expected the engagement to be closed, got engaged %v for %s", stats.Engaged, stats.TotalEngaged)
+	}
+
+	// Once readable again the watermarks apply as before
+	queue.set(100, nil)
+	fake.Advance(time.Second)
+	if !b.check(context.Background()) {
+		t.Errorf("Expected backpressure to engage again once the length is readable")
+	}
+}
+
+func TestBackpressureWaitPausesUntilDrained(t *testing.T) {
+	b, queue, fake := newTestBackpressure()
+	queue.set(100, nil)
+	fake.Advance(time.Second)
+
+	done := make(chan error, 1)
+	go func() { done <- b.Wait(context.Background()) }()
+
+	fake.BlockUntil(1)
+	queue.set(20, nil)
+	fake.Advance(time.Second)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the wait to end cleanly, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for the producer to resume")
+	}
+	if waits := b.Snapshot().Waits; waits != 1 {
+		t.Errorf("Expected 1 wait, got %d", waits)
+	}
+}
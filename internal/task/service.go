@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 
 	"github.com/guillermoballester/propagatorGo/internal/constants"
 
@@ -14,6 +15,7 @@ import (
 // QueueService defines the minimum interface needed for task queue operations
 type QueueService interface {
 	Enqueue(ctx context.Context, queueName string, task interface{}) error
+	EnqueueIfEmpty(ctx context.Context, queueName string, tasks []interface{}) (bool, error)
 	Dequeue(ctx context.Context, queueName string, timeout int) ([]byte, error)
 	QueueLength(ctx context.Context, queueName string) (int64, error)
 	ClearQueue(ctx context.Context, queueName string) error
@@ -21,15 +23,22 @@ type QueueService interface {
 
 // Service manages task processing
 type Service struct {
-	config   *config.Config
-	queueSvc QueueService
+	config       *config.Config
+	queueSvc     QueueService
+	backpressure map[string]*Backpressure
 }
 
 // NewService creates a new task service
 func NewService(cfg *config.Config, queue QueueService) *Service {
+	backpressure := make(map[string]*Backpressure)
+	for _, q := range cfg.Queues {
+		backpressure[q.TaskType] = NewBackpressure(q, queue)
+	}
+
 	return &Service{
-		config:   cfg,
-		queueSvc: queue,
+		config:       cfg,
+		queueSvc:     queue,
+		backpressure: backpressure,
 	}
 }
 
@@ -44,6 +53,37 @@ func (s *Service) EnqueueTask(ctx context.Context, task *Task) error {
 	return s.queueSvc.Enqueue(ctx, queueName, task)
 }
 
+// QueueLength returns the number of pending tasks of a type
+func (s *Service) QueueLength(ctx context.Context, taskType string) (int64, error) {
+	return s.queueSvc.QueueLength(ctx, QueueName(taskType))
+}
+
+// WaitForCapacity blocks producers of a task type while its queue is above the
+// configured high watermark. Queues without watermarks never block.
+func (s *Service) WaitForCapacity(ctx context.Context, taskType string) error {
+	bp, ok := s.backpressure[taskType]
+	if !ok {
+		return nil
+	}
+	return bp.Wait(ctx)
+}
+
+// BackpressureStats returns the backpressure state of every configured queue,
+// sorted by task type
+func (s *Service) BackpressureStats() []BackpressureStats {
+	taskTypes := make([]string, 0, len(s.backpressure))
+	for taskType := range s.backpressure {
+		taskTypes = append(taskTypes, taskType)
+	}
+	sort.Strings(taskTypes)
+
+	stats := make([]BackpressureStats, 0, len(taskTypes))
+	for _, taskType := range taskTypes {
+		stats = append(stats, s.backpressure[taskType].Snapshot())
+	}
+	return stats
+}
+
 // EnqueueStocks adds all enabled stock symbols as tasks for a specific task type
 func (s *Service) EnqueueStocks(ctx context.Context, taskType string, source string) error {
 	queueName := QueueName(taskType)
@@ -82,6 +122,50 @@ func (s *Service) EnqueueStocks(ctx context.Context, taskType string, source str
 	return nil
 }
 
+// EnqueueScrapeCycle adds one scrape task per enabled stock and source, so that
+// scraper workers on any instance can pull them from the shared queue. When no
+// sources are given, every enabled site is used. If the previous cycle has not
+// been drained yet nothing is added, which keeps each symbol scraped once per
+// cycle. The check and the enqueue happen atomically, so concurrent dispatches
+// cannot both add a cycle.
+// Returns the number of tasks added.
+func (s *Service) EnqueueScrapeCycle(ctx context.Context, sources []string) (int, error) {
+	queueName := QueueName(constants.TaskTypeScrape)
+
+	if len(sources) == 0 {
+		for _, site := range s.config.Scraper.Sites {
+			if site.Enabled {
+				sources = append(sources, site.Name)
+			}
+		}
+	}
+
+	var tasks []interface{}
+	for _, stock := range s.config.StockList.Stocks {
+		if !stock.Enabled {
+			continue
+		}
+
+		for _, source := range sources {
+			tasks = append(tasks, s.CreateScrapeTask(stock.Symbol, source))
+		}
+	}
+
+	added, err := s.queueSvc.EnqueueIfEmpty(ctx, queueName, tasks)
+	if err != nil {
+		return 0, err
+	}
+	if !added {
+		if len(tasks) > 0 {
+			log.Printf("Queue %s still has items from the previous cycle, skipping dispatch", queueName)
+		}
+		return 0, nil
+	}
+
+	log.Printf("Added %d tasks to %s queue", len(tasks), queueName)
+	return len(tasks), nil
+}
+
 // GetNext retrieves the next task from the queue
 func (s *Service) GetNext(ctx context.Context, taskType string, timeout int) (*Task, error) {
 	queueName := QueueName(taskType)
@@ -102,6 +186,14 @@ func (s *Service) GetNext(ctx context.Context, taskType string, timeout int) (*T
 	return &task, nil
 }
 
+// CreateScrapeTask creates a new scrape task for a symbol and source
+func (s *Service) CreateScrapeTask(symbol, source string) *Task {
+	task := NewTask(constants.TaskTypeScrape)
+	task.SetParam("symbol", symbol)
+	task.SetParam("source", source)
+	return task
+}
+
 // CreateConsumeTask creates a new consume task with article data
 func (s *Service) CreateConsumeTask(symbol, source string, article interface{}) *Task {
 	task := NewTask(constants.TaskTypeConsume)
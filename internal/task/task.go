@@ -53,6 +53,25 @@ func (t *Task) GetParamString(key string) (string, error) {
 	return str, nil
 }
 
+// SetNotBefore delays the task, so workers dequeuing it earlier put it back
+func (t *Task) SetNotBefore(at time.Time) {
+	t.Params["not_before"] = at.Format(time.RFC3339Nano)
+}
+
+// NotBefore returns when the task may run, or the zero time when it may run
+// right away
+func (t *Task) NotBefore() time.Time {
+	value, ok := t.Params["not_before"].(string)
+	if !ok {
+		return time.Time{}
+	}
+	at, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
+
 // GetArticle extracts the article from a consume task
 func (t *Task) GetArticle() (*model.ArticleData, error) {
 	if t.Type != constants.TaskTypeConsume {
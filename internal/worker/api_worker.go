@@ -0,0 +1,378 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/task"
+)
+
+const (
+	// defaultAPICallTimeout is used for calls that set no timeout
+	defaultAPICallTimeout = 30 * time.Second
+
+	// maxAPIResponseBody bounds how much of a response body is kept
+	maxAPIResponseBody = 10 << 20
+
+	// apiRetryBackoff is the wait before the first retry of a failed call,
+	// doubling for each further one
+	apiRetryBackoff = time.Second
+
+	// maxAPIRetryDelay caps the wait before a retry, Retry-After included
+	maxAPIRetryDelay = 15 * time.Minute
+
+	// deferPollInterval bounds how long a worker waits after putting back a
+	// task that is not due yet
+	deferPollInterval = time.Second
+)
+
+// APIResponseHandler processes the response of an API call
+type APIResponseHandler func(ctx context.Context, resp *task.APIResponse) error
+
+// APIHandlers is a registry of named API response handlers
+type APIHandlers struct {
+	mu       sync.RWMutex
+	handlers map[string]APIResponseHandler
+}
+
+// NewAPIHandlers creates a registry holding the built-in "log" handler
+func NewAPIHandlers() *APIHandlers {
+	h := &APIHandlers{
+		handlers: make(map[string]APIResponseHandler),
+	}
+	h.Register("log", func(_ context.Context, resp *task.APIResponse) error {
+		log.Printf("API call %s %s returned %d (%d bytes) in %s",
+			resp.Call.Method, resp.Call.URL, resp.Status, len(resp.Body), resp.Duration.Round(time.Millisecond))
+		return nil
+	})
+	return h
+}
+
+// Register adds or replaces a named handler
+func (h *APIHandlers) Register(name string, handler APIResponseHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.handlers[name] = handler
+}
+
+// Get returns a named handler
+func (h *APIHandlers) Get(name string) (APIResponseHandler, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	handler, ok := h.handlers[name]
+	return handler, ok
+}
+
+// retryableError marks failures worth another attempt
+type retryableError struct {
+	err        error
+	retryAfter time.Duration // Wait asked for by the server, zero when none
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// APIWorker consumes api_call tasks, performs the HTTP requests they describe
+// and routes each response to a handler or a follow-up queue
+type APIWorker struct {
+	BaseWorker
+	taskService     *task.Service
+	client          *http.Client
+	handlers        *APIHandlers
+	defaultHandler  string
+	defaultFollowUp string
+	runMode         string
+}
+
+// NewAPIWorker creates a new API worker. Responses of calls that name no
+// handler or follow-up go to defaultHandler or defaultFollowUp. In pass run
+// mode the worker stops once the api_call queue is drained.
+func NewAPIWorker(bw BaseWorker, taskSvc *task.Service, handlers *APIHandlers, defaultHandler, defaultFollowUp, runMode string) *APIWorker {
+	return &APIWorker{
+		BaseWorker:      bw,
+		taskService:     taskSvc,
+		client:          &http.Client{},
+		handlers:        handlers,
+		defaultHandler:  defaultHandler,
+		defaultFollowUp: defaultFollowUp,
+		runMode:         runMode,
+	}
+}
+
+// Start begins consuming api_call tasks
+func (w *APIWorker) Start(ctx context.Context) error {
+	if !w.SetActive(true) {
+		return fmt.Errorf("worker %s is already running", w.Name())
+	}
+	w.Stats.RecordStart()
+	defer w.Stats.RecordStop()
+
+	for w.IsActive() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			nextTask, err := w.taskService.GetNext(ctx, constants.TaskTypeAPICall, 5)
+			if err != nil {
+				log.Printf("Error getting api_call task: %v", err)
+				w.Stats.RecordError()
+				w.clock.Sleep(1 * time.Second)
+				continue
+			}
+
+			if nextTask == nil {
+				if w.runMode == constants.RunModePass && queueDrained(ctx, w.taskService, constants.TaskTypeAPICall) {
+					log.Printf("Worker %s drained the queue", w.Name())
+					w.SetActive(false)
+					return nil
+				}
+				continue
+			}
+
+			// Calls waiting to be retried go back to the end of the queue
+			if notBefore := nextTask.NotBefore(); w.clock.Now().Before(notBefore) {
+				w.deferTask(nextTask, notBefore)
+				continue
+			}
+
+			call, err := nextTask.GetAPICall()
+			if err != nil {
+				log.Printf("Error extracting api call: %v", err)
+				w.Stats.RecordError()
+				continue
+			}
+
+			labels := ItemLabels{Symbol: call.Symbol, Source: call.Source}
+			itemStart := w.clock.Now()
+			err = w.process(ctx, call)
+
+			// A call interrupted by shutdown is left for another worker
+			if err != nil && ctx.Err() != nil {
+				requeue(w.taskService, nextTask, w.Stats)
+				return ctx.Err()
+			}
+
+			if err != nil {
+				var retryErr *retryableError
+				if errors.As(err, &retryErr) && nextTask.Attempt() < call.MaxAttempts {
+					delay := retryDelay(nextTask.Attempt(), retryErr.retryAfter)
+					log.Printf("API call %s %s failed on attempt %d, retrying in %s: %v",
+						call.Method, call.URL, nextTask.Attempt(), delay, err)
+					nextTask.SetParam("attempt", nextTask.Attempt()+1)
+					nextTask.SetNotBefore(w.clock.Now().Add(delay))
+					w.retry(nextTask)
+				} else {
+					log.Printf("API call %s %s failed: %v", call.Method, call.URL, err)
+				}
+				w.Stats.RecordItemFailed(labels, w.clock.Since(itemStart))
+				continue
+			}
+
+			w.Stats.RecordItemProcessed(labels, w.clock.Since(itemStart))
+		}
+	}
+	return nil
+}
+
+// retry puts a failed task back on its queue for another attempt
+func (w *APIWorker) retry(t *task.Task) {
+	ctx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+	defer cancel()
+
+	if err := w.taskService.EnqueueTask(ctx, t); err != nil {
+		log.Printf("Error requeueing %s task %s for a retry: %v", t.Type, t.ID, err)
+		return
+	}
+	w.Stats.RecordRetry()
+}
+
+// deferTask puts back a task that is not due yet, then waits a little so a
+// queue holding only such tasks is not spun through
+func (w *APIWorker) deferTask(t *task.Task, notBefore time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+	defer cancel()
+
+	if err := w.taskService.EnqueueTask(ctx, t); err != nil {
+		log.Printf("Error putting back %s task %s: %v", t.Type, t.ID, err)
+		return
+	}
+
+	wait := notBefore.Sub(w.clock.Now())
+	if wait > deferPollInterval {
+		wait = deferPollInterval
+	}
+	w.clock.Sleep(wait)
+}
+
+// retryDelay returns how long to wait before retrying a call that failed on
+// an attempt, backing off exponentially unless the server asked for longer
+func retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := apiRetryBackoff
+	for i := 1; i < attempt && delay < maxAPIRetryDelay; i++ {
+		delay *= 2
+	}
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	if delay > maxAPIRetryDelay {
+		delay = maxAPIRetryDelay
+	}
+	return delay
+}
+
+// parseRetryAfter reads a Retry-After header, given in seconds or as an HTTP
+// date, returning zero when it is missing or invalid
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// process performs an API call and routes its response
+func (w *APIWorker) process(ctx context.Context, call *task.APICall) error {
+	if err := call.Validate(); err != nil {
+		return err
+	}
+
+	handlerName := call.Handler
+	followUp := call.FollowUp
+	if handlerName == "" && followUp == "" {
+		handlerName, followUp = w.defaultHandler, w.defaultFollowUp
+	}
+	if handlerName == "" && followUp == "" {
+		return fmt.Errorf("api call %s %s has no handler or follow-up", call.Method, call.URL)
+	}
+
+	var handler APIResponseHandler
+	if handlerName != "" {
+		var ok bool
+		if handler, ok = w.handlers.Get(handlerName); !ok {
+			return fmt.Errorf("unknown api response handler: %s", handlerName)
+		}
+	}
+
+	// Hold off while the follow-up queue is behind
+	if followUp != "" {
+		if err := w.taskService.WaitForCapacity(ctx, followUp); err != nil {
+			return err
+		}
+	}
+
+	resp, err := w.do(ctx, call)
+	if err != nil {
+		return err
+	}
+
+	if handler != nil {
+		if err := handler(ctx, resp); err != nil {
+			return fmt.Errorf("handler %s failed: %w", handlerName, err)
+		}
+	}
+
+	if followUp != "" {
+		followUpTask := task.NewTask(followUp)
+		followUpTask.SetParam("response", resp)
+		if call.Symbol != "" {
+			followUpTask.SetParam("symbol", call.Symbol)
+		}
+		if call.Source != "" {
+			followUpTask.SetParam("source", call.Source)
+		}
+		if err := w.taskService.EnqueueTask(ctx, followUpTask); err != nil {
+			return fmt.Errorf("error enqueueing %s follow-up: %w", followUp, err)
+		}
+	}
+
+	return nil
+}
+
+// do performs the HTTP request of a call and checks its status
+func (w *APIWorker) do(ctx context.Context, call *task.APICall) (*task.APIResponse, error) {
+	timeout := call.Timeout
+	if timeout <= 0 {
+		timeout = defaultAPICallTimeout
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := call.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if call.Body != "" {
+		body = strings.NewReader(call.Body)
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, method, call.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("error building request: %w", err)
+	}
+	for key, value := range call.Headers {
+		req.Header.Set(key, value)
+	}
+
+	start := w.clock.Now()
+	res, err := w.client.Do(req)
+	if err != nil {
+		return nil, &retryableError{err: fmt.Errorf("error calling %s: %w", call.URL, err)}
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxAPIResponseBody))
+	if err != nil {
+		return nil, &retryableError{err: fmt.Errorf("error reading response from %s: %w", call.URL, err)}
+	}
+
+	if !call.Expects(res.StatusCode) {
+		statusErr := fmt.Errorf("unexpected status %d from %s", res.StatusCode, call.URL)
+		if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+			return nil, &retryableError{
+				err:        statusErr,
+				retryAfter: parseRetryAfter(res.Header.Get("Retry-After"), w.clock.Now()),
+			}
+		}
+		return nil, statusErr
+	}
+
+	headers := make(map[string]string, len(res.Header))
+	for key := range res.Header {
+		headers[key] = res.Header.Get(key)
+	}
+
+	return &task.APIResponse{
+		Call:       *call,
+		Status:     res.StatusCode,
+		Headers:    headers,
+		Body:       string(data),
+		Duration:   w.clock.Since(start),
+		ReceivedAt: w.clock.Now(),
+	}, nil
+}
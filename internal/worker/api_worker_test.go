@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/task"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter time.Duration
+		expected   time.Duration
+	}{
+		{"first retry", 1, 0, apiRetryBackoff},
+		{"doubles per attempt", 3, 0, 4 * apiRetryBackoff},
+		{"capped", 40, 0, maxAPIRetryDelay},
+		{"retry-after longer than the backoff", 1, 30 * time.Second, 30 * time.Second},
+		{"retry-after shorter than the backoff", 4, time.Second, 8 * apiRetryBackoff},
+		{"retry-after capped", 1, time.Hour, maxAPIRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDelay(tt.attempt, tt.retryAfter); got != tt.expected {
+				t.Errorf("Expected delay %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-5", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.expected {
+			t.Errorf("Expected %s for %q, got %s", tt.expected, tt.value, got)
+		}
+	}
+}
+
+func TestAPIWorkerDoRetryableStatus(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Retry-After", "7")
+		rw.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC))
+	w := NewAPIWorker(NewBaseWorker(1, "api-1", "api", fake), nil, NewAPIHandlers(), "", "", "")
+	call := &task.APICall{URL: server.URL}
+
+	_, err := w.do(context.Background(), call)
+	var retryErr *retryableError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("Expected a retryable error for status 503, got %v", err)
+	}
+	if retryErr.retryAfter != 7*time.Second {
+		t.Errorf("Expected retry after 7s, got %s", retryErr.retryAfter)
+	}
+
+	status = http.StatusNotFound
+	if _, err := w.do(context.Background(), call); err == nil || errors.As(err, &retryErr) {
+		t.Errorf("Expected a final error for status 404, got %v", err)
+	}
+}
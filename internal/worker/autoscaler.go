@@ -0,0 +1,213 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+const (
+	defaultScaleInterval     = 15 * time.Second
+	defaultScaleUpCooldown   = 30 * time.Second
+	defaultScaleDownCooldown = 2 * time.Minute
+	defaultQueuePerWorker    = 100
+)
+
+// QueueDepthFunc reports how many items are waiting for a pool
+type QueueDepthFunc func(ctx context.Context) (int64, error)
+
+// Autoscaler resizes a running pool between its minimum and maximum size based
+// on queue depth and processing latency. Scale-ups happen in one step, while
+// scale-downs remove one worker at a time after a longer cooldown.
+type Autoscaler struct {
+	cfg   config.AutoscaleConfig
+	depth QueueDepthFunc
+	pool  *Pool
+	clock clock.Clock
+
+	mu            sync.Mutex
+	stats         ScalingStats
+	lastProcessed int64
+	lastTime      int64
+}
+
+// ScalingStats describes the autoscaler's most recent decisions
+type ScalingStats struct {
+	MinWorkers     int           `json:"min_workers"`
+	MaxWorkers     int           `json:"max_workers"`
+	CurrentWorkers int           `json:"current_workers"`
+	DesiredWorkers int           `json:"desired_workers"`
+	QueueDepth     int64         `json:"queue_depth"`
+	AvgLatency     time.Duration `json:"avg_latency"`
+	ScaleUps       int64         `json:"scale_ups"`
+	ScaleDowns     int64         `json:"scale_downs"`
+	LastScaleAt    time.Time     `json:"last_scale_at,omitempty"`
+	LastDecision   string        `json:"last_decision"`
+}
+
+// NewAutoscaler creates an autoscaler from its configuration
+func NewAutoscaler(cfg config.AutoscaleConfig, depth QueueDepthFunc) *Autoscaler {
+	if cfg.MinWorkers < 1 {
+		cfg.MinWorkers = 1
+	}
+	if cfg.MaxWorkers < cfg.MinWorkers {
+		cfg.MaxWorkers = cfg.MinWorkers
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultScaleInterval
+	}
+	if cfg.ScaleUpCooldown <= 0 {
+		cfg.ScaleUpCooldown = defaultScaleUpCooldown
+	}
+	if cfg.ScaleDownCooldown <= 0 {
+		cfg.ScaleDownCooldown = defaultScaleDownCooldown
+	}
+	if cfg.TargetQueuePerWorker <= 0 {
+		cfg.TargetQueuePerWorker = defaultQueuePerWorker
+	}
+
+	return &Autoscaler{
+		cfg:   cfg,
+		depth: depth,
+		clock: clock.New(),
+		stats: ScalingStats{
+			MinWorkers: cfg.MinWorkers,
+			MaxWorkers: cfg.MaxWorkers,
+		},
+	}
+}
+
+// Run evaluates the pool size periodically until the context is cancelled or
+// the pool is stopped
+func (a *Autoscaler) Run(ctx context.Context, stop <-chan struct{}) {
+	ticker := a.clock.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C():
+			a.evaluate(ctx)
+		}
+	}
+}
+
+// Stats returns the latest scaling state
+func (a *Autoscaler) Stats() ScalingStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.stats
+}
+
+// evaluate decides on a new pool size and applies it
+func (a *Autoscaler) evaluate(ctx context.Context) {
+	depth, err := a.depth(ctx)
+	if err != nil {
+		log.Printf("Autoscaler for pool %s could not read queue depth: %v", a.pool.Name(), err)
+		return
+	}
+
+	latency := a.intervalLatency()
+	current := a.pool.Size()
+	desired, reason := a.desiredSize(current, depth, latency)
+
+	a.mu.Lock()
+	a.stats.QueueDepth = depth
+	a.stats.AvgLatency = latency
+	a.stats.CurrentWorkers = current
+	a.stats.DesiredWorkers = desired
+	sinceLastScale := a.clock.Since(a.stats.LastScaleAt)
+	a.mu.Unlock()
+
+	switch {
+	case desired > current && sinceLastScale < a.cfg.ScaleUpCooldown:
+		a.recordDecision(fmt.Sprintf("scale-up to %d deferred: cooldown", desired))
+		return
+	case desired < current && sinceLastScale < a.cfg.ScaleDownCooldown:
+		a.recordDecision(fmt.Sprintf("scale-down to %d deferred: cooldown", desired))
+		return
+	case desired == current:
+		a.recordDecision("steady: " + reason)
+		return
+	}
+
+	// Shrink gradually so in-flight work has time to finish
+	if desired < current {
+		desired = current - 1
+	}
+
+	size, err := a.pool.ScaleTo(desired)
+	if err != nil {
+		log.Printf("Autoscaler for pool %s failed to scale to %d: %v", a.pool.Name(), desired, err)
+		return
+	}
+
+	a.mu.Lock()
+	if size > current {
+		a.stats.ScaleUps++
+	} else {
+		a.stats.ScaleDowns++
+	}
+	a.stats.CurrentWorkers = size
+	a.stats.LastScaleAt = a.clock.Now()
+	a.stats.LastDecision = fmt.Sprintf("scaled %d -> %d: %s", current, size, reason)
+	a.mu.Unlock()
+
+	log.Printf("Autoscaler for pool %s scaled %d -> %d workers (%s)", a.pool.Name(), current, size, reason)
+}
+
+// desiredSize computes the target pool size and explains why
+func (a *Autoscaler) desiredSize(current int, depth int64, latency time.Duration) (int, string) {
+	desired := int((depth + a.cfg.TargetQueuePerWorker - 1) / a.cfg.TargetQueuePerWorker)
+	reason := fmt.Sprintf("queue depth %d", depth)
+
+	// Slow items with work still pending call for more workers than depth alone suggests
+	if a.cfg.MaxLatency > 0 && latency > a.cfg.MaxLatency && depth > 0 && desired <= current {
+		desired = current + 1
+		reason = fmt.Sprintf("latency %s above %s", latency.Round(time.Millisecond), a.cfg.MaxLatency)
+	}
+
+	if desired < a.cfg.MinWorkers {
+		desired = a.cfg.MinWorkers
+	}
+	if desired > a.cfg.MaxWorkers {
+		desired = a.cfg.MaxWorkers
+	}
+
+	return desired, reason
+}
+
+// intervalLatency returns the average processing time per item since the last evaluation
+func (a *Autoscaler) intervalLatency() time.Duration {
+	stats := a.pool.Stats()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	processed := stats.ItemsProcessed - a.lastProcessed
+	elapsed := stats.ProcessingTime - a.lastTime
+	a.lastProcessed = stats.ItemsProcessed
+	a.lastTime = stats.ProcessingTime
+
+	if processed <= 0 {
+		return 0
+	}
+	return time.Duration(elapsed / processed)
+}
+
+// recordDecision stores a decision that did not change the pool size
+func (a *Autoscaler) recordDecision(decision string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stats.LastDecision = decision
+}
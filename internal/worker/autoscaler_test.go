@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+// startScaledPool starts a pool of one idle test worker, autoscaled on the
+// reported queue depth. started counts the workers that began running.
+func startScaledPool(t *testing.T, cfg config.AutoscaleConfig, depth *int64, started *int32) (*Pool, *Autoscaler, *clock.Fake) {
+	t.Helper()
+
+	fake := clock.NewFake(testStart)
+	run := func(ctx context.Context, w *testWorker) error {
+		atomic.AddInt32(started, 1)
+		return untilStopped(ctx, w)
+	}
+	p := NewPool("test", 1)
+	if err := p.AddWorker(newTestWorker(0, fake, run)); err != nil {
+		t.Fatalf("Expected the worker to be added, got %v", err)
+	}
+
+	a := NewAutoscaler(cfg, func(context.Context) (int64, error) { return atomic.LoadInt64(depth), nil })
+	p.EnableAutoscaling(a, func(id int) (Worker, error) { return newTestWorker(id, fake, run), nil })
+	p.SetClock(fake)
+
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Expected the pool to start, got %v", err)
+	}
+	t.Cleanup(p.Stop)
+	return p, a, fake
+}
+
+func TestAutoscalerDesiredSize(t *testing.T) {
+	a := NewAutoscaler(config.AutoscaleConfig{
+		MinWorkers:           1,
+		MaxWorkers:           5,
+		TargetQueuePerWorker: 10,
+		MaxLatency:           100 * time.Millisecond,
+	}, nil)
+
+	tests := []struct {
+		current int
+		depth   int64
+		latency time.Duration
+		desired int
+	}{
+		{1, 0, 0, 1},
+		{1, 25, 0, 3},
+		{1, 1000, 0, 5},
+		{3, 5, 0, 1},
+		{2, 5, 200 * time.Millisecond, 3},
+		{2, 0, 200 * time.Millisecond, 1},
+		{5, 50, 200 * time.Millisecond, 5},
+	}
+
+	for _, tt := range tests {
+		if desired, _ := a.desiredSize(tt.current, tt.depth, tt.latency); desired != tt.desired {
+			t.Errorf("Expected %d workers for %d workers, depth %d and latency %s, got %d",
+				tt.desired, tt.current, tt.depth, tt.latency, desired)
+		}
+	}
+}
+
+func TestAutoscalerScalesUpAtOnceAndDownGradually(t *testing.T) {
+	depth := int64(35)
+	var started int32
+	p, a, fake := startScaledPool(t, config.AutoscaleConfig{
+		MinWorkers:           1,
+		MaxWorkers:           5,
+		TargetQueuePerWorker: 10,
+		ScaleDownCooldown:    2 * time.Minute,
+	}, &depth, &started)
+	ctx := context.Background()
+
+	a.evaluate(ctx)
+	if size := p.Size(); size != 4 {
+		t.Fatalf("Expected the pool to grow to 4 workers at once, got %d", size)
+	}
+	waitFor(t, "the new workers to start", func() bool { return atomic.LoadInt32(&started) == 4 })
+
+	// The queue drained, but the pool only just grew
+	atomic.StoreInt64(&depth, 0)
+	a.evaluate(ctx)
+	if size := p.Size(); size != 4 || !strings.Contains(a.Stats().LastDecision, "deferred") {
+		t.Errorf("Expected the scale-down to be deferred, got %d workers and %q", size, a.Stats().LastDecision)
+	}
+
+	fake.Advance(2 * time.Minute)
+	a.evaluate(ctx)
+	if size := p.Size(); size != 3 {
+		t.Errorf("Expected one worker to be removed, got %d workers", size)
+	}
+
+	// Removing the next worker waits for another cooldown
+	a.evaluate(ctx)
+	if size := p.Size(); size != 3 {
+		t.Errorf("Expected the next scale-down to wait, got %d workers", size)
+	}
+
+	atomic.StoreInt64(&depth, 30)
+	a.evaluate(ctx)
+	if decision := a.Stats().LastDecision; decision != "steady: queue depth 30" {
+		t.Errorf("Expected the pool to hold steady, got %q", decision)
+	}
+
+	stats := a.Stats()
+	if stats.ScaleUps != 1 || stats.ScaleDowns != 1 {
+		t.Errorf("Expected 1 scale-up and 1 scale-down, got %d and %d", stats.ScaleUps, stats.ScaleDowns)
+	}
+	waitFor(t, "the removed worker to exit", func() bool { return len(p.Stats().WorkerStats) == 3 })
+}
+
+func TestAutoscalerEvaluatesEveryInterval(t *testing.T) {
+	depth := int64(0)
+	var started int32
+	p, _, fake := startScaledPool(t, config.AutoscaleConfig{
+		MinWorkers:           1,
+		MaxWorkers:           5,
+		TargetQueuePerWorker: 10,
+		Interval:             15 * time.Second,
+	}, &depth, &started)
+
+	fake.BlockUntil(1)
+	atomic.StoreInt64(&depth, 25)
+
+	fake.Advance(15*time.Second - time.Nanosecond)
+	time.Sleep(5 * time.Millisecond)
+	if size := p.Size(); size != 1 {
+		t.Fatalf("Expected no scaling before the interval elapsed, got %d workers", size)
+	}
+
+	fake.Advance(time.Nanosecond)
+	waitFor(t, "the pool to grow", func() bool { return p.Size() == 3 && atomic.LoadInt32(&started) == 3 })
+}
@@ -2,29 +2,78 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/guillermoballester/propagatorGo/internal/constants"
 	"github.com/guillermoballester/propagatorGo/internal/database"
+	"github.com/guillermoballester/propagatorGo/internal/pipeline"
 	"github.com/guillermoballester/propagatorGo/internal/repository"
 	"github.com/guillermoballester/propagatorGo/internal/task"
 )
 
+const (
+	// defaultBatchWindow is used when batching is enabled without a window
+	defaultBatchWindow = 5 * time.Second
+
+	// flushTimeout bounds the final flush performed when the worker stops
+	flushTimeout = 30 * time.Second
+
+	// requeueTimeout bounds putting an interrupted task back on its queue
+	requeueTimeout = 5 * time.Second
+)
+
+// ArticleStore saves the articles taken from the consume queue
+type ArticleStore interface {
+	SaveArticle(ctx context.Context, article database.Article) error
+	SaveArticles(ctx context.Context, articles []database.Article) ([]repository.SaveResult, error)
+}
+
 // ConsumerWorker consumes messages from Redis and stores in the database
 type ConsumerWorker struct {
 	BaseWorker
 	taskService *task.Service
-	repository  *repository.ArticleRepository
+	repository  ArticleStore
+	pipeline    *pipeline.Pipeline
+	batchSize   int
+	batchWindow time.Duration
+	runMode     string
 }
 
-// NewConsumerWorker creates a new consumer worker
-func NewConsumerWorker(bw BaseWorker, taskSvc *task.Service, repo *repository.ArticleRepository) *ConsumerWorker {
+// consumeItem is an article taken from the consume queue
+type consumeItem struct {
+	task    *task.Task
+	article database.Article
+	labels  ItemLabels
+}
+
+// articleBatch accumulates articles waiting to be saved together
+type articleBatch struct {
+	articles []database.Article
+	labels   []ItemLabels // Labels of each article, for the stats
+	openedAt time.Time
+}
+
+// NewConsumerWorker creates a new consumer worker. A batchSize greater than one
+// enables batch mode, where articles are saved in bulk once the batch is full
+// or batchWindow has elapsed since its first article. In pass run mode the
+// worker stops once the consume queue is drained. A nil pipeline saves
+// articles as they were dequeued.
+func NewConsumerWorker(bw BaseWorker, taskSvc *task.Service, repo ArticleStore, p *pipeline.Pipeline, batchSize int, batchWindow time.Duration, runMode string) *ConsumerWorker {
+	if batchSize > 1 && batchWindow <= 0 {
+		batchWindow = defaultBatchWindow
+	}
+
 	return &ConsumerWorker{
 		BaseWorker:  bw,
 		taskService: taskSvc,
 		repository:  repo,
+		pipeline:    p,
+		batchSize:   batchSize,
+		batchWindow: batchWindow,
+		runMode:     runMode,
 	}
 }
 
@@ -33,74 +82,50 @@ func (w *ConsumerWorker) Start(ctx context.Context) error {
 	if !w.SetActive(true) {
 		return fmt.Errorf("worker %s is already running", w.Name())
 	}
+	w.Stats.RecordStart()
+	defer w.Stats.RecordStop()
+
+	if w.batchSize > 1 {
+		return w.consumeBatches(ctx)
+	}
 
 	for w.IsActive() {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			w.Stats.RecordStart()
-
-			nextTask, err := w.taskService.GetNext(ctx, constants.TaskTypeConsume, 5)
-			if err != nil {
-				log.Printf("Error getting task: %v", err)
-				w.Stats.RecordItemFailed()
-				time.Sleep(1 * time.Second)
-				continue
-			}
-
-			// If no task returned within timeout, try again
-			if nextTask == nil {
-				continue
-			}
-
-			symbol, err := nextTask.GetParamString("symbol")
-			if err != nil {
-				log.Printf("Error getting symbol from task: %v", err)
-				w.Stats.RecordItemFailed()
+			item, ok := w.nextArticle(ctx, 5)
+			if !ok {
+				if w.drained(ctx) {
+					log.Printf("Worker %s drained the queue", w.Name())
+					w.SetActive(false)
+					return nil
+				}
 				continue
 			}
 
-			source, err := nextTask.GetParamString("source")
-			if err != nil {
-				log.Printf("Error getting source from task: %v", err)
-				w.Stats.RecordItemFailed()
+			itemStart := w.clock.Now()
+			if !w.enrich(ctx, &item) {
 				continue
 			}
 
-			log.Printf("Worker %s processing article from source %s for symbol %s",
-				w.Name(), source, symbol)
-
-			// Extract article from task
-			article, err := nextTask.GetArticle()
-			if err != nil {
-				log.Printf("Error extracting article: %v", err)
-				w.Stats.RecordItemFailed()
-				continue
-			}
-
-			// Convert to database model
-			dbArticle := database.Article{
-				Title:     article.Title,
-				URL:       article.URL,
-				Text:      article.Text,
-				SiteName:  article.SiteName,
-				ScrapedAt: article.ScrapedAt,
-				Symbol:    symbol,
-			}
-
-			err = w.repository.SaveArticle(ctx, dbArticle)
+			err := w.repository.SaveArticle(ctx, item.article)
 			if err != nil {
+				// An article interrupted by shutdown is left for another worker
+				if ctx.Err() != nil {
+					requeue(w.taskService, item.task, w.Stats)
+					return ctx.Err()
+				}
 				log.Printf("Error saving article to database: %v", err)
-				w.Stats.RecordItemFailed()
+				w.Stats.RecordItemFailed(item.labels, w.clock.Since(itemStart))
 				continue
 			}
 
-			w.Stats.RecordItemProcessed()
+			w.Stats.RecordItemProcessed(item.labels, w.clock.Since(itemStart))
 			stats := w.Stats.GetSnapshot()
 			log.Printf("[%s] Task completed for %s. Articles: %d, Total processed: %d, Successful: %d, Failed: %d",
 				w.Name(),
-				symbol,
+				item.article.Symbol,
 				1,
 				stats.ItemsProcessed,
 				stats.ItemsSuccessful,
@@ -109,3 +134,212 @@ func (w *ConsumerWorker) Start(ctx context.Context) error {
 	}
 	return nil
 }
+
+// consumeBatches accumulates articles and saves them in bulk by size or time window
+func (w *ConsumerWorker) consumeBatches(ctx context.Context) error {
+	batch := &articleBatch{}
+
+	// Poll in short intervals so the time window is honoured while the queue is idle
+	timeout := 5
+	if w.batchWindow < 5*time.Second {
+		timeout = 1
+	}
+
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		defer cancel()
+		w.flush(flushCtx, batch)
+	}()
+
+	for w.IsActive() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			item, ok := w.nextArticle(ctx, timeout)
+			if ok && w.enrich(ctx, &item) {
+				if len(batch.articles) == 0 {
+					batch.openedAt = w.clock.Now()
+				}
+				batch.articles = append(batch.articles, item.article)
+				batch.labels = append(batch.labels, item.labels)
+			} else if !ok && w.drained(ctx) {
+				// The deferred flush saves whatever is pending
+				log.Printf("Worker %s drained the queue", w.Name())
+				w.SetActive(false)
+				return nil
+			}
+
+			full := len(batch.articles) >= w.batchSize
+			expired := len(batch.articles) > 0 && w.clock.Since(batch.openedAt) >= w.batchWindow
+			if full || expired {
+				w.flush(ctx, batch)
+			}
+		}
+	}
+	return nil
+}
+
+// flush saves the pending batch and records the outcome of every article
+func (w *ConsumerWorker) flush(ctx context.Context, batch *articleBatch) {
+	if len(batch.articles) == 0 {
+		return
+	}
+
+	articles, labels := batch.articles, batch.labels
+	batch.articles, batch.labels = nil, nil
+
+	flushStart := w.clock.Now()
+	results, err := w.repository.SaveArticles(ctx, articles)
+
+	// Every article of the batch is charged an equal share of the write
+	perItem := w.clock.Since(flushStart) / time.Duration(len(articles))
+
+	if err != nil {
+		// Keep the batch for the final flush when interrupted by shutdown
+		if ctx.Err() != nil {
+			batch.articles = append(articles, batch.articles...)
+			batch.labels = append(labels, batch.labels...)
+			return
+		}
+		// A single bad article fails the whole statement, so save them one by one
+		log.Printf("Error saving batch of %d articles to database, saving them one by one: %v", len(articles), err)
+		w.saveEach(ctx, batch, articles, labels)
+		return
+	}
+
+	outcomes := make(map[repository.SaveOutcome]int)
+	for _, result := range results {
+		outcomes[result.Outcome]++
+	}
+
+	// Duplicated URLs are collapsed by the repository but still count as processed
+	for _, l := range labels {
+		w.Stats.RecordItemProcessed(l, perItem)
+	}
+
+	stats := w.Stats.GetSnapshot()
+	log.Printf("[%s] Batch saved. Articles: %d, Inserted: %d, Updated: %d, Unchanged: %d, Total processed: %d, Successful: %d, Failed: %d",
+		w.Name(),
+		len(articles),
+		outcomes[repository.OutcomeInserted],
+		outcomes[repository.OutcomeUpdated],
+		outcomes[repository.OutcomeUnchanged],
+		stats.ItemsProcessed,
+		stats.ItemsSuccessful,
+		stats.ItemsFailed)
+}
+
+// saveEach saves the articles of a failed batch one at a time, so a bad
+// article only fails itself. Articles left when ctx is cancelled are kept in
+// the batch for the final flush.
+func (w *ConsumerWorker) saveEach(ctx context.Context, batch *articleBatch, articles []database.Article, labels []ItemLabels) {
+	saved, failed := 0, 0
+	for i, article := range articles {
+		start := w.clock.Now()
+		if err := w.repository.SaveArticle(ctx, article); err != nil {
+			if ctx.Err() != nil {
+				batch.articles = append(articles[i:], batch.articles...)
+				batch.labels = append(labels[i:], batch.labels...)
+				return
+			}
+			log.Printf("Error saving article %s to database: %v", article.URL, err)
+			w.Stats.RecordItemFailed(labels[i], w.clock.Since(start))
+			failed++
+			continue
+		}
+		w.Stats.RecordItemProcessed(labels[i], w.clock.Since(start))
+		saved++
+	}
+
+	log.Printf("[%s] Batch saved one by one. Articles: %d, Saved: %d, Failed: %d",
+		w.Name(), len(articles), saved, failed)
+}
+
+// enrich runs an article through the pool's pipeline. It returns false when
+// the article must not be saved, after recording the outcome: dropped
+// articles count as processed, failed ones as failed, and articles
+// interrupted by shutdown are requeued.
+func (w *ConsumerWorker) enrich(ctx context.Context, item *consumeItem) bool {
+	if w.pipeline == nil {
+		return true
+	}
+
+	start := w.clock.Now()
+	err := w.pipeline.Process(ctx, &item.article)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, pipeline.ErrDrop):
+		log.Printf("[%s] Article %s dropped: %v", w.Name(), item.article.URL, err)
+		w.Stats.RecordItemProcessed(item.labels, w.clock.Since(start))
+	case ctx.Err() != nil:
+		requeue(w.taskService, item.task, w.Stats)
+	default:
+		log.Printf("Error processing article %s: %v", item.article.URL, err)
+		w.Stats.RecordItemFailed(item.labels, w.clock.Since(start))
+	}
+	return false
+}
+
+// drained reports whether a pass-mode consumer has emptied the queue
+func (w *ConsumerWorker) drained(ctx context.Context) bool {
+	return w.runMode == constants.RunModePass && queueDrained(ctx, w.taskService, constants.TaskTypeConsume)
+}
+
+// nextArticle dequeues the next consume task and converts it to a database article.
+// It returns false when no task was available or the task could not be decoded.
+func (w *ConsumerWorker) nextArticle(ctx context.Context, timeout int) (consumeItem, bool) {
+	nextTask, err := w.taskService.GetNext(ctx, constants.TaskTypeConsume, timeout)
+	if err != nil {
+		log.Printf("Error getting task: %v", err)
+		w.Stats.RecordError()
+		w.clock.Sleep(1 * time.Second)
+		return consumeItem{}, false
+	}
+
+	// If no task returned within timeout, try again
+	if nextTask == nil {
+		return consumeItem{}, false
+	}
+
+	symbol, err := nextTask.GetParamString("symbol")
+	if err != nil {
+		log.Printf("Error getting symbol from task: %v", err)
+		w.Stats.RecordError()
+		return consumeItem{}, false
+	}
+
+	source, err := nextTask.GetParamString("source")
+	if err != nil {
+		log.Printf("Error getting source from task: %v", err)
+		w.Stats.RecordError()
+		return consumeItem{}, false
+	}
+
+	log.Printf("Worker %s processing article from source %s for symbol %s",
+		w.Name(), source, symbol)
+	labels := ItemLabels{Symbol: symbol, Source: source}
+
+	// Extract article from task
+	article, err := nextTask.GetArticle()
+	if err != nil {
+		log.Printf("Error extracting article: %v", err)
+		w.Stats.RecordError()
+		return consumeItem{}, false
+	}
+
+	// Convert to database model
+	return consumeItem{
+		task: nextTask,
+		article: database.Article{
+			Title:     article.Title,
+			URL:       article.URL,
+			Text:      article.Text,
+			SiteName:  article.SiteName,
+			ScrapedAt: article.ScrapedAt,
+			Symbol:    symbol,
+		},
+		labels: labels,
+	}, true
+}
@@ -1,44 +1,208 @@
 package worker
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
+	"github.com/guillermoballester/propagatorGo/internal/clock"
 	"github.com/guillermoballester/propagatorGo/internal/config"
 	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/model"
+	"github.com/guillermoballester/propagatorGo/internal/pipeline"
 	"github.com/guillermoballester/propagatorGo/internal/repository"
 	scraper "github.com/guillermoballester/propagatorGo/internal/scrapper"
 	"github.com/guillermoballester/propagatorGo/internal/task"
 )
 
+// stateLoadTimeout bounds reading saved state when a work manager is created
+const stateLoadTimeout = 5 * time.Second
+
+// StateStore persists the position of each source's round-robin and the
+// scrape state of its symbols, so a restarted instance continues where the
+// previous one stopped
+type StateStore interface {
+	SaveCursor(ctx context.Context, source, symbol string) error
+	LoadCursor(ctx context.Context, source string) (string, error)
+	SaveSymbolState(ctx context.Context, state model.SymbolState) error
+	LoadSymbolStates(ctx context.Context, source string) ([]model.SymbolState, error)
+}
+
 // Factory creates workers based on configuration
 type Factory struct {
+	config         *config.Config
 	scraperService *scraper.Service
 	taskService    *task.Service
 	repository     *repository.ArticleRepository
-	workManager    *WorkManager
+	workManagers   map[string]*WorkManager // One per source
+	store          StateStore
+	apiHandlers    *APIHandlers
+	stages         *pipeline.Registry
+	pipelines      map[string]*pipeline.Pipeline // One per consumer pool
+	clock          clock.Clock
+	mu             sync.Mutex
 }
 
 // NewWorkerFactory creates a new worker factory
 func NewWorkerFactory(cfg *config.Config, scraperSvc *scraper.Service, taskSvc *task.Service, repo *repository.ArticleRepository) *Factory {
 	return &Factory{
+		config:         cfg,
 		scraperService: scraperSvc,
 		taskService:    taskSvc,
 		repository:     repo,
-		workManager:    NewWorkManagerFromConfig(cfg),
+		workManagers:   make(map[string]*WorkManager),
+		apiHandlers:    NewAPIHandlers(),
+		stages:         pipeline.NewRegistry(cfg),
+		pipelines:      make(map[string]*pipeline.Pipeline),
+		clock:          clock.New(),
+	}
+}
+
+// SetClock makes the workers and work managers created from now on tell the
+// time and wait on a clock other than the wall clock, e.g. a fake clock in
+// tests
+func (f *Factory) SetClock(c clock.Clock) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.clock = c
+}
+
+// SetStateStore enables saving and restoring work manager positions and
+// symbol states. It must be called before any worker is created.
+func (f *Factory) SetStateStore(store StateStore) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.store = store
+}
+
+// RegisterAPIHandler makes a handler available to API workers under a name
+func (f *Factory) RegisterAPIHandler(name string, handler APIResponseHandler) {
+	f.apiHandlers.Register(name, handler)
+}
+
+// RegisterStage makes a pipeline stage available to consumer pools under a name
+func (f *Factory) RegisterStage(name string, factory pipeline.StageFactory) {
+	f.stages.Register(name, factory)
+}
+
+// Pipeline returns the pipeline of a consumer pool, or nil when it has none
+func (f *Factory) Pipeline(jobName string) *pipeline.Pipeline {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.pipelines[jobName]
+}
+
+// pipelineFor returns the pipeline shared by all consumers of a pool,
+// building it from the pool configuration the first time
+func (f *Factory) pipelineFor(cfg config.WorkerConfig) (*pipeline.Pipeline, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if p, ok := f.pipelines[cfg.JobName]; ok {
+		return p, nil
+	}
+
+	p, err := f.stages.Build(cfg.Pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("error building pipeline for %s: %w", cfg.JobName, err)
+	}
+	if p != nil {
+		f.pipelines[cfg.JobName] = p
+	}
+	return p, nil
+}
+
+// WorkManager returns the work manager shared by all scrapers of a source,
+// resuming from the saved state when one exists
+func (f *Factory) WorkManager(source string) *WorkManager {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wm, ok := f.workManagers[source]
+	if !ok {
+		wm = NewWorkManagerFromConfig(f.config)
+		wm.source = source
+		wm.clock = f.clock
+		wm.SetPolicy(NewSchedulingPolicy(f.config.Scraper.Scheduling))
+		f.restoreState(wm)
+		f.workManagers[source] = wm
+	}
+	return wm
+}
+
+// restoreState loads a new work manager's saved state and attaches the store
+// to it; callers must hold f.mu
+func (f *Factory) restoreState(wm *WorkManager) {
+	if f.store == nil {
+		return
+	}
+	wm.store = f.store
+
+	ctx, cancel := context.WithTimeout(context.Background(), stateLoadTimeout)
+	defer cancel()
+
+	cursor, err := f.store.LoadCursor(ctx, wm.source)
+	if err != nil {
+		log.Printf("Error loading cursor for source %s: %v", wm.source, err)
+	}
+
+	states, err := f.store.LoadSymbolStates(ctx, wm.source)
+	if err != nil {
+		log.Printf("Error loading symbol states for source %s: %v", wm.source, err)
+	}
+
+	wm.restoreStates(states, cursor)
+}
+
+// Checkpoint saves the position of every work manager. Returns the number of
+// cursors saved.
+func (f *Factory) Checkpoint(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.store == nil {
+		return 0, nil
+	}
+
+	saved := 0
+	for source, wm := range f.workManagers {
+		symbol := wm.Position()
+		if symbol == "" {
+			continue
+		}
+		if err := f.store.SaveCursor(ctx, source, symbol); err != nil {
+			return saved, fmt.Errorf("error saving cursor for source %s: %w", source, err)
+		}
+		saved++
 	}
+	return saved, nil
 }
 
-// CreateWorker creates a worker of the specified type
-func (f *Factory) CreateWorker(id int, workerType string, source string) (Worker, error) {
-	baseName := fmt.Sprintf("%s%d", workerType, id)
-	baseWorker := NewBaseWorker(id, baseName, workerType)
+// CreateWorker creates a worker of the type described by the pool configuration
+func (f *Factory) CreateWorker(id int, cfg config.WorkerConfig) (Worker, error) {
+	baseName := fmt.Sprintf("%s%d", cfg.WorkerType, id)
+	f.mu.Lock()
+	c := f.clock
+	f.mu.Unlock()
+	baseWorker := NewBaseWorker(id, baseName, cfg.WorkerType, c)
 
-	switch workerType {
+	switch cfg.WorkerType {
 	case constants.WorkerTypeScraper:
-		return NewScraperWorker(baseWorker, f.scraperService, f.workManager, source), nil
+		return NewScraperWorker(baseWorker, f.scraperService, f.taskService, f.WorkManager, cfg.Source, cfg.ScrapeMode, cfg.RunMode), nil
 	case constants.WorkerTypeConsumer:
-		return NewConsumerWorker(baseWorker, f.taskService, f.repository), nil
+		p, err := f.pipelineFor(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewConsumerWorker(baseWorker, f.taskService, f.repository, p, cfg.BatchSize, cfg.BatchWindow, cfg.RunMode), nil
+	case constants.WorkerTypeAPI:
+		return NewAPIWorker(baseWorker, f.taskService, f.apiHandlers, cfg.APIHandler, cfg.FollowUpTaskType, cfg.RunMode), nil
 	default:
-		return nil, fmt.Errorf("unknown worker type: %s", workerType)
+		return nil, fmt.Errorf("unknown worker type: %s", cfg.WorkerType)
 	}
 }
@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the latency histogram buckets, from
+// fast database writes to slow scrapes
+var latencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	25 * time.Second,
+	time.Minute,
+}
+
+// Histogram counts durations in fixed buckets. It is not safe for concurrent
+// use; Stats guards its histograms with its own mutex.
+type Histogram struct {
+	counts []int64 // One per bucket, plus one for durations above the last bound
+	count  int64
+	sum    time.Duration
+	max    time.Duration
+}
+
+// HistogramSnapshot summarises a histogram
+type HistogramSnapshot struct {
+	Count   int64         `json:"count"`
+	Mean    time.Duration `json:"mean"`
+	P50     time.Duration `json:"p50"`
+	P95     time.Duration `json:"p95"`
+	P99     time.Duration `json:"p99"`
+	Max     time.Duration `json:"max"`
+	Buckets []BucketCount `json:"buckets,omitempty"`
+}
+
+// BucketCount is the number of durations up to a bound. The last bucket has
+// no bound and holds everything slower than the previous one.
+type BucketCount struct {
+	UpTo  time.Duration `json:"up_to,omitempty"`
+	Count int64         `json:"count"`
+}
+
+// NewHistogram creates an empty latency histogram
+func NewHistogram() *Histogram {
+	return &Histogram{
+		counts: make([]int64, len(latencyBuckets)+1),
+	}
+}
+
+// Observe adds a duration to the histogram
+func (h *Histogram) Observe(d time.Duration) {
+	i := 0
+	for i < len(latencyBuckets) && d > latencyBuckets[i] {
+		i++
+	}
+	h.counts[i]++
+	h.count++
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// Merge adds the counts of another histogram to this one
+func (h *Histogram) Merge(other *Histogram) {
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.count += other.count
+	h.sum += other.sum
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+// Quantile estimates the duration below which a fraction q of the
+// observations fall, interpolating within the bucket
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := q * float64(h.count)
+	var seen int64
+	for i, c := range h.counts {
+		if c == 0 || float64(seen+c) < rank {
+			seen += c
+			continue
+		}
+
+		var lower time.Duration
+		if i > 0 {
+			lower = latencyBuckets[i-1]
+		}
+		upper := h.max
+		if i < len(latencyBuckets) && latencyBuckets[i] < upper {
+			upper = latencyBuckets[i]
+		}
+		if upper < lower {
+			return upper
+		}
+
+		fraction := (rank - float64(seen)) / float64(c)
+		return lower + time.Duration(fraction*float64(upper-lower))
+	}
+	return h.max
+}
+
+// Snapshot summarises the histogram. Bucket counts are only included when
+// withBuckets is set.
+func (h *Histogram) Snapshot(withBuckets bool) HistogramSnapshot {
+	snapshot := HistogramSnapshot{
+		Count: h.count,
+		P50:   h.Quantile(0.50),
+		P95:   h.Quantile(0.95),
+		P99:   h.Quantile(0.99),
+		Max:   h.max,
+	}
+	if h.count > 0 {
+		snapshot.Mean = h.sum / time.Duration(h.count)
+	}
+
+	if withBuckets {
+		snapshot.Buckets = make([]BucketCount, len(h.counts))
+		for i, c := range h.counts {
+			snapshot.Buckets[i].Count = c
+			if i < len(latencyBuckets) {
+				snapshot.Buckets[i].UpTo = latencyBuckets[i]
+			}
+		}
+	}
+	return snapshot
+}
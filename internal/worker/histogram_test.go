@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	h := NewHistogram()
+	if got := h.Quantile(0.5); got != 0 {
+		t.Errorf("Expected 0 for an empty histogram, got %s", got)
+	}
+
+	for i := 0; i < 90; i++ {
+		h.Observe(20 * time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		h.Observe(2 * time.Second)
+	}
+
+	tests := []struct {
+		q        float64
+		expected time.Duration
+	}{
+		// Interpolated within the 10ms-25ms bucket
+		{0.5, 10*time.Millisecond + 15*time.Millisecond*50/90},
+		// The 1s-2.5s bucket is capped at the max observed
+		{0.95, 1500 * time.Millisecond},
+		{0.99, 1900 * time.Millisecond},
+		{1, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := h.Quantile(tt.q); got != tt.expected {
+			t.Errorf("Expected p%v %s, got %s", tt.q*100, tt.expected, got)
+		}
+	}
+}
+
+func TestHistogramOverflowAndMerge(t *testing.T) {
+	h := NewHistogram()
+	h.Observe(5 * time.Millisecond)
+
+	slow := NewHistogram()
+	slow.Observe(2 * time.Minute)
+	h.Merge(slow)
+
+	snapshot := h.Snapshot(true)
+	if snapshot.Count != 2 || snapshot.Max != 2*time.Minute {
+		t.Errorf("Expected 2 observations up to 2m, got %d up to %s", snapshot.Count, snapshot.Max)
+	}
+	if snapshot.Mean != (2*time.Minute+5*time.Millisecond)/2 {
+		t.Errorf("Expected mean %s, got %s", (2*time.Minute+5*time.Millisecond)/2, snapshot.Mean)
+	}
+
+	last := snapshot.Buckets[len(snapshot.Buckets)-1]
+	if last.Count != 1 || last.UpTo != 0 {
+		t.Errorf("Expected the unbounded bucket to hold 1 observation, got %+v", last)
+	}
+	if got := h.Quantile(1); got != 2*time.Minute {
+		t.Errorf("Expected p100 2m, got %s", got)
+	}
+}
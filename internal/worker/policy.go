@@ -0,0 +1,182 @@
+package worker
+
+import (
+	"math"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/model"
+)
+
+const (
+	defaultMinScrapeInterval = 5 * time.Minute
+	defaultMaxScrapeInterval = 2 * time.Hour
+
+	// maxFailureBackoff caps how many failures double a symbol's interval
+	maxFailureBackoff = 4
+)
+
+// defaultTierWeights are used for tiers missing from the configuration
+var defaultTierWeights = map[string]float64{
+	constants.TierHigh:   4,
+	constants.TierNormal: 1,
+	constants.TierLow:    0.25,
+}
+
+// Candidate is a stock a scheduling policy may pick, with its scrape state.
+// State is the zero value for symbols never scraped.
+type Candidate struct {
+	Index int
+	Stock config.Stock
+	State model.SymbolState
+}
+
+// SchedulingPolicy chooses which stock a work manager hands out next
+type SchedulingPolicy interface {
+	// Name identifies the policy
+	Name() string
+
+	// Pick returns the Index of the candidate to scrape next, or false when
+	// no candidate is due. Candidates are in stock list order and cursor is
+	// the index following the last stock handed out.
+	Pick(candidates []Candidate, cursor int, now time.Time) (int, bool)
+}
+
+// NewSchedulingPolicy creates the policy described by the configuration
+func NewSchedulingPolicy(cfg config.SchedulingPolicyConfig) SchedulingPolicy {
+	if cfg.Policy == constants.SchedulingAdaptive {
+		return NewAdaptivePolicy(cfg)
+	}
+	return RoundRobinPolicy{}
+}
+
+// RoundRobinPolicy hands out every stock in turn, regardless of its state
+type RoundRobinPolicy struct{}
+
+// Name identifies the policy
+func (RoundRobinPolicy) Name() string {
+	return constants.SchedulingRoundRobin
+}
+
+// Pick returns the first candidate at or after the cursor, wrapping around
+func (RoundRobinPolicy) Pick(candidates []Candidate, cursor int, now time.Time) (int, bool) {
+	if len(candidates) == 0 {
+		return 0, false
+	}
+	for _, c := range candidates {
+		if c.Index >= cursor {
+			return c.Index, true
+		}
+	}
+	return candidates[0].Index, true
+}
+
+// AdaptivePolicy gives each symbol its own scrape interval, shorter for
+// higher tiers and for symbols that recently yielded new articles, longer
+// after consecutive failures, always between the configured bounds. Among
+// the symbols that are due, the one longest without a success relative to
+// its interval goes first.
+type AdaptivePolicy struct {
+	minInterval time.Duration
+	maxInterval time.Duration
+	tierWeights map[string]float64
+}
+
+// NewAdaptivePolicy creates an adaptive policy, applying defaults
+func NewAdaptivePolicy(cfg config.SchedulingPolicyConfig) *AdaptivePolicy {
+	if cfg.MinInterval <= 0 {
+		cfg.MinInterval = defaultMinScrapeInterval
+	}
+	if cfg.MaxInterval < cfg.MinInterval {
+		cfg.MaxInterval = defaultMaxScrapeInterval
+		if cfg.MaxInterval < cfg.MinInterval {
+			cfg.MaxInterval = cfg.MinInterval
+		}
+	}
+
+	weights := make(map[string]float64, len(defaultTierWeights))
+	for tier, weight := range defaultTierWeights {
+		weights[tier] = weight
+	}
+	for tier, weight := range cfg.TierWeights {
+		if weight > 0 {
+			weights[tier] = weight
+		}
+	}
+
+	return &AdaptivePolicy{
+		minInterval: cfg.MinInterval,
+		maxInterval: cfg.MaxInterval,
+		tierWeights: weights,
+	}
+}
+
+// Name identifies the policy
+func (p *AdaptivePolicy) Name() string {
+	return constants.SchedulingAdaptive
+}
+
+// Pick returns the due candidate that is most overdue, never-scraped first
+func (p *AdaptivePolicy) Pick(candidates []Candidate, cursor int, now time.Time) (int, bool) {
+	best := -1
+	bestScore := 0.0
+
+	for _, c := range candidates {
+		if c.State.LastScrapedAt.IsZero() {
+			return c.Index, true
+		}
+
+		interval := p.Interval(c.Stock, c.State)
+		if now.Before(c.State.LastScrapedAt.Add(interval)) {
+			continue
+		}
+
+		// Symbols that have not succeeded for many intervals catch up first
+		sinceSuccess := now.Sub(c.State.LastSuccessAt)
+		if c.State.LastSuccessAt.IsZero() {
+			sinceSuccess = now.Sub(c.State.LastScrapedAt)
+		}
+		score := float64(sinceSuccess) / float64(interval)
+		if best == -1 || score > bestScore {
+			best = c.Index
+			bestScore = score
+		}
+	}
+
+	return best, best != -1
+}
+
+// Interval returns how long to wait between scrapes of a symbol
+func (p *AdaptivePolicy) Interval(stock config.Stock, state model.SymbolState) time.Duration {
+	tier := stock.Tier
+	if tier == "" {
+		tier = constants.TierNormal
+	}
+	weight, ok := p.tierWeights[tier]
+	if !ok {
+		weight = p.tierWeights[constants.TierNormal]
+	}
+
+	// Each new article per scrape shortens the interval further
+	interval := float64(p.maxInterval) / (weight * (1 + state.ArticleYield))
+
+	failures := state.ConsecutiveFailures
+	if failures > maxFailureBackoff {
+		failures = maxFailureBackoff
+	}
+	interval *= math.Pow(2, float64(failures))
+
+	return clampDuration(time.Duration(interval), p.minInterval, p.maxInterval)
+}
+
+// clampDuration bounds d to [min, max]
+func clampDuration(d, min, max time.Duration) time.Duration {
+	if d < min {
+		return min
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/model"
+)
+
+func TestAdaptivePolicyInterval(t *testing.T) {
+	p := NewAdaptivePolicy(config.SchedulingPolicyConfig{})
+	custom := NewAdaptivePolicy(config.SchedulingPolicyConfig{
+		MinInterval: time.Minute,
+		MaxInterval: time.Hour,
+		TierWeights: map[string]float64{constants.TierHigh: 8, constants.TierLow: -1},
+	})
+
+	tests := []struct {
+		name     string
+		policy   *AdaptivePolicy
+		tier     string
+		yield    float64
+		failures int
+		expected time.Duration
+	}{
+		{"normal tier waits the max interval", p, constants.TierNormal, 0, 0, 2 * time.Hour},
+		{"empty tier counts as normal", p, "", 0, 0, 2 * time.Hour},
+		{"unknown tier counts as normal", p, "vip", 0, 0, 2 * time.Hour},
+		{"high tier divides by its weight", p, constants.TierHigh, 0, 0, 30 * time.Minute},
+		{"yield shortens the interval", p, constants.TierHigh, 1, 0, 15 * time.Minute},
+		{"clamped to the min interval", p, constants.TierHigh, 100, 0, 5 * time.Minute},
+		{"low tier clamped to the max interval", p, constants.TierLow, 0, 0, 2 * time.Hour},
+		{"failures double the interval", p, constants.TierHigh, 0, 1, time.Hour},
+		{"failure backoff is capped", p, constants.TierHigh, 7, 10, time.Hour},
+		{"configured weights override the defaults", custom, constants.TierHigh, 0, 0, 7*time.Minute + 30*time.Second},
+		{"non-positive weights are ignored", custom, constants.TierLow, 0, 0, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stock := config.Stock{Symbol: "AAPL", Tier: tt.tier}
+			state := model.SymbolState{ArticleYield: tt.yield, ConsecutiveFailures: tt.failures}
+
+			if got := tt.policy.Interval(stock, state); got != tt.expected {
+				t.Errorf("Expected interval %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewAdaptivePolicyBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.SchedulingPolicyConfig
+		min, max time.Duration
+	}{
+		{"defaults", config.SchedulingPolicyConfig{}, defaultMinScrapeInterval, defaultMaxScrapeInterval},
+		{"max below min falls back to the default max", config.SchedulingPolicyConfig{MinInterval: time.Minute, MaxInterval: time.Second}, time.Minute, defaultMaxScrapeInterval},
+		{"min above the default max", config.SchedulingPolicyConfig{MinInterval: 3 * time.Hour}, 3 * time.Hour, 3 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewAdaptivePolicy(tt.cfg)
+			if p.minInterval != tt.min || p.maxInterval != tt.max {
+				t.Errorf("Expected bounds [%s, %s], got [%s, %s]", tt.min, tt.max, p.minInterval, p.maxInterval)
+			}
+		})
+	}
+}
+
+func TestAdaptivePolicyPick(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	p := NewAdaptivePolicy(config.SchedulingPolicyConfig{})
+	high := config.Stock{Symbol: "NVDA", Tier: constants.TierHigh} // 30 minute interval
+	normal := config.Stock{Symbol: "KO"}                           // 2 hour interval
+
+	scraped := func(ago, successAgo time.Duration) model.SymbolState {
+		state := model.SymbolState{LastScrapedAt: now.Add(-ago)}
+		if successAgo > 0 {
+			state.LastSuccessAt = now.Add(-successAgo)
+		}
+		return state
+	}
+
+	tests := []struct {
+		name       string
+		candidates []Candidate
+		expected   int
+		ok         bool
+	}{
+		{
+			name:     "no candidates",
+			expected: -1,
+		},
+		{
+			name: "never scraped goes first",
+			candidates: []Candidate{
+				{Index: 0, Stock: high, State: scraped(time.Hour, time.Hour)},
+				{Index: 1, Stock: normal},
+			},
+			expected: 1,
+			ok:       true,
+		},
+		{
+			name: "none due",
+			candidates: []Candidate{
+				{Index: 0, Stock: high, State: scraped(10*time.Minute, 10*time.Minute)},
+				{Index: 1, Stock: normal, State: scraped(time.Hour, time.Hour)},
+			},
+			expected: -1,
+		},
+		{
+			name: "most overdue relative to its interval",
+			candidates: []Candidate{
+				{Index: 0, Stock: normal, State: scraped(3*time.Hour, 3*time.Hour)}, // 1.5 intervals
+				{Index: 1, Stock: high, State: scraped(time.Hour, time.Hour)},       // 2 intervals
+			},
+			expected: 1,
+			ok:       true,
+		},
+		{
+			name: "failing symbols count from their last success",
+			candidates: []Candidate{
+				{Index: 0, Stock: high, State: scraped(2*time.Hour, 2*time.Hour)},
+				{Index: 1, Stock: normal, State: model.SymbolState{LastScrapedAt: now.Add(-5 * time.Hour), LastSuccessAt: now.Add(-20 * time.Hour), ConsecutiveFailures: 1}},
+			},
+			expected: 1,
+			ok:       true,
+		},
+		{
+			name: "never succeeded counts from the last scrape",
+			candidates: []Candidate{
+				{Index: 0, Stock: high, State: scraped(2*time.Hour, 2*time.Hour)},
+				{Index: 1, Stock: high, State: scraped(3*time.Hour, 0)},
+			},
+			expected: 1,
+			ok:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := p.Pick(tt.candidates, 0, now)
+			if ok != tt.ok || (ok && got != tt.expected) {
+				t.Errorf("Expected %d (%v), got %d (%v)", tt.expected, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestRoundRobinPolicyPick(t *testing.T) {
+	candidates := []Candidate{{Index: 1}, {Index: 3}, {Index: 4}}
+
+	tests := []struct {
+		cursor   int
+		expected int
+	}{
+		{0, 1},
+		{2, 3},
+		{4, 4},
+		{5, 1},
+	}
+
+	for _, tt := range tests {
+		got, ok := RoundRobinPolicy{}.Pick(candidates, tt.cursor, time.Now())
+		if !ok || got != tt.expected {
+			t.Errorf("Expected candidate %d at cursor %d, got %d (%v)", tt.expected, tt.cursor, got, ok)
+		}
+	}
+
+	if _, ok := (RoundRobinPolicy{}).Pick(nil, 0, time.Now()); ok {
+		t.Errorf("Expected no pick without candidates")
+	}
+}
@@ -5,26 +5,150 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/pipeline"
 )
 
+// NewWorkerFunc creates a worker with the given ID, used when a pool grows
+type NewWorkerFunc func(id int) (Worker, error)
+
 // Pool manages a collection of workers
 type Pool struct {
-	workers    []Worker
+	name       string
+	workers    []Worker // Workers currently part of the pool
+	retiring   []Worker // Workers removed by scale-down, still finishing their item
+	retired    *Stats   // Stats of the workers removed by scale-down once they exited
 	numWorkers int
 	wg         sync.WaitGroup
 	mu         sync.Mutex
 	isRunning  bool
+	stopping   bool
+	stopCh     chan struct{}
+	ctx        context.Context
+	clock      clock.Clock
+
+	newWorker  NewWorkerFunc
+	nextID     int
+	autoscaler *Autoscaler
+	supervisor *Supervisor
+	crashLoop  chan error
+
+	runToCompletion bool
+	running         int           // Workers currently running
+	finished        chan struct{} // Closed once every worker has exited
+}
+
+// PoolStats aggregates the stats of every worker that ran in the pool
+type PoolStats struct {
+	Name            string                `json:"name"`
+	Workers         int                   `json:"workers"`
+	IsRunning       bool                  `json:"is_running"`
+	ItemsProcessed  int64                 `json:"items_processed"`
+	ItemsSuccessful int64                 `json:"items_successful"`
+	ItemsFailed     int64                 `json:"items_failed"`
+	ProcessingTime  int64                 `json:"processing_time"`
+	Runtime         time.Duration         `json:"runtime"` // Summed over workers
+	Panics          int64                 `json:"panics"`
+	Restarts        int64                 `json:"restarts"`
+	Requeued        int64                 `json:"requeued"`
+	Retried         int64                 `json:"retried"`
+	Errors          int64                 `json:"errors"`
+	Scaling         *ScalingStats         `json:"scaling,omitempty"`
+	Pipeline        []pipeline.StageStats `json:"pipeline,omitempty"` // Consumer pools only
+	StatsDetail
+	WorkerStats []WorkerStats `json:"worker_stats"`
+}
+
+// WorkerStats summarises a single worker of a pool
+type WorkerStats struct {
+	Name           string            `json:"name"`
+	IsRunning      bool              `json:"is_running"`
+	Runtime        time.Duration     `json:"runtime"`
+	ItemsProcessed int64             `json:"items_processed"`
+	ItemsFailed    int64             `json:"items_failed"`
+	Latency        HistogramSnapshot `json:"latency"`
 }
 
-// NewPool creates a new worker pool with the specified size
-func NewPool(size int) *Pool {
-	return &Pool{
+// NewPool creates a new worker pool with the specified size. Workers are
+// supervised with the default restart policy until SetRestartPolicy is called.
+func NewPool(name string, size int) *Pool {
+	p := &Pool{
+		name:       name,
 		workers:    make([]Worker, 0, size),
 		numWorkers: size,
 		isRunning:  false,
+		crashLoop:  make(chan error, 1),
+		clock:      clock.New(),
+		retired:    NewStats(clock.New()),
+	}
+	p.supervisor = NewSupervisor(name, config.RestartConfig{}, p.escalate)
+	return p
+}
+
+// SetClock makes the pool, its supervisor and its autoscaler wait on a clock
+// other than the wall clock, e.g. a fake clock in tests. Workers get their
+// clock when created.
+func (p *Pool) SetClock(c clock.Clock) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.clock = c
+	p.supervisor.clock = c
+	if p.autoscaler != nil {
+		p.autoscaler.clock = c
+	}
+}
+
+// SetRestartPolicy configures how the pool's workers are restarted
+func (p *Pool) SetRestartPolicy(cfg config.RestartConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.supervisor = NewSupervisor(p.name, cfg, p.escalate)
+	p.supervisor.completes = p.runToCompletion
+	p.supervisor.clock = p.clock
+}
+
+// SetRunToCompletion marks the pool's workers as finishing on their own, e.g.
+// after one pass over the stocks. Their clean exits are never restarted.
+func (p *Pool) SetRunToCompletion(v bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.runToCompletion = v
+	p.supervisor.completes = v
+}
+
+// Finished is closed once every worker of the current run has exited
+func (p *Pool) Finished() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.finished
+}
+
+// CrashLoop delivers an error when a worker of the pool exhausted its restart
+// budget, so whoever runs the pool can react
+func (p *Pool) CrashLoop() <-chan error {
+	return p.crashLoop
+}
+
+// escalate reports a crash-looping worker without blocking the supervisor
+func (p *Pool) escalate(err *CrashLoopError) {
+	select {
+	case p.crashLoop <- err:
+	default:
 	}
 }
 
+// Name returns the pool's name
+func (p *Pool) Name() string {
+	return p.name
+}
+
 // AddWorker adds a worker to the pool
 func (p *Pool) AddWorker(w Worker) error {
 	p.mu.Lock()
@@ -39,9 +163,22 @@ func (p *Pool) AddWorker(w Worker) error {
 	}
 
 	p.workers = append(p.workers, w)
+	p.nextID++
 	return nil
 }
 
+// EnableAutoscaling lets the pool resize itself while running. newWorker is
+// used to create workers when the pool grows beyond its current size.
+func (p *Pool) EnableAutoscaling(a *Autoscaler, newWorker NewWorkerFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a.pool = p
+	a.clock = p.clock
+	p.autoscaler = a
+	p.newWorker = newWorker
+}
+
 // Start launches all workers in the pool
 func (p *Pool) Start(ctx context.Context) error {
 	p.mu.Lock()
@@ -50,38 +187,220 @@ func (p *Pool) Start(ctx context.Context) error {
 		return fmt.Errorf("worker pool is already running")
 	}
 	p.isRunning = true
-	p.mu.Unlock()
+	p.ctx = ctx
+	p.stopCh = make(chan struct{})
+	p.finished = make(chan struct{})
+	if len(p.workers) == 0 {
+		close(p.finished)
+	}
+
+	// Drop escalations left over from a previous run
+	select {
+	case <-p.crashLoop:
+	default:
+	}
 
 	// Launch each worker in its own goroutine
 	for _, w := range p.workers {
-		p.wg.Add(1)
-		worker := w // Capture variable for goroutine
-
-		go func() {
-			defer p.wg.Done()
+		p.launch(w)
+	}
+	autoscaler := p.autoscaler
+	stopCh := p.stopCh
+	p.mu.Unlock()
 
-			log.Printf("Starting worker: %s", worker.Name())
-			if err := worker.Start(ctx); err != nil {
-				log.Printf("Worker %s error: %v", worker.Name(), err)
-			}
-			log.Printf("Worker %s stopped", worker.Name())
-		}()
+	if autoscaler != nil {
+		go autoscaler.Run(ctx, stopCh)
 	}
 
 	return nil
 }
 
+// launch runs a supervised worker in its own goroutine; callers must hold p.mu
+func (p *Pool) launch(worker Worker) {
+	p.wg.Add(1)
+	p.running++
+	ctx := p.ctx
+	supervisor := p.supervisor
+
+	go func() {
+		defer p.wg.Done()
+		defer p.exited(worker)
+
+		log.Printf("Starting worker: %s", worker.Name())
+		supervisor.Run(ctx, worker, func() bool {
+			return p.isStopped(worker)
+		})
+		log.Printf("Worker %s stopped", worker.Name())
+	}()
+}
+
+// exited records a worker goroutine exiting and signals when none are left.
+// A worker removed by scale-down is dropped, keeping only its stats.
+func (p *Pool) exited(worker Worker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, w := range p.retiring {
+		if w == worker {
+			p.retiring = append(p.retiring[:i], p.retiring[i+1:]...)
+			p.retired.absorb(worker.GetStats())
+			break
+		}
+	}
+
+	p.running--
+	if p.running == 0 {
+		close(p.finished)
+	}
+}
+
+// isStopped reports whether the pool asked a worker to stop, either because
+// the pool is stopping or because the worker was scaled away
+func (p *Pool) isStopped(worker Worker) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopping || !p.isRunning {
+		return true
+	}
+	for _, w := range p.workers {
+		if w == worker {
+			return false
+		}
+	}
+	return true
+}
+
+// ScaleTo grows or shrinks a running pool to the given number of workers.
+// Removed workers are asked to stop and finish their in-flight item in the
+// background. Returns the resulting number of workers.
+func (p *Pool) ScaleTo(size int) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.isRunning || p.stopping || p.running == 0 {
+		return len(p.workers), fmt.Errorf("cannot scale pool %s while it is stopped", p.name)
+	}
+	if p.newWorker == nil {
+		return len(p.workers), fmt.Errorf("pool %s has no worker constructor", p.name)
+	}
+
+	for len(p.workers) < size {
+		w, err := p.newWorker(p.nextID)
+		if err != nil {
+			return len(p.workers), fmt.Errorf("error creating worker: %w", err)
+		}
+		p.nextID++
+		p.workers = append(p.workers, w)
+		p.launch(w)
+	}
+
+	for len(p.workers) > size {
+		last := len(p.workers) - 1
+		w := p.workers[last]
+		p.workers = p.workers[:last]
+		p.retiring = append(p.retiring, w)
+
+		if err := w.Stop(); err != nil {
+			log.Printf("Error stopping worker %s: %v", w.Name(), err)
+		}
+	}
+
+	return len(p.workers), nil
+}
+
+// Size returns the current number of workers
+func (p *Pool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.workers)
+}
+
+// Stats returns the aggregated stats of the pool
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	all := make([]Worker, 0, len(p.workers)+len(p.retiring))
+	all = append(all, p.workers...)
+	all = append(all, p.retiring...)
+	stats := PoolStats{
+		Name:      p.name,
+		Workers:   len(p.workers),
+		IsRunning: p.isRunning,
+	}
+	autoscaler := p.autoscaler
+	now := p.clock.Now()
+
+	// Workers gone after a scale-down only count towards the pool totals. They
+	// are read along with the worker list, so none is counted twice.
+	agg := newStatsAggregate()
+	retired := p.retired.GetSnapshot()
+	p.retired.addTo(agg, now)
+	p.mu.Unlock()
+
+	stats.ItemsProcessed += retired.ItemsProcessed
+	stats.ItemsSuccessful += retired.ItemsSuccessful
+	stats.ItemsFailed += retired.ItemsFailed
+	stats.ProcessingTime += retired.ProcessingTime
+	stats.Runtime += retired.Runtime
+	stats.Panics += retired.Panics
+	stats.Restarts += retired.Restarts
+	stats.Requeued += retired.Requeued
+	stats.Retried += retired.Retried
+	stats.Errors += retired.Errors
+
+	stats.WorkerStats = make([]WorkerStats, 0, len(all))
+	for _, w := range all {
+		workerStats := w.GetStats()
+		snapshot := workerStats.GetSnapshot()
+		runtime := workerStats.GetTotalRuntime()
+		stats.ItemsProcessed += snapshot.ItemsProcessed
+		stats.ItemsSuccessful += snapshot.ItemsSuccessful
+		stats.ItemsFailed += snapshot.ItemsFailed
+		stats.ProcessingTime += snapshot.ProcessingTime
+		stats.Runtime += runtime
+		stats.Panics += snapshot.Panics
+		stats.Restarts += snapshot.Restarts
+		stats.Requeued += snapshot.Requeued
+		stats.Retried += snapshot.Retried
+		stats.Errors += snapshot.Errors
+
+		workerStats.addTo(agg, now)
+		stats.WorkerStats = append(stats.WorkerStats, WorkerStats{
+			Name:           w.Name(),
+			IsRunning:      snapshot.IsRunning,
+			Runtime:        runtime,
+			ItemsProcessed: snapshot.ItemsProcessed,
+			ItemsFailed:    snapshot.ItemsFailed,
+			Latency:        workerStats.Latency(),
+		})
+	}
+	stats.StatsDetail = agg.detail()
+
+	if autoscaler != nil {
+		scaling := autoscaler.Stats()
+		stats.Scaling = &scaling
+	}
+
+	return stats
+}
+
 // Stop gracefully shuts down all workers
 func (p *Pool) Stop() {
 	p.mu.Lock()
-	if !p.isRunning {
+	if !p.isRunning || p.stopping {
 		p.mu.Unlock()
 		return
 	}
+	// Prevent scaling from launching workers while we wait for them
+	p.stopping = true
+	close(p.stopCh)
+	workers := make([]Worker, len(p.workers))
+	copy(workers, p.workers)
 	p.mu.Unlock()
 
 	// Stop each worker
-	for _, w := range p.workers {
+	for _, w := range workers {
 		if err := w.Stop(); err != nil {
 			log.Printf("Error stopping worker %s: %v", w.Name(), err)
 		}
@@ -92,7 +411,31 @@ func (p *Pool) Stop() {
 
 	p.mu.Lock()
 	p.isRunning = false
+	p.stopping = false
+	p.mu.Unlock()
+}
+
+// Shutdown stops the pool like Stop, but gives up waiting for the workers once
+// ctx is done and returns its error. Workers that are still finishing their
+// current item keep running until their own context is cancelled.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	running := p.isRunning
+	finished := p.finished
 	p.mu.Unlock()
+
+	if !running {
+		return nil
+	}
+
+	go p.Stop()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // Wait blocks until all workers have completed
@@ -3,28 +3,59 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/guillermoballester/propagatorGo/internal/constants"
 	scraper "github.com/guillermoballester/propagatorGo/internal/scrapper"
+	"github.com/guillermoballester/propagatorGo/internal/task"
+)
+
+const (
+	// maxBreakerWait bounds each wait for an open circuit breaker, so a
+	// stopped worker notices promptly
+	maxBreakerWait = 30 * time.Second
+
+	// probeWait is how long to wait while another worker probes a source
+	probeWait = time.Second
 )
 
 // ScraperWorker scrapes websites and publishes to Redis
 type ScraperWorker struct {
 	BaseWorker
 	scraperService *scraper.Service
+	taskService    *task.Service
 	source         string
+	mode           string
+	runMode        string
 	WorkManager    *WorkManager
+	workManagers   func(source string) *WorkManager
 }
 
-// NewScraperWorker creates a new scraper worker
-func NewScraperWorker(bw BaseWorker, scraperSvc *scraper.Service, wm *WorkManager, source string) *ScraperWorker {
+// NewScraperWorker creates a new scraper worker. workManagers returns the work
+// manager of a source, which hands out symbols and records their scrape state.
+// In queue mode symbols are pulled from scrape tasks instead of the work
+// manager. In pass run mode the worker stops once the current pass is
+// complete or the queue is drained.
+func NewScraperWorker(bw BaseWorker, scraperSvc *scraper.Service, taskSvc *task.Service, workManagers func(source string) *WorkManager, source string, mode string, runMode string) *ScraperWorker {
+	if mode == "" {
+		mode = constants.ScrapeModeRoundRobin
+	}
+	if runMode == "" {
+		runMode = constants.RunModeWindow
+	}
+
 	return &ScraperWorker{
 		BaseWorker:     bw,
 		scraperService: scraperSvc,
-		WorkManager:    wm,
+		taskService:    taskSvc,
+		WorkManager:    workManagers(source),
+		workManagers:   workManagers,
 		source:         source,
+		mode:           mode,
+		runMode:        runMode,
 	}
 }
 
@@ -33,39 +64,76 @@ func (w *ScraperWorker) Start(ctx context.Context) error {
 	if !w.SetActive(true) {
 		return fmt.Errorf("worker %s is already running", w.Name())
 	}
+	w.Stats.RecordStart()
+	defer w.Stats.RecordStop()
 
 	for w.IsActive() {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			w.Stats.RecordStart()
+			// Hold off while consumers are behind on the consume queue
+			if err := w.taskService.WaitForCapacity(ctx, constants.TaskTypeConsume); err != nil {
+				return err
+			}
 
-			stock := w.WorkManager.GetNextStock()
-			if stock == nil {
-				log.Printf("No stocks available for worker %s", w.Name())
-				time.Sleep(5 * time.Second)
-				continue
+			// Leave the source alone while its circuit breaker is open
+			if w.mode != constants.ScrapeModeQueue {
+				if wait := w.scraperService.Breaker(w.source).RetryAfter(); wait > 0 {
+					if err := w.waitForBreaker(ctx, w.source, wait); err != nil {
+						return err
+					}
+					continue
+				}
 			}
 
-			symbol := stock.Symbol
-			source := w.source
+			symbol, source, inFlight, ok := w.nextSymbol(ctx)
+			if !ok {
+				if w.passComplete(ctx) {
+					log.Printf("Worker %s finished its pass", w.Name())
+					w.SetActive(false)
+					return nil
+				}
+				continue
+			}
 
 			log.Printf("Worker %s processing symbol: %s from source %s",
 				w.Name(), symbol, source)
+			labels := ItemLabels{Symbol: symbol, Source: source}
+			itemStart := w.clock.Now()
 
 			articles, err := w.scraperService.ScrapeAndPublish(
 				ctx,
 				source,
 				symbol,
 			)
+			// A task interrupted by shutdown is left for another worker
+			if err != nil && ctx.Err() != nil && inFlight != nil {
+				requeue(w.taskService, inFlight, w.Stats)
+				return ctx.Err()
+			}
+
+			// Rejected scrapes are not failures of the symbol, retry it once the source recovers
+			if errors.Is(err, scraper.ErrCircuitOpen) {
+				if inFlight != nil {
+					requeue(w.taskService, inFlight, w.Stats)
+				} else {
+					w.workManagers(source).Release(symbol)
+				}
+				if waitErr := w.waitForBreaker(ctx, source, w.scraperService.Breaker(source).RetryAfter()); waitErr != nil {
+					return waitErr
+				}
+				continue
+			}
+
+			w.workManagers(source).RecordScrape(symbol, articles, err)
 			if err != nil {
 				log.Printf("Error processing symbol %s: %v", symbol, err)
-				w.Stats.RecordItemFailed()
+				w.Stats.RecordItemFailed(labels, w.clock.Since(itemStart))
 				continue
 			}
 
-			w.Stats.RecordItemProcessed()
+			w.Stats.RecordItemProcessed(labels, w.clock.Since(itemStart))
 			stats := w.Stats.GetSnapshot()
 			log.Printf("[%s] Task completed for %s. Articles: %d, Total processed: %d, Successful: %d, Failed: %d",
 				w.Name(),
@@ -79,3 +147,105 @@ func (w *ScraperWorker) Start(ctx context.Context) error {
 
 	return nil
 }
+
+// nextSymbol returns the next symbol and source to scrape, either from the
+// shared scrape queue or from the local work manager depending on the mode.
+// In queue mode the dequeued task is returned too, so it can be requeued.
+func (w *ScraperWorker) nextSymbol(ctx context.Context) (string, string, *task.Task, bool) {
+	if w.mode != constants.ScrapeModeQueue {
+		if w.runMode == constants.RunModePass {
+			stock := w.WorkManager.NextInPass()
+			if stock == nil {
+				return "", "", nil, false
+			}
+			return stock.Symbol, w.source, nil, true
+		}
+
+		stock := w.WorkManager.GetNextStock()
+		if stock == nil {
+			log.Printf("No stocks due for worker %s", w.Name())
+			w.clock.Sleep(5 * time.Second)
+			return "", "", nil, false
+		}
+		return stock.Symbol, w.source, nil, true
+	}
+
+	nextTask, err := w.taskService.GetNext(ctx, constants.TaskTypeScrape, 5)
+	if err != nil {
+		log.Printf("Error getting scrape task: %v", err)
+		w.clock.Sleep(1 * time.Second)
+		return "", "", nil, false
+	}
+
+	// If no task returned within timeout, try again
+	if nextTask == nil {
+		return "", "", nil, false
+	}
+
+	symbol, err := nextTask.GetParamString("symbol")
+	if err != nil {
+		log.Printf("Error getting symbol from task: %v", err)
+		w.Stats.RecordError()
+		return "", "", nil, false
+	}
+
+	// Tasks carry their own source, falling back to the pool's source
+	source, err := nextTask.GetParamString("source")
+	if err != nil {
+		source = w.source
+	}
+
+	return symbol, source, nextTask, true
+}
+
+// waitForBreaker waits for a source's circuit breaker, at most maxBreakerWait
+func (w *ScraperWorker) waitForBreaker(ctx context.Context, source string, wait time.Duration) error {
+	if wait <= 0 {
+		wait = probeWait
+	}
+	if wait > maxBreakerWait {
+		wait = maxBreakerWait
+	}
+
+	log.Printf("Worker %s waiting %s, circuit breaker for source %s is open", w.Name(), wait.Round(time.Second), source)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.clock.After(wait):
+		return nil
+	}
+}
+
+// passComplete reports whether a pass-mode worker has nothing left to scrape
+func (w *ScraperWorker) passComplete(ctx context.Context) bool {
+	if w.runMode != constants.RunModePass {
+		return false
+	}
+	if w.mode == constants.ScrapeModeQueue {
+		return queueDrained(ctx, w.taskService, constants.TaskTypeScrape)
+	}
+	return true
+}
+
+// requeue puts a task interrupted by shutdown back on its queue
+func requeue(taskSvc *task.Service, t *task.Task, stats *Stats) {
+	ctx, cancel := context.WithTimeout(context.Background(), requeueTimeout)
+	defer cancel()
+
+	if err := taskSvc.EnqueueTask(ctx, t); err != nil {
+		log.Printf("Error requeueing %s task %s: %v", t.Type, t.ID, err)
+		return
+	}
+	stats.RecordRequeue()
+}
+
+// queueDrained reports whether a task queue is empty
+func queueDrained(ctx context.Context, taskSvc *task.Service, taskType string) bool {
+	length, err := taskSvc.QueueLength(ctx, taskType)
+	if err != nil {
+		log.Printf("Error checking %s queue length: %v", taskType, err)
+		return false
+	}
+	return length == 0
+}
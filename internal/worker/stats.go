@@ -1,76 +1,179 @@
 package worker
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
 )
 
+// windowSlots is the number of one-minute slots kept for rolling windows,
+// which bounds the longest window
+const windowSlots = 60
+
 // Stats tracks operational metrics for a worker
 type Stats struct {
-	ItemsProcessed  int64      // Total items processed
-	ItemsSuccessful int64      // Successfully processed items
-	ItemsFailed     int64      // Failed items
-	LastProcessedAt time.Time  // When last item was processed
-	StartTime       time.Time  // When the worker started
-	StopTime        time.Time  // When the worker stopped
-	ProcessingTime  int64      // Total time spent processing in nanoseconds
-	IsRunning       bool       // Is the worker currently running
-	mu              sync.Mutex // Mutex for updating stats
-}
-
-// NewStats creates a new Stats instance
-func NewStats() *Stats {
+	ItemsProcessed  int64         // Total items processed
+	ItemsSuccessful int64         // Successfully processed items
+	ItemsFailed     int64         // Failed items
+	LastProcessedAt time.Time     // When last item was processed
+	StartTime       time.Time     // When the current run started
+	StopTime        time.Time     // When the last run stopped
+	Runtime         time.Duration // Time spent running in previous runs
+	ProcessingTime  int64         // Total time spent processing items in nanoseconds
+	IsRunning       bool          // Is the worker currently running
+	Panics          int64         // Panics recovered by the supervisor
+	Restarts        int64         // Times the supervisor restarted the worker
+	Requeued        int64         // Interrupted tasks put back on their queue
+	Retried         int64         // Failed tasks put back on their queue for another attempt
+	Errors          int64         // Failed items that never started, e.g. queue or decode errors
+	LastPanic       string        // Value of the most recent panic
+	LastPanicAt     time.Time     // When the most recent panic happened
+	mu              sync.Mutex    // Mutex for updating stats
+	clock           clock.Clock
+
+	latency  *Histogram            // Time spent per item
+	window   *rollingWindow        // Items of the last hour, per minute
+	bySymbol map[string]*itemStats // Items per symbol
+	bySource map[string]*itemStats // Items per source
+}
+
+// ItemLabels identify what an item was about, for the per-symbol and
+// per-source breakdowns. Empty labels are left out of the breakdowns.
+type ItemLabels struct {
+	Symbol string
+	Source string
+}
+
+// ItemStats describes the items processed in a breakdown or time window
+type ItemStats struct {
+	Processed   int64             `json:"processed"`
+	Failed      int64             `json:"failed"`
+	FailureRate float64           `json:"failure_rate"`
+	Latency     HistogramSnapshot `json:"latency"`
+}
+
+// StatsDetail holds the latency distribution, rolling windows and breakdowns
+// of one worker or of a whole pool
+type StatsDetail struct {
+	Latency  HistogramSnapshot    `json:"latency"`
+	Last5m   ItemStats            `json:"last_5m"`
+	LastHour ItemStats            `json:"last_hour"`
+	BySymbol map[string]ItemStats `json:"by_symbol,omitempty"`
+	BySource map[string]ItemStats `json:"by_source,omitempty"`
+}
+
+// NewStats creates a new Stats instance telling the time with a clock
+func NewStats(c clock.Clock) *Stats {
 	return &Stats{
+		clock:           c,
 		ItemsProcessed:  0,
 		ItemsSuccessful: 0,
 		ItemsFailed:     0,
 		ProcessingTime:  0,
 		IsRunning:       false,
+		latency:         NewHistogram(),
+		window:          &rollingWindow{},
+		bySymbol:        make(map[string]*itemStats),
+		bySource:        make(map[string]*itemStats),
 	}
 }
 
-// RecordStart marks the worker as started
+// RecordStart marks the worker as started. Calls while running are ignored.
 func (s *Stats) RecordStart() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.StartTime = time.Now()
+	if s.IsRunning {
+		return
+	}
+	s.StartTime = s.clock.Now()
 	s.IsRunning = true
 }
 
-// RecordStop marks the worker as stopped
+// RecordStop marks the worker as stopped and adds the run to its runtime
 func (s *Stats) RecordStop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.StopTime = time.Now()
+	if !s.IsRunning {
+		return
+	}
+	s.StopTime = s.clock.Now()
+	s.Runtime += s.StopTime.Sub(s.StartTime)
 	s.IsRunning = false
 }
 
-// RecordItemProcessed records a successful item processing
-func (s *Stats) RecordItemProcessed() {
-	atomic.AddInt64(&s.ItemsProcessed, 1)
+// RecordItemProcessed records an item processed successfully in d
+func (s *Stats) RecordItemProcessed(labels ItemLabels, d time.Duration) {
 	atomic.AddInt64(&s.ItemsSuccessful, 1)
-	atomic.AddInt64(&s.ProcessingTime, int64(time.Since(s.StartTime)))
+	s.recordItem(labels, d, false)
+}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.LastProcessedAt = time.Now()
+// RecordItemFailed records an item that failed after d
+func (s *Stats) RecordItemFailed(labels ItemLabels, d time.Duration) {
+	atomic.AddInt64(&s.ItemsFailed, 1)
+	s.recordItem(labels, d, true)
 }
 
-// RecordItemFailed records a failed item processing
-func (s *Stats) RecordItemFailed() {
+// RecordError records an item that failed before it could be processed, such
+// as a queue or decode error. It counts as a failed item but adds no latency,
+// which would skew the histograms and rolling windows towards zero.
+func (s *Stats) RecordError() {
 	atomic.AddInt64(&s.ItemsProcessed, 1)
 	atomic.AddInt64(&s.ItemsFailed, 1)
-	atomic.AddInt64(&s.ProcessingTime, int64(time.Since(s.StartTime)))
+	atomic.AddInt64(&s.Errors, 1)
+}
+
+// recordItem updates the counters, histograms and breakdowns for an item
+func (s *Stats) recordItem(labels ItemLabels, d time.Duration, failed bool) {
+	atomic.AddInt64(&s.ItemsProcessed, 1)
+	atomic.AddInt64(&s.ProcessingTime, int64(d))
+
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.LastProcessedAt = now
+	s.latency.Observe(d)
+	s.window.record(now, d, failed)
+	if labels.Symbol != "" {
+		breakdown(s.bySymbol, labels.Symbol).record(d, failed)
+	}
+	if labels.Source != "" {
+		breakdown(s.bySource, labels.Source).record(d, failed)
+	}
+}
+
+// RecordPanic records a panic recovered from the worker
+func (s *Stats) RecordPanic(value interface{}) {
+	atomic.AddInt64(&s.Panics, 1)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.LastProcessedAt = time.Now()
+	s.LastPanic = fmt.Sprintf("%v", value)
+	s.LastPanicAt = s.clock.Now()
+}
+
+// RecordRestart records a restart performed by the supervisor
+func (s *Stats) RecordRestart() {
+	atomic.AddInt64(&s.Restarts, 1)
+}
+
+// RecordRequeue records an interrupted task being put back on its queue
+func (s *Stats) RecordRequeue() {
+	atomic.AddInt64(&s.Requeued, 1)
+}
+
+// RecordRetry records a failed task being put back on its queue for another attempt
+func (s *Stats) RecordRetry() {
+	atomic.AddInt64(&s.Retried, 1)
 }
 
-// GetSnapshot returns a copy of the current stats
+// GetSnapshot returns a copy of the current counters
 func (s *Stats) GetSnapshot() Stats {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -79,6 +182,11 @@ func (s *Stats) GetSnapshot() Stats {
 	itemsSuccessful := atomic.LoadInt64(&s.ItemsSuccessful)
 	itemsFailed := atomic.LoadInt64(&s.ItemsFailed)
 	processingTime := atomic.LoadInt64(&s.ProcessingTime)
+	panics := atomic.LoadInt64(&s.Panics)
+	restarts := atomic.LoadInt64(&s.Restarts)
+	requeued := atomic.LoadInt64(&s.Requeued)
+	retried := atomic.LoadInt64(&s.Retried)
+	errorCount := atomic.LoadInt64(&s.Errors)
 
 	return Stats{
 		ItemsProcessed:  itemsProcessed,
@@ -88,22 +196,221 @@ func (s *Stats) GetSnapshot() Stats {
 		ProcessingTime:  processingTime,
 		StartTime:       s.StartTime,
 		StopTime:        s.StopTime,
+		Runtime:         s.Runtime,
 		IsRunning:       s.IsRunning,
+		Panics:          panics,
+		Restarts:        restarts,
+		Requeued:        requeued,
+		Retried:         retried,
+		Errors:          errorCount,
+		LastPanic:       s.LastPanic,
+		LastPanicAt:     s.LastPanicAt,
 	}
 }
 
-// GetTotalRuntime returns the total runtime of the worker
+// GetTotalRuntime returns the time the worker spent running, across runs
 func (s *Stats) GetTotalRuntime() time.Duration {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.IsRunning {
-		return time.Since(s.StartTime)
+		return s.Runtime + s.clock.Since(s.StartTime)
 	}
+	return s.Runtime
+}
+
+// Latency returns the distribution of the time spent per item
+func (s *Stats) Latency() HistogramSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.latency.Snapshot(true)
+}
+
+// Detail returns the worker's latency distribution, rolling windows and breakdowns
+func (s *Stats) Detail() StatsDetail {
+	agg := newStatsAggregate()
+	s.addTo(agg, s.clock.Now())
+	return agg.detail()
+}
+
+// addTo merges the worker's histograms and breakdowns into an aggregate
+func (s *Stats) addTo(agg *statsAggregate, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if s.StopTime.IsZero() {
-		return 0
+	agg.latency.Merge(s.latency)
+	s.window.collect(now, 5*time.Minute, agg.last5m)
+	s.window.collect(now, time.Hour, agg.lastHour)
+	for symbol, stats := range s.bySymbol {
+		breakdown(agg.bySymbol, symbol).merge(stats)
 	}
+	for source, stats := range s.bySource {
+		breakdown(agg.bySource, source).merge(stats)
+	}
+}
+
+// absorb adds the counters, runtime, histograms and breakdowns of another
+// worker's stats, so a pool keeps them once the worker is gone
+func (s *Stats) absorb(other *Stats) {
+	snapshot := other.GetSnapshot()
+	runtime := other.GetTotalRuntime()
+
+	atomic.AddInt64(&s.ItemsProcessed, snapshot.ItemsProcessed)
+	atomic.AddInt64(&s.ItemsSuccessful, snapshot.ItemsSuccessful)
+	atomic.AddInt64(&s.ItemsFailed, snapshot.ItemsFailed)
+	atomic.AddInt64(&s.ProcessingTime, snapshot.ProcessingTime)
+	atomic.AddInt64(&s.Panics, snapshot.Panics)
+	atomic.AddInt64(&s.Restarts, snapshot.Restarts)
+	atomic.AddInt64(&s.Requeued, snapshot.Requeued)
+	atomic.AddInt64(&s.Retried, snapshot.Retried)
+	atomic.AddInt64(&s.Errors, snapshot.Errors)
+
+	other.mu.Lock()
+	defer other.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Runtime += runtime
+	if other.LastProcessedAt.After(s.LastProcessedAt) {
+		s.LastProcessedAt = other.LastProcessedAt
+	}
+	s.latency.Merge(other.latency)
+	s.window.merge(other.window)
+	for symbol, stats := range other.bySymbol {
+		breakdown(s.bySymbol, symbol).merge(stats)
+	}
+	for source, stats := range other.bySource {
+		breakdown(s.bySource, source).merge(stats)
+	}
+}
 
-	return s.StopTime.Sub(s.StartTime)
+// itemStats counts items and their latency
+type itemStats struct {
+	processed int64
+	failed    int64
+	latency   *Histogram
+}
+
+func newItemStats() *itemStats {
+	return &itemStats{latency: NewHistogram()}
+}
+
+func (c *itemStats) record(d time.Duration, failed bool) {
+	c.processed++
+	if failed {
+		c.failed++
+	}
+	c.latency.Observe(d)
+}
+
+func (c *itemStats) merge(other *itemStats) {
+	c.processed += other.processed
+	c.failed += other.failed
+	c.latency.Merge(other.latency)
+}
+
+func (c *itemStats) snapshot() ItemStats {
+	stats := ItemStats{
+		Processed: c.processed,
+		Failed:    c.failed,
+		Latency:   c.latency.Snapshot(false),
+	}
+	if c.processed > 0 {
+		stats.FailureRate = float64(c.failed) / float64(c.processed)
+	}
+	return stats
+}
+
+// breakdown returns the entry of a breakdown, creating it if needed
+func breakdown(m map[string]*itemStats, key string) *itemStats {
+	stats, ok := m[key]
+	if !ok {
+		stats = newItemStats()
+		m[key] = stats
+	}
+	return stats
+}
+
+// rollingWindow keeps item stats per minute for the last windowSlots minutes
+type rollingWindow struct {
+	slots   [windowSlots]*itemStats
+	minutes [windowSlots]int64 // Unix minute each slot holds
+}
+
+// record adds an item to the slot of the current minute, recycling the slot
+// if it still holds an older minute
+func (w *rollingWindow) record(now time.Time, d time.Duration, failed bool) {
+	minute := now.Unix() / 60
+	i := minute % windowSlots
+	if w.slots[i] == nil || w.minutes[i] != minute {
+		w.slots[i] = newItemStats()
+		w.minutes[i] = minute
+	}
+	w.slots[i].record(d, failed)
+}
+
+// merge adds the slots of another window, keeping the most recent minute
+// when two slots hold different ones
+func (w *rollingWindow) merge(other *rollingWindow) {
+	for i, slot := range other.slots {
+		if slot == nil {
+			continue
+		}
+		if w.slots[i] == nil || w.minutes[i] < other.minutes[i] {
+			w.slots[i] = newItemStats()
+			w.minutes[i] = other.minutes[i]
+		}
+		if w.minutes[i] == other.minutes[i] {
+			w.slots[i].merge(slot)
+		}
+	}
+}
+
+// collect merges the slots of the last d, including the current minute
+func (w *rollingWindow) collect(now time.Time, d time.Duration, into *itemStats) {
+	minute := now.Unix() / 60
+	span := int64(d / time.Minute)
+	for i, slot := range w.slots {
+		if slot != nil && minute-w.minutes[i] < span {
+			into.merge(slot)
+		}
+	}
+}
+
+// statsAggregate merges the detailed stats of several workers
+type statsAggregate struct {
+	latency  *Histogram
+	last5m   *itemStats
+	lastHour *itemStats
+	bySymbol map[string]*itemStats
+	bySource map[string]*itemStats
+}
+
+func newStatsAggregate() *statsAggregate {
+	return &statsAggregate{
+		latency:  NewHistogram(),
+		last5m:   newItemStats(),
+		lastHour: newItemStats(),
+		bySymbol: make(map[string]*itemStats),
+		bySource: make(map[string]*itemStats),
+	}
+}
+
+// detail summarises the aggregate
+func (agg *statsAggregate) detail() StatsDetail {
+	detail := StatsDetail{
+		Latency:  agg.latency.Snapshot(true),
+		Last5m:   agg.last5m.snapshot(),
+		LastHour: agg.lastHour.snapshot(),
+		BySymbol: make(map[string]ItemStats, len(agg.bySymbol)),
+		BySource: make(map[string]ItemStats, len(agg.bySource)),
+	}
+	for symbol, stats := range agg.bySymbol {
+		detail.BySymbol[symbol] = stats.snapshot()
+	}
+	for source, stats := range agg.bySource {
+		detail.BySource[source] = stats.snapshot()
+	}
+	return detail
 }
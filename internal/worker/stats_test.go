@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+)
+
+func TestRollingWindow(t *testing.T) {
+	start := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	w := &rollingWindow{}
+
+	w.record(start, time.Second, false)
+	w.record(start.Add(2*time.Minute), time.Second, true)
+	w.record(start.Add(10*time.Minute), time.Second, false)
+
+	now := start.Add(10*time.Minute + 30*time.Second)
+	last5m, lastHour := newItemStats(), newItemStats()
+	w.collect(now, 5*time.Minute, last5m)
+	w.collect(now, time.Hour, lastHour)
+
+	if last5m.processed != 1 || last5m.failed != 0 {
+		t.Errorf("Expected 1 item in the last 5m, got %d (%d failed)", last5m.processed, last5m.failed)
+	}
+	if lastHour.processed != 3 || lastHour.failed != 1 {
+		t.Errorf("Expected 3 items in the last hour, got %d (%d failed)", lastHour.processed, lastHour.failed)
+	}
+
+	// An hour later the first slot is recycled instead of accumulating
+	w.record(start.Add(time.Hour), time.Second, false)
+	recycled := newItemStats()
+	w.collect(start.Add(time.Hour), time.Hour, recycled)
+	if recycled.processed != 3 {
+		t.Errorf("Expected 3 items in the hour after recycling, got %d", recycled.processed)
+	}
+}
+
+func TestRollingWindowMerge(t *testing.T) {
+	start := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+	w := &rollingWindow{}
+	w.record(start, time.Second, false)
+
+	other := &rollingWindow{}
+	other.record(start, time.Second, true)
+	other.record(start.Add(time.Hour), time.Second, false) // Same slot, newer minute
+
+	w.merge(other)
+
+	merged := newItemStats()
+	w.collect(start.Add(time.Hour), time.Hour, merged)
+	if merged.processed != 1 || merged.failed != 0 {
+		t.Errorf("Expected only the newer minute to be kept, got %d (%d failed)", merged.processed, merged.failed)
+	}
+}
+
+func TestRecordErrorSkipsLatency(t *testing.T) {
+	s := NewStats(clock.NewFake(time.Now()))
+	s.RecordItemProcessed(ItemLabels{Symbol: "AAPL"}, time.Second)
+	s.RecordError()
+
+	snapshot := s.GetSnapshot()
+	if snapshot.ItemsProcessed != 2 || snapshot.ItemsFailed != 1 || snapshot.Errors != 1 {
+		t.Errorf("Expected 2 items with 1 failed error, got %d items, %d failed, %d errors",
+			snapshot.ItemsProcessed, snapshot.ItemsFailed, snapshot.Errors)
+	}
+
+	detail := s.Detail()
+	if detail.Latency.Count != 1 || detail.Latency.P50 == 0 {
+		t.Errorf("Expected only the processed item in the latency, got %+v", detail.Latency)
+	}
+	if detail.Last5m.Processed != 1 || detail.Last5m.Failed != 0 {
+		t.Errorf("Expected errors to stay out of the rolling window, got %+v", detail.Last5m)
+	}
+}
@@ -0,0 +1,186 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+)
+
+const (
+	defaultMaxRestarts    = 5
+	defaultRestartWindow  = time.Minute
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// PanicError wraps a panic recovered from a worker
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// CrashLoopError is raised when a worker exceeds its restart budget
+type CrashLoopError struct {
+	Pool     string
+	Worker   string
+	Restarts int
+	Window   time.Duration
+	LastErr  error
+}
+
+func (e *CrashLoopError) Error() string {
+	return fmt.Sprintf("worker %s in pool %s is crash-looping: %d restarts within %s, last error: %v",
+		e.Worker, e.Pool, e.Restarts, e.Window, e.LastErr)
+}
+
+func (e *CrashLoopError) Unwrap() error {
+	return e.LastErr
+}
+
+// Supervisor runs workers, recovers their panics and restarts them according
+// to a restart policy. Workers restarted too often within the window are
+// given up on and reported through the escalation callback.
+type Supervisor struct {
+	pool       string
+	cfg        config.RestartConfig
+	onEscalate func(err *CrashLoopError)
+	completes  bool // Workers finish on their own, so a clean exit is final
+	clock      clock.Clock
+}
+
+// NewSupervisor creates a supervisor for a pool
+func NewSupervisor(pool string, cfg config.RestartConfig, onEscalate func(err *CrashLoopError)) *Supervisor {
+	if cfg.Policy == "" {
+		cfg.Policy = constants.RestartOnFailure
+	}
+	if cfg.MaxRestarts <= 0 {
+		cfg.MaxRestarts = defaultMaxRestarts
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultRestartWindow
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	return &Supervisor{
+		pool:       pool,
+		cfg:        cfg,
+		onEscalate: onEscalate,
+		clock:      clock.New(),
+	}
+}
+
+// Run keeps a worker running until the context is cancelled, stopped reports
+// true, the restart policy says otherwise, or the worker is crash-looping
+func (s *Supervisor) Run(ctx context.Context, w Worker, stopped func() bool) {
+	var restarts []time.Time
+
+	for {
+		err := s.runOnce(ctx, w)
+
+		if ctx.Err() != nil || stopped() || !s.shouldRestart(err) {
+			if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+				log.Printf("Worker %s error: %v", w.Name(), err)
+			}
+			return
+		}
+
+		// Only restarts within the window count towards the budget
+		now := s.clock.Now()
+		recent := restarts[:0]
+		for _, at := range restarts {
+			if now.Sub(at) < s.cfg.Window {
+				recent = append(recent, at)
+			}
+		}
+		restarts = recent
+
+		if len(restarts) >= s.cfg.MaxRestarts {
+			crashErr := &CrashLoopError{
+				Pool:     s.pool,
+				Worker:   w.Name(),
+				Restarts: len(restarts),
+				Window:   s.cfg.Window,
+				LastErr:  err,
+			}
+			log.Printf("Giving up on worker %s: %v", w.Name(), crashErr)
+			if s.onEscalate != nil {
+				s.onEscalate(crashErr)
+			}
+			return
+		}
+
+		backoff := s.backoff(len(restarts))
+		log.Printf("Restarting worker %s in %s after: %v", w.Name(), backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(backoff):
+		}
+
+		if stopped() {
+			return
+		}
+
+		// Reset the worker's running flag, left set by a crashed Start
+		if stopErr := w.Stop(); stopErr != nil {
+			log.Printf("Error resetting worker %s: %v", w.Name(), stopErr)
+		}
+		restarts = append(restarts, s.clock.Now())
+		w.GetStats().RecordRestart()
+	}
+}
+
+// runOnce runs the worker, converting a panic into a PanicError
+func (s *Supervisor) runOnce(ctx context.Context, w Worker) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.GetStats().RecordPanic(r)
+			panicErr := &PanicError{Value: r, Stack: debug.Stack()}
+			log.Printf("Recovered panic in worker %s: %v\n%s", w.Name(), r, panicErr.Stack)
+			err = panicErr
+		}
+	}()
+
+	return w.Start(ctx)
+}
+
+// shouldRestart applies the restart policy to the outcome of a run
+func (s *Supervisor) shouldRestart(err error) bool {
+	switch s.cfg.Policy {
+	case constants.RestartAlways:
+		return err != nil || !s.completes
+	case constants.RestartNever:
+		return false
+	default:
+		return err != nil
+	}
+}
+
+// backoff returns the delay before the next restart
+func (s *Supervisor) backoff(restarts int) time.Duration {
+	backoff := s.cfg.InitialBackoff
+	for i := 0; i < restarts && backoff < s.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.cfg.MaxBackoff {
+		backoff = s.cfg.MaxBackoff
+	}
+	return backoff
+}
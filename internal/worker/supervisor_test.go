@@ -0,0 +1,166 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+)
+
+// newTestSupervisor creates a supervisor on a fake clock, delivering its
+// escalations on a channel
+func newTestSupervisor(cfg config.RestartConfig) (*Supervisor, *clock.Fake, chan *CrashLoopError) {
+	fake := clock.NewFake(testStart)
+	escalations := make(chan *CrashLoopError, 1)
+	s := NewSupervisor("test", cfg, func(err *CrashLoopError) { escalations <- err })
+	s.clock = fake
+	return s, fake, escalations
+}
+
+// supervise runs a worker under a supervisor in the background, returning a
+// channel closed once the supervisor gives up
+func supervise(s *Supervisor, w Worker) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(context.Background(), w, func() bool { return false })
+	}()
+	return done
+}
+
+func TestSupervisorBackoff(t *testing.T) {
+	s := NewSupervisor("test", config.RestartConfig{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}, nil)
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for restarts, want := range expected {
+		if got := s.backoff(restarts); got != want {
+			t.Errorf("Expected backoff %s after %d restarts, got %s", want, restarts, got)
+		}
+	}
+}
+
+func TestSupervisorRestartsWithBackoffUntilCrashLooping(t *testing.T) {
+	s, fake, escalations := newTestSupervisor(config.RestartConfig{
+		MaxRestarts:    3,
+		Window:         time.Minute,
+		InitialBackoff: time.Second,
+		MaxBackoff:     3 * time.Second,
+	})
+	failure := errors.New("boom")
+	w := newTestWorker(1, fake, func(context.Context, *testWorker) error { return failure })
+	done := supervise(s, w)
+
+	for i, backoff := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		fake.BlockUntil(1)
+
+		// Not restarted before the backoff elapsed
+		fake.Advance(backoff - time.Nanosecond)
+		time.Sleep(5 * time.Millisecond)
+		if starts := w.startCount(); starts != i+1 {
+			t.Fatalf("Expected %d starts before the backoff elapsed, got %d", i+1, starts)
+		}
+
+		fake.Advance(time.Nanosecond)
+		waitFor(t, "restart", func() bool { return w.startCount() == i+2 })
+	}
+
+	<-done
+	select {
+	case err := <-escalations:
+		if err.Restarts != 3 || !errors.Is(err, failure) {
+			t.Errorf("Expected a crash loop after 3 restarts wrapping the failure, got %v", err)
+		}
+	default:
+		t.Fatalf("Expected the crash loop to be escalated")
+	}
+	if restarts := w.Stats.GetSnapshot().Restarts; restarts != 3 {
+		t.Errorf("Expected 3 restarts recorded, got %d", restarts)
+	}
+}
+
+func TestSupervisorForgetsRestartsOutsideWindow(t *testing.T) {
+	s, fake, escalations := newTestSupervisor(config.RestartConfig{
+		MaxRestarts:    1,
+		Window:         time.Minute,
+		InitialBackoff: time.Second,
+	})
+
+	// Every run lasts until the test lets it fail
+	fail := make(chan struct{})
+	w := newTestWorker(1, fake, func(context.Context, *testWorker) error {
+		<-fail
+		return errors.New("boom")
+	})
+	done := supervise(s, w)
+
+	fail <- struct{}{}
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	waitFor(t, "restart", func() bool { return w.startCount() == 2 })
+
+	// The first restart is out of the window by the time the worker fails again
+	fake.Advance(time.Minute)
+	fail <- struct{}{}
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	waitFor(t, "second restart", func() bool { return w.startCount() == 3 })
+
+	// A failure right after a restart exceeds the budget
+	fail <- struct{}{}
+	<-done
+	if len(escalations) != 1 {
+		t.Errorf("Expected the crash loop to be escalated")
+	}
+}
+
+func TestSupervisorRecoversPanics(t *testing.T) {
+	s, fake, _ := newTestSupervisor(config.RestartConfig{})
+	w := newTestWorker(1, fake, func(ctx context.Context, w *testWorker) error {
+		if w.startCount() == 1 {
+			panic("nil map")
+		}
+		return nil
+	})
+	done := supervise(s, w)
+
+	fake.BlockUntil(1)
+	fake.Advance(defaultInitialBackoff)
+	<-done
+
+	stats := w.Stats.GetSnapshot()
+	if w.startCount() != 2 || stats.Panics != 1 || stats.LastPanic != "nil map" {
+		t.Errorf("Expected one panic and a clean second run, got %d starts and %d panics (%q)",
+			w.startCount(), stats.Panics, stats.LastPanic)
+	}
+}
+
+func TestSupervisorRestartPolicy(t *testing.T) {
+	failure := errors.New("boom")
+
+	tests := []struct {
+		policy    string
+		completes bool
+		err       error
+		restart   bool
+	}{
+		{constants.RestartOnFailure, false, failure, true},
+		{constants.RestartOnFailure, false, nil, false},
+		{constants.RestartAlways, false, nil, true},
+		{constants.RestartAlways, true, nil, false},
+		{constants.RestartAlways, true, failure, true},
+		{constants.RestartNever, false, failure, false},
+	}
+
+	for _, tt := range tests {
+		s := NewSupervisor("test", config.RestartConfig{Policy: tt.policy}, nil)
+		s.completes = tt.completes
+		if got := s.shouldRestart(tt.err); got != tt.restart {
+			t.Errorf("Expected restart %v for policy %s (completes %v) after %v, got %v",
+				tt.restart, tt.policy, tt.completes, tt.err, got)
+		}
+	}
+}
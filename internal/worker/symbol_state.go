@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/model"
+)
+
+const (
+	// yieldSmoothing is the weight of the latest scrape in the article yield average
+	yieldSmoothing = 0.3
+
+	// maxRecentURLs bounds the URLs remembered per symbol to detect new articles
+	maxRecentURLs = 50
+)
+
+// recordScrape updates a symbol's state with the outcome of a scrape
+func recordScrape(state *model.SymbolState, articles []model.ArticleData, err error, now time.Time) {
+	state.LastScrapedAt = now
+	state.TotalScrapes++
+
+	if err != nil {
+		state.ConsecutiveFailures++
+		return
+	}
+
+	seen := make(map[string]bool, len(state.RecentURLs))
+	for _, url := range state.RecentURLs {
+		seen[url] = true
+	}
+
+	// Pages list the latest articles first, so keep them at the front
+	fresh := make([]string, 0, len(articles))
+	for _, article := range articles {
+		if article.URL == "" || seen[article.URL] {
+			continue
+		}
+		seen[article.URL] = true
+		fresh = append(fresh, article.URL)
+	}
+
+	recent := append(fresh, state.RecentURLs...)
+	if len(recent) > maxRecentURLs {
+		recent = recent[:maxRecentURLs]
+	}
+
+	if state.LastSuccessAt.IsZero() {
+		state.ArticleYield = float64(len(fresh))
+	} else {
+		state.ArticleYield = yieldSmoothing*float64(len(fresh)) + (1-yieldSmoothing)*state.ArticleYield
+	}
+
+	state.LastSuccessAt = now
+	state.ConsecutiveFailures = 0
+	state.LastYield = len(fresh)
+	state.TotalArticles += int64(len(fresh))
+	state.RecentURLs = recent
+}
@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/model"
+)
+
+func articlesAt(urls ...string) []model.ArticleData {
+	articles := make([]model.ArticleData, len(urls))
+	for i, url := range urls {
+		articles[i] = model.ArticleData{URL: url}
+	}
+	return articles
+}
+
+func TestRecordScrape(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		state     model.SymbolState
+		articles  []model.ArticleData
+		err       error
+		failures  int
+		lastYield int
+		yield     float64
+		recent    []string
+		success   time.Time
+	}{
+		{
+			name:      "first success takes the yield as is",
+			articles:  articlesAt("a", "b", "a", ""),
+			lastYield: 2,
+			yield:     2,
+			recent:    []string{"a", "b"},
+			success:   now,
+		},
+		{
+			name:      "later success smooths the yield",
+			state:     model.SymbolState{LastSuccessAt: earlier, ArticleYield: 1, ConsecutiveFailures: 3, RecentURLs: []string{"a"}},
+			articles:  articlesAt("c", "b", "a"),
+			lastYield: 2,
+			yield:     yieldSmoothing*2 + (1-yieldSmoothing)*1,
+			recent:    []string{"c", "b", "a"},
+			success:   now,
+		},
+		{
+			name:      "failure extends the streak and keeps the rest",
+			state:     model.SymbolState{LastSuccessAt: earlier, ArticleYield: 1, ConsecutiveFailures: 1, LastYield: 4, RecentURLs: []string{"a"}},
+			err:       errors.New("status 500"),
+			failures:  2,
+			lastYield: 4,
+			yield:     1,
+			recent:    []string{"a"},
+			success:   earlier,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			recordScrape(&state, tt.articles, tt.err, now)
+
+			if !state.LastScrapedAt.Equal(now) {
+				t.Errorf("Expected last scrape at %v, got %v", now, state.LastScrapedAt)
+			}
+			if state.TotalScrapes != tt.state.TotalScrapes+1 {
+				t.Errorf("Expected %d scrapes, got %d", tt.state.TotalScrapes+1, state.TotalScrapes)
+			}
+			if state.ConsecutiveFailures != tt.failures {
+				t.Errorf("Expected %d consecutive failures, got %d", tt.failures, state.ConsecutiveFailures)
+			}
+			if state.LastYield != tt.lastYield {
+				t.Errorf("Expected last yield %d, got %d", tt.lastYield, state.LastYield)
+			}
+			if diff := state.ArticleYield - tt.yield; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Expected article yield %f, got %f", tt.yield, state.ArticleYield)
+			}
+			if !state.LastSuccessAt.Equal(tt.success) {
+				t.Errorf("Expected last success at %v, got %v", tt.success, state.LastSuccessAt)
+			}
+			if len(state.RecentURLs) != len(tt.recent) {
+				t.Fatalf("Expected recent URLs %v, got %v", tt.recent, state.RecentURLs)
+			}
+			for i, url := range tt.recent {
+				if state.RecentURLs[i] != url {
+					t.Errorf("Expected recent URLs %v, got %v", tt.recent, state.RecentURLs)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRecordScrapeBoundsRecentURLs(t *testing.T) {
+	state := model.SymbolState{}
+	for i := 0; i < maxRecentURLs+10; i++ {
+		recordScrape(&state, articlesAt(time.Duration(i).String()), nil, time.Now())
+	}
+
+	if len(state.RecentURLs) != maxRecentURLs {
+		t.Errorf("Expected %d recent URLs, got %d", maxRecentURLs, len(state.RecentURLs))
+	}
+	if state.TotalArticles != maxRecentURLs+10 {
+		t.Errorf("Expected %d articles, got %d", maxRecentURLs+10, state.TotalArticles)
+	}
+}
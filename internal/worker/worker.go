@@ -3,6 +3,8 @@ package worker
 import (
 	"context"
 	"sync/atomic"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
 )
 
 const (
@@ -17,6 +19,7 @@ type BaseWorker struct {
 	typeOfWorker string // Type of worker (e.g., "scraper", "consumer")
 	active       int32  // Atomic flag for tracking active state
 	Stats        *Stats // Performance statistics
+	clock        clock.Clock
 }
 
 // Worker represents a generic worker that can process tasks
@@ -29,6 +32,9 @@ type Worker interface {
 
 	// Name returns the worker's name
 	Name() string
+
+	// GetStats returns the worker's performance statistics
+	GetStats() *Stats
 }
 
 // Stop gracefully stops the worker by setting the Active flag to 0
@@ -43,6 +49,11 @@ func (w *BaseWorker) Name() string {
 	return w.workerName
 }
 
+// GetStats returns the worker's performance statistics
+func (w *BaseWorker) GetStats() *Stats {
+	return w.Stats
+}
+
 // IsActive checks if the worker is currently active
 func (w *BaseWorker) IsActive() bool {
 	return atomic.LoadInt32(&w.active) == 1
@@ -57,13 +68,15 @@ func (w *BaseWorker) SetActive(active bool) bool {
 	return atomic.CompareAndSwapInt32(&w.active, 1, 0)
 }
 
-// NewBaseWorker creates a new base worker with the given parameters
-func NewBaseWorker(id int, name, workerType string) BaseWorker {
+// NewBaseWorker creates a new base worker with the given parameters, telling
+// the time and waiting on a clock
+func NewBaseWorker(id int, name, workerType string, c clock.Clock) BaseWorker {
 	return BaseWorker{
 		ID:           id,
 		workerName:   name,
 		typeOfWorker: workerType,
 		active:       0,
-		Stats:        NewStats(),
+		Stats:        NewStats(c),
+		clock:        c,
 	}
 }
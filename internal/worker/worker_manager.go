@@ -1,16 +1,39 @@
 package worker
 
 import (
+	"context"
+	"log"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/guillermoballester/propagatorGo/internal/clock"
 	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/model"
+)
+
+const (
+	// stateSaveTimeout bounds persisting a symbol's state after a scrape
+	stateSaveTimeout = 5 * time.Second
+
+	// claimTimeout releases a symbol whose scrape never reported back, e.g. after a panic
+	claimTimeout = 10 * time.Minute
 )
 
 // WorkManager manages the distribution of stocks to workers
 type WorkManager struct {
-	stocks     []config.Stock // List of stocks to process
-	currentIdx int            // Current position in the stocks list
-	mu         sync.Mutex     // To make operations thread-safe
+	stocks        []config.Stock                // List of stocks to process
+	currentIdx    int                           // Current position in the stocks list
+	passRemaining int                           // Stocks left to hand out in the current pass
+	source        string                        // Source the stocks are scraped from
+	states        map[string]*model.SymbolState // Scrape state per symbol
+	store         StateStore                    // Persists states, when set
+	policy        SchedulingPolicy              // Chooses the next stock
+	claimed       map[string]time.Time          // Symbols being scraped, so they are not handed out twice
+	passClaimed   map[string]bool               // Claimed symbols handed out by the current pass
+	retry         []string                      // Released symbols, handed out again before any other
+	mu            sync.Mutex                    // To make operations thread-safe
+	clock         clock.Clock
 }
 
 // NewWorkManager creates a new work manager from a stock list
@@ -23,26 +46,259 @@ func NewWorkManager(stockList []config.Stock) *WorkManager {
 	}
 
 	return &WorkManager{
-		stocks:     enabledStocks,
-		currentIdx: 0,
+		stocks:      enabledStocks,
+		currentIdx:  0,
+		states:      make(map[string]*model.SymbolState),
+		policy:      RoundRobinPolicy{},
+		claimed:     make(map[string]time.Time),
+		passClaimed: make(map[string]bool),
+		clock:       clock.New(),
 	}
 }
 
-// GetNextStock returns the next stock to process, cycling through the list
+// SetPolicy changes how the next stock is chosen
+func (wm *WorkManager) SetPolicy(policy SchedulingPolicy) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	wm.policy = policy
+}
+
+// GetNextStock returns the next stock to process as chosen by the scheduling
+// policy, or nil when no stock is due
 func (wm *WorkManager) GetNextStock() *config.Stock {
 	wm.mu.Lock()
 	defer wm.mu.Unlock()
 
+	return wm.pick(wm.clock.Now())
+}
+
+// BeginPass starts a pass over every enabled stock, continuing from the
+// current position so consecutive passes keep the round-robin order
+func (wm *WorkManager) BeginPass() {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	wm.passRemaining = len(wm.stocks)
+	wm.passClaimed = make(map[string]bool)
+}
+
+// NextInPass returns the next stock of the current pass, or nil once every
+// stock has been handed out or none is due
+func (wm *WorkManager) NextInPass() *config.Stock {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	if wm.passRemaining <= 0 {
+		return nil
+	}
+
+	// The pass ends early once no stock is due
+	stock := wm.pick(wm.clock.Now())
+	if stock == nil {
+		wm.passRemaining = 0
+		return nil
+	}
+	wm.passRemaining--
+	wm.passClaimed[stock.Symbol] = true
+
+	return stock
+}
+
+// pick asks the policy for the next stock among those not being scraped and
+// claims it; callers must hold wm.mu
+func (wm *WorkManager) pick(now time.Time) *config.Stock {
 	if len(wm.stocks) == 0 {
 		return nil
 	}
 
-	stock := wm.stocks[wm.currentIdx]
-	wm.currentIdx = (wm.currentIdx + 1) % len(wm.stocks)
+	candidates := make([]Candidate, 0, len(wm.stocks))
+	for i, stock := range wm.stocks {
+		if claimedAt, ok := wm.claimed[stock.Symbol]; ok && now.Sub(claimedAt) < claimTimeout {
+			continue
+		}
+
+		candidate := Candidate{Index: i, Stock: stock}
+		if state, ok := wm.states[stock.Symbol]; ok {
+			candidate.State = *state
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	// Released symbols go first, without moving the position
+	for i, symbol := range wm.retry {
+		for _, c := range candidates {
+			if c.Stock.Symbol == symbol {
+				wm.retry = append(wm.retry[:i], wm.retry[i+1:]...)
+				wm.claimed[symbol] = now
+				stock := c.Stock
+				return &stock
+			}
+		}
+	}
+
+	idx, ok := wm.policy.Pick(candidates, wm.currentIdx, now)
+	if !ok {
+		return nil
+	}
+
+	stock := wm.stocks[idx]
+	wm.currentIdx = (idx + 1) % len(wm.stocks)
+	wm.claimed[stock.Symbol] = now
 
 	return &stock
 }
 
+// Position returns the symbol that will be handed out next, or an empty
+// string when there are no stocks
+func (wm *WorkManager) Position() string {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	if len(wm.stocks) == 0 {
+		return ""
+	}
+	return wm.stocks[wm.currentIdx].Symbol
+}
+
+// ResumeFrom moves the current position to a symbol, typically one saved by a
+// previous run. Returns false if the symbol is no longer in the list.
+func (wm *WorkManager) ResumeFrom(symbol string) bool {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	for i, stock := range wm.stocks {
+		if stock.Symbol == symbol {
+			wm.currentIdx = i
+			return true
+		}
+	}
+	return false
+}
+
+// RecordScrape updates a symbol's state with the outcome of a scrape and
+// persists it, along with the current position, when a store is attached.
+// Returns the updated state.
+func (wm *WorkManager) RecordScrape(symbol string, articles []model.ArticleData, err error) model.SymbolState {
+	wm.mu.Lock()
+	state, ok := wm.states[symbol]
+	if !ok {
+		state = &model.SymbolState{Symbol: symbol, Source: wm.source}
+		wm.states[symbol] = state
+	}
+	recordScrape(state, articles, err, wm.clock.Now())
+	delete(wm.claimed, symbol)
+	delete(wm.passClaimed, symbol)
+	snapshot := copySymbolState(state)
+	store := wm.store
+	wm.mu.Unlock()
+
+	if store != nil {
+		// Saved along with the cursor, so a crash loses at most the scrapes in flight
+		ctx, cancel := context.WithTimeout(context.Background(), stateSaveTimeout)
+		defer cancel()
+		if saveErr := store.SaveSymbolState(ctx, snapshot); saveErr != nil {
+			log.Printf("Error saving state of %s: %v", symbol, saveErr)
+		}
+		if position := wm.Position(); position != "" {
+			if saveErr := store.SaveCursor(ctx, wm.source, position); saveErr != nil {
+				log.Printf("Error saving cursor for source %s: %v", wm.source, saveErr)
+			}
+		}
+	}
+
+	return snapshot
+}
+
+// Release gives back a symbol that was handed out but not scraped. It is
+// handed out again before any other symbol, and within its pass if it was
+// handed out by one.
+func (wm *WorkManager) Release(symbol string) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	if _, ok := wm.claimed[symbol]; !ok {
+		return
+	}
+	delete(wm.claimed, symbol)
+	wm.retry = append(wm.retry, symbol)
+
+	if wm.passClaimed[symbol] {
+		delete(wm.passClaimed, symbol)
+		wm.passRemaining++
+	}
+}
+
+// SymbolStates returns the scrape state of every enabled stock, including
+// those never scraped, in stock list order
+func (wm *WorkManager) SymbolStates() []model.SymbolState {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	states := make([]model.SymbolState, 0, len(wm.stocks))
+	for _, stock := range wm.stocks {
+		if state, ok := wm.states[stock.Symbol]; ok {
+			states = append(states, copySymbolState(state))
+			continue
+		}
+		states = append(states, model.SymbolState{Symbol: stock.Symbol, Source: wm.source})
+	}
+	return states
+}
+
+// restoreStates loads saved symbol states and positions the round-robin on
+// the saved cursor, or on the symbol that went longest without a scrape
+func (wm *WorkManager) restoreStates(states []model.SymbolState, cursor string) {
+	wm.mu.Lock()
+	for i := range states {
+		state := states[i]
+		wm.states[state.Symbol] = &state
+	}
+	wm.mu.Unlock()
+
+	if cursor != "" && wm.ResumeFrom(cursor) {
+		log.Printf("Source %s resumes from %s", wm.source, cursor)
+		return
+	}
+
+	if stalest := wm.stalest(); stalest != "" && wm.ResumeFrom(stalest) {
+		log.Printf("Source %s resumes from its stalest symbol %s", wm.source, stalest)
+	}
+}
+
+// stalest returns the enabled symbol scraped least recently, never-scraped
+// symbols first, or an empty string when there are no stocks
+func (wm *WorkManager) stalest() string {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	stocks := make([]config.Stock, len(wm.stocks))
+	copy(stocks, wm.stocks)
+	lastScraped := func(symbol string) time.Time {
+		if state, ok := wm.states[symbol]; ok {
+			return state.LastScrapedAt
+		}
+		return time.Time{}
+	}
+
+	// Stable, so ties keep the stock list order
+	sort.SliceStable(stocks, func(i, j int) bool {
+		return lastScraped(stocks[i].Symbol).Before(lastScraped(stocks[j].Symbol))
+	})
+
+	if len(stocks) == 0 {
+		return ""
+	}
+	return stocks[0].Symbol
+}
+
+// copySymbolState returns a copy that does not share the URL slice
+func copySymbolState(state *model.SymbolState) model.SymbolState {
+	snapshot := *state
+	snapshot.RecentURLs = append([]string(nil), state.RecentURLs...)
+	return snapshot
+}
+
 // GetAllStocks returns all enabled stocks
 func (wm *WorkManager) GetAllStocks() []config.Stock {
 	wm.mu.Lock()
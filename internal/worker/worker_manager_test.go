@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/guillermoballester/propagatorGo/internal/config"
+)
+
+func passSymbols(wm *WorkManager) []string {
+	var symbols []string
+	for stock := wm.NextInPass(); stock != nil; stock = wm.NextInPass() {
+		symbols = append(symbols, stock.Symbol)
+		wm.RecordScrape(stock.Symbol, nil, nil)
+	}
+	return symbols
+}
+
+func TestReleaseKeepsSymbolInPass(t *testing.T) {
+	wm := NewWorkManager([]config.Stock{
+		{Symbol: "AAPL", Enabled: true},
+		{Symbol: "MSFT", Enabled: true},
+		{Symbol: "NVDA", Enabled: true},
+	})
+	wm.BeginPass()
+
+	first := wm.NextInPass()
+	if first == nil || first.Symbol != "AAPL" {
+		t.Fatalf("Expected AAPL first, got %v", first)
+	}
+	wm.Release(first.Symbol)
+
+	// Releasing twice must not give the pass a second slot
+	wm.Release(first.Symbol)
+
+	expected := []string{"AAPL", "MSFT", "NVDA"}
+	got := passSymbols(wm)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected pass %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected pass %v, got %v", expected, got)
+		}
+	}
+
+	// The next pass carries on in order
+	wm.BeginPass()
+	if got := passSymbols(wm); len(got) != 3 || got[0] != "AAPL" {
+		t.Errorf("Expected the next pass to start at AAPL, got %v", got)
+	}
+}
+
+func TestReleaseRetriesBeforeNextSymbol(t *testing.T) {
+	wm := NewWorkManager([]config.Stock{
+		{Symbol: "AAPL", Enabled: true},
+		{Symbol: "MSFT", Enabled: true},
+	})
+
+	first := wm.GetNextStock()
+	second := wm.GetNextStock()
+	wm.Release(first.Symbol)
+	wm.RecordScrape(second.Symbol, nil, nil)
+
+	if next := wm.GetNextStock(); next == nil || next.Symbol != first.Symbol {
+		t.Errorf("Expected released %s next, got %v", first.Symbol, next)
+	}
+	if next := wm.GetNextStock(); next == nil || next.Symbol != second.Symbol {
+		t.Errorf("Expected %s after the retry, got %v", second.Symbol, next)
+	}
+}
@@ -0,0 +1,330 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guillermoballester/propagatorGo/internal/clock"
+	"github.com/guillermoballester/propagatorGo/internal/config"
+	"github.com/guillermoballester/propagatorGo/internal/constants"
+	"github.com/guillermoballester/propagatorGo/internal/database"
+	"github.com/guillermoballester/propagatorGo/internal/repository"
+	scraper "github.com/guillermoballester/propagatorGo/internal/scrapper"
+	"github.com/guillermoballester/propagatorGo/internal/task"
+)
+
+var testStart = time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+
+// testWorker runs a function each time it is started
+type testWorker struct {
+	BaseWorker
+	run    func(ctx context.Context, w *testWorker) error
+	starts int32
+}
+
+func newTestWorker(id int, c clock.Clock, run func(ctx context.Context, w *testWorker) error) *testWorker {
+	return &testWorker{BaseWorker: NewBaseWorker(id, "test-"+strconv.Itoa(id), "test", c), run: run}
+}
+
+func (w *testWorker) Start(ctx context.Context) error {
+	w.SetActive(true)
+	atomic.AddInt32(&w.starts, 1)
+	return w.run(ctx, w)
+}
+
+func (w *testWorker) startCount() int {
+	return int(atomic.LoadInt32(&w.starts))
+}
+
+// untilStopped keeps a test worker running until it is stopped or its
+// context is cancelled
+func untilStopped(ctx context.Context, w *testWorker) error {
+	for w.IsActive() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+	return nil
+}
+
+// memQueue keeps queued messages in memory; Dequeue fails with err when set
+type memQueue struct {
+	mu     sync.Mutex
+	queues map[string][][]byte
+	err    error
+}
+
+func newMemQueue() *memQueue {
+	return &memQueue{queues: make(map[string][][]byte)}
+}
+
+func (q *memQueue) Enqueue(_ context.Context, queueName string, t interface{}) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queues[queueName] = append(q.queues[queueName], data)
+	return nil
+}
+
+func (q *memQueue) EnqueueIfEmpty(ctx context.Context, queueName string, tasks []interface{}) (bool, error) {
+	if length, _ := q.QueueLength(ctx, queueName); length > 0 {
+		return false, nil
+	}
+	for _, t := range tasks {
+		if err := q.Enqueue(ctx, queueName, t); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (q *memQueue) Dequeue(_ context.Context, queueName string, _ int) ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.err != nil {
+		return nil, q.err
+	}
+	if len(q.queues[queueName]) == 0 {
+		return nil, nil
+	}
+	data := q.queues[queueName][0]
+	q.queues[queueName] = q.queues[queueName][1:]
+	return data, nil
+}
+
+func (q *memQueue) QueueLength(_ context.Context, queueName string) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return int64(len(q.queues[queueName])), nil
+}
+
+func (q *memQueue) ClearQueue(_ context.Context, queueName string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.queues, queueName)
+	return nil
+}
+
+// peek decodes the first task waiting on a queue
+func (q *memQueue) peek(t *testing.T, taskType string) *task.Task {
+	t.Helper()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	messages := q.queues[task.QueueName(taskType)]
+	if len(messages) == 0 {
+		t.Fatalf("Expected a task on the %s queue", taskType)
+	}
+	var queued task.Task
+	if err := json.Unmarshal(messages[0], &queued); err != nil {
+		t.Fatalf("Expected a task, got %v", err)
+	}
+	return &queued
+}
+
+// waitFor waits for a condition reached by a worker goroutine
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// startWorker runs a worker in the background, returning a channel
+// delivering what Start returned
+func startWorker(w Worker) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Start(context.Background())
+	}()
+	return done
+}
+
+// assertRunning fails the test if the worker already returned
+func assertRunning(t *testing.T, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected the worker to be waiting, it returned %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestScraperWorkerIdlesWhileNoStockIsDue(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	cfg := &config.Config{}
+	wm := NewWorkManager([]config.Stock{{Symbol: "AAPL", Enabled: true}})
+
+	// The only stock is being scraped elsewhere
+	if wm.GetNextStock() == nil {
+		t.Fatalf("Expected AAPL to be handed out")
+	}
+
+	w := NewScraperWorker(NewBaseWorker(1, "scraper-1", "scraper", fake),
+		scraper.NewScraperService(cfg, nil, nil), task.NewService(cfg, newMemQueue()),
+		func(string) *WorkManager { return wm }, "yahoo", constants.ScrapeModeRoundRobin, constants.RunModeWindow)
+	done := startWorker(w)
+
+	fake.BlockUntil(1)
+	w.Stop()
+
+	// Nothing happens before the idle sleep is over
+	fake.Advance(5*time.Second - time.Nanosecond)
+	assertRunning(t, done)
+
+	fake.Advance(time.Nanosecond)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the worker to stop cleanly, got %v", err)
+	}
+	if stats := w.Stats.GetSnapshot(); stats.ItemsProcessed != 0 {
+		t.Errorf("Expected no items processed, got %d", stats.ItemsProcessed)
+	}
+}
+
+func TestConsumerWorkerBacksOffOnQueueErrors(t *testing.T) {
+	fake := clock.NewFake(testStart)
+	queue := newMemQueue()
+	queue.err = errors.New("connection refused")
+
+	w := NewConsumerWorker(NewBaseWorker(1, "consumer-1", "consumer", fake),
+		task.NewService(&config.Config{}, queue), nil, nil, 1, 0, constants.RunModeWindow)
+	done := startWorker(w)
+
+	// One failed dequeue per second
+	for i := int64(1); i <= 3; i++ {
+		fake.BlockUntil(1)
+		if errorCount := w.Stats.GetSnapshot().Errors; errorCount != i {
+			t.Fatalf("Expected %d errors, got %d", i, errorCount)
+		}
+		fake.Advance(time.Second)
+	}
+
+	fake.BlockUntil(1)
+	w.Stop()
+	fake.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the worker to stop cleanly, got %v", err)
+	}
+}
+
+// failingStore fails every bulk save and the single saves of some URLs
+type failingStore struct {
+	bad   map[string]bool
+	saved []string
+}
+
+func (s *failingStore) SaveArticle(_ context.Context, article database.Article) error {
+	if s.bad[article.URL] {
+		return errors.New("value too long for type character varying(255)")
+	}
+	s.saved = append(s.saved, article.URL)
+	return nil
+}
+
+func (s *failingStore) SaveArticles(context.Context, []database.Article) ([]repository.SaveResult, error) {
+	return nil, errors.New("value too long for type character varying(255)")
+}
+
+func TestConsumerWorkerSavesOneByOneWhenBatchFails(t *testing.T) {
+	store := &failingStore{bad: map[string]bool{"https://news.example/2": true}}
+	w := NewConsumerWorker(NewBaseWorker(1, "consumer-1", "consumer", clock.NewFake(testStart)),
+		nil, store, nil, 10, time.Second, constants.RunModeWindow)
+
+	batch := &articleBatch{}
+	for _, url := range []string{"https://news.example/1", "https://news.example/2", "https://news.example/3"} {
+		batch.articles = append(batch.articles, database.Article{URL: url, Symbol: "AAPL"})
+		batch.labels = append(batch.labels, ItemLabels{Symbol: "AAPL"})
+	}
+	w.flush(context.Background(), batch)
+
+	if len(store.saved) != 2 || store.saved[0] != "https://news.example/1" || store.saved[1] != "https://news.example/3" {
+		t.Errorf("Expected the valid articles to be saved one by one, got %v", store.saved)
+	}
+	if stats := w.Stats.GetSnapshot(); stats.ItemsSuccessful != 2 || stats.ItemsFailed != 1 {
+		t.Errorf("Expected 2 articles saved and 1 failed, got %d and %d", stats.ItemsSuccessful, stats.ItemsFailed)
+	}
+	if len(batch.articles) != 0 {
+		t.Errorf("Expected the batch to be emptied, got %d articles", len(batch.articles))
+	}
+}
+
+func TestAPIWorkerDelaysRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			rw.Header().Set("Retry-After", "4")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(testStart)
+	queue := newMemQueue()
+	taskSvc := task.NewService(&config.Config{}, queue)
+	call := task.APICall{URL: server.URL, MaxAttempts: 2, Handler: "log"}
+	if err := taskSvc.EnqueueTask(context.Background(), taskSvc.CreateAPICallTask(call)); err != nil {
+		t.Fatalf("Expected the call to be enqueued, got %v", err)
+	}
+
+	w := NewAPIWorker(NewBaseWorker(1, "api-1", "api", fake), taskSvc, NewAPIHandlers(), "", "", constants.RunModePass)
+	done := startWorker(w)
+
+	// The throttled call goes back on the queue, due once Retry-After elapsed
+	fake.BlockUntil(1)
+	retried := queue.peek(t, constants.TaskTypeAPICall)
+	if !retried.NotBefore().Equal(testStart.Add(4*time.Second)) || retried.Attempt() != 2 {
+		t.Errorf("Expected attempt 2 due at %v, got attempt %d due at %v",
+			testStart.Add(4*time.Second), retried.Attempt(), retried.NotBefore())
+	}
+	if stats := w.Stats.GetSnapshot(); stats.Retried != 1 || stats.Requeued != 0 || stats.ItemsFailed != 1 {
+		t.Errorf("Expected one failed attempt retried and no requeue, got %d retried, %d requeued and %d failed",
+			stats.Retried, stats.Requeued, stats.ItemsFailed)
+	}
+
+	// Put back while it is not due, checking again every second
+	for i := 0; i < 4; i++ {
+		if n := atomic.LoadInt32(&calls); n != 1 {
+			t.Fatalf("Expected the retry to wait, got %d calls after %ds", n, i)
+		}
+		fake.Advance(time.Second)
+		if i < 3 {
+			fake.BlockUntil(1)
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("Expected the worker to drain the queue, got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected 2 calls, got %d", n)
+	}
+	if stats := w.Stats.GetSnapshot(); stats.ItemsSuccessful != 1 || stats.Retried != 1 {
+		t.Errorf("Expected the retry to succeed, got %d successful and %d retried", stats.ItemsSuccessful, stats.Retried)
+	}
+}